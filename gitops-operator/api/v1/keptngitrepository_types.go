@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,25 +26,74 @@ const (
 	KeptnGitRepositoryPhaseSuccessful = "Successful"
 	// KeptnGitRepositoryPhaseFailed defines the value for a failed action
 	KeptnGitRepositoryPhaseFailed = "Failed"
+	// KeptnGitRepositoryPhasePlanned defines the value for a dry run which has been planned
+	KeptnGitRepositoryPhasePlanned = "Planned"
+)
+
+const (
+	// PlannedActionCreate defines the value for an object which would be created
+	PlannedActionCreate = "Create"
+	// PlannedActionUpdate defines the value for an object which would be updated
+	PlannedActionUpdate = "Update"
+	// PlannedActionDelete defines the value for an object which would be pruned
+	PlannedActionDelete = "Delete"
 )
 
 // KeptnGitRepositorySpec defines the desired state of KeptnGitRepository
 type KeptnGitRepositorySpec struct {
 	Repository string `json:"repository"`
-	Token      string `json:"password"`
-	Username   string `json:"username"`
+	Token      string `json:"password,omitempty"`
+	Username   string `json:"username,omitempty"`
 	Branch     string `json:"branch,omitempty"`
 	BaseDir    string `json:"baseDir,omitempty"`
+	// SecretRef references a Secret containing the git password, takes precedence over Token
+	SecretRef *keptnv1.SecretKeyReference `json:"secretRef,omitempty"`
+	// SSH configures SSH authentication, used instead of username/password if a private key is given
+	SSH *keptnv1.GitSSHCredentials `json:"ssh,omitempty"`
+	// Prune deletes objects created from this repository if they are removed from the repository
+	Prune bool `json:"prune,omitempty"`
+	// DryRun only plans the changes of the repository and writes them to the status, nothing is applied or pushed upstream
+	DryRun bool `json:"dryRun,omitempty"`
 }
 
 // KeptnGitRepositoryStatus defines the observed state of KeptnGitRepository
 type KeptnGitRepositoryStatus struct {
 	LastCommit string `json:"lastCommit,omitempty"`
 	Result     string `json:"result,omitempty"`
+	// Inventory contains the objects which have been applied from the last commit
+	Inventory []InventoryEntry `json:"inventory,omitempty"`
+	// PlannedCommit is the commit the plan has been computed for
+	PlannedCommit string `json:"plannedCommit,omitempty"`
+	// Plan contains the changes which would be applied if dryRun was disabled
+	Plan []PlannedChange `json:"plan,omitempty"`
+	// ObservedGeneration is the generation of the spec which has been reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the reconciliation (Ready, Reconciling, Stalled)
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// InventoryEntry references an object which has been applied from a KeptnGitRepository
+type InventoryEntry struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// PlannedChange describes a change which would be applied by a KeptnGitRepository
+type PlannedChange struct {
+	// Action is one of Create, Update or Delete
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KeptnGitRepository is the Schema for the keptngitrepositories API
 type KeptnGitRepository struct {
@@ -63,6 +113,26 @@ type KeptnGitRepositoryList struct {
 	Items           []KeptnGitRepository `json:"items"`
 }
 
+// GetConditions returns the conditions of the KeptnGitRepository
+func (in *KeptnGitRepository) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions of the KeptnGitRepository
+func (in *KeptnGitRepository) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// GetObservedGeneration returns the generation which has been reconciled
+func (in *KeptnGitRepository) GetObservedGeneration() int64 {
+	return in.Status.ObservedGeneration
+}
+
+// SetObservedGeneration sets the generation which has been reconciled
+func (in *KeptnGitRepository) SetObservedGeneration(generation int64) {
+	in.Status.ObservedGeneration = generation
+}
+
 func init() {
 	SchemeBuilder.Register(&KeptnGitRepository{}, &KeptnGitRepositoryList{})
 }
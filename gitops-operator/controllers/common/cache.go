@@ -0,0 +1,100 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"time"
+)
+
+//MinCleanupInterval is the shortest interval in which stale repositories are removed
+const MinCleanupInterval = time.Minute
+
+//RepositoryCache keeps a checkout of every repository on disk, so repositories only need to be fetched instead of cloned
+type RepositoryCache struct {
+	// BaseDir is the directory containing the cached repositories
+	BaseDir string
+	// MaxAge is the duration after which a repository which has not been used is removed
+	MaxAge time.Duration
+	// CleanupInterval is the interval in which stale repositories are removed
+	CleanupInterval time.Duration
+}
+
+//NewRepositoryCache creates a repository cache in the given directory, stale repositories are removed every maxAge / 2 but
+//not more often than MinCleanupInterval
+func NewRepositoryCache(baseDir string, maxAge time.Duration) *RepositoryCache {
+	cleanupInterval := maxAge / 2
+	if cleanupInterval < MinCleanupInterval {
+		cleanupInterval = MinCleanupInterval
+	}
+	return &RepositoryCache{
+		BaseDir:         baseDir,
+		MaxAge:          maxAge,
+		CleanupInterval: cleanupInterval,
+	}
+}
+
+//Dir returns the cache directory of a repository branch and marks it as used
+func (c *RepositoryCache) Dir(remoteURI string, branch string) (string, error) {
+	hash := sha256.Sum256([]byte(remoteURI + "#" + branch))
+	dir := filepath.Join(c.BaseDir, hex.EncodeToString(hash[:16]))
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("could not create cache directory: %w", err)
+	}
+
+	now := time.Now()
+	if err := os.Chtimes(dir, now, now); err != nil {
+		return "", fmt.Errorf("could not update cache directory: %w", err)
+	}
+	return dir, nil
+}
+
+//Cleanup removes all repositories which have not been used for longer than MaxAge
+func (c *RepositoryCache) Cleanup() error {
+	entries, err := ioutil.ReadDir(c.BaseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("could not read cache directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || time.Since(entry.ModTime()) < c.MaxAge {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(c.BaseDir, entry.Name())); err != nil {
+			return fmt.Errorf("could not remove stale repository %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+//Start removes stale repositories periodically until the context is done, this makes the cache a manager.Runnable
+func (c *RepositoryCache) Start(ctx context.Context) error {
+	log := ctrl.Log.WithName("repository-cache")
+	interval := c.CleanupInterval
+	if interval < MinCleanupInterval {
+		interval = MinCleanupInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Cleanup(); err != nil {
+			log.Error(err, "Could not remove stale repositories")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
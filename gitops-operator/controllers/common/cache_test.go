@@ -0,0 +1,81 @@
+package common
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRepositoryCacheDir(t *testing.T) {
+	cache := NewRepositoryCache(t.TempDir(), time.Hour)
+
+	dir, err := cache.Dir("https://github.com/keptn/podtato-head", "main")
+	require.NoError(t, err)
+	require.DirExists(t, dir)
+
+	sameDir, err := cache.Dir("https://github.com/keptn/podtato-head", "main")
+	require.NoError(t, err)
+	require.Equal(t, dir, sameDir)
+
+	otherBranchDir, err := cache.Dir("https://github.com/keptn/podtato-head", "develop")
+	require.NoError(t, err)
+	require.NotEqual(t, dir, otherBranchDir)
+
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(dir, old, old))
+	_, err = cache.Dir("https://github.com/keptn/podtato-head", "main")
+	require.NoError(t, err)
+
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), info.ModTime(), time.Minute)
+}
+
+func TestRepositoryCacheCleanup(t *testing.T) {
+	cache := NewRepositoryCache(t.TempDir(), time.Hour)
+
+	staleDir, err := cache.Dir("https://github.com/keptn/podtato-head", "main")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(staleDir, "README.md"), []byte("podtato-head"), 0600))
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(staleDir, old, old))
+
+	freshDir, err := cache.Dir("https://github.com/keptn/podtato-head", "develop")
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Cleanup())
+	require.NoDirExists(t, staleDir)
+	require.DirExists(t, freshDir)
+}
+
+func TestRepositoryCacheCleanupWithoutBaseDir(t *testing.T) {
+	cache := NewRepositoryCache(filepath.Join(t.TempDir(), "missing"), time.Hour)
+	require.NoError(t, cache.Cleanup())
+}
+
+func TestRepositoryCacheCleanupInterval(t *testing.T) {
+	tests := []struct {
+		name   string
+		maxAge time.Duration
+		want   time.Duration
+	}{
+		{name: "zero", maxAge: 0, want: MinCleanupInterval},
+		{name: "nanosecond", maxAge: time.Nanosecond, want: MinCleanupInterval},
+		{name: "default", maxAge: 24 * time.Hour, want: 12 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewRepositoryCache(t.TempDir(), tt.maxAge)
+			require.Equal(t, tt.want, cache.CleanupInterval)
+
+			// Start must not panic, even if the interval has been changed afterwards
+			cache.CleanupInterval = 0
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			require.NoError(t, cache.Start(ctx))
+		})
+	}
+}
@@ -6,15 +6,21 @@ import (
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
 	internaltypes "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/controllers/common/types"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/gitauth"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"time"
 )
 
 //GitClientFactory ...
 type GitClientFactory interface {
 	GetClient(repositoryConfig internaltypes.GitRepositoryConfig, dir string) (GitClient, error)
+	GetRemoteCommitHash(repositoryConfig internaltypes.GitRepositoryConfig) (string, error)
 }
 
 //GitClient ...
@@ -37,29 +43,67 @@ func (GoGitClientFactory) GetClient(repositoryConfig internaltypes.GitRepository
 	return client, nil
 }
 
+//GetRemoteCommitHash gets the hash of the last commit of the configured branch without cloning the repository (ls-remote)
+func (GoGitClientFactory) GetRemoteCommitHash(repositoryConfig internaltypes.GitRepositoryConfig) (string, error) {
+	authentication, err := gitauth.NewAuthMethod(repositoryConfig.RemoteURI, repositoryConfig.User, repositoryConfig.Token, repositoryConfig.SSH)
+	if err != nil {
+		return "", err
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{repositoryConfig.RemoteURI},
+	})
+
+	refs, err := remote.List(&git.ListOptions{Auth: authentication})
+	if err != nil {
+		return "", fmt.Errorf("could not list references of %s: %w", repositoryConfig.RemoteURI, err)
+	}
+
+	branch := plumbing.NewBranchReferenceName(repositoryConfig.Branch)
+	for _, ref := range refs {
+		if ref.Name() == branch {
+			return ref.Hash().String(), nil
+		}
+	}
+	return "", fmt.Errorf("could not find branch %s in %s", repositoryConfig.Branch, repositoryConfig.RemoteURI)
+}
+
 //GoGitClient speecifies the repository configuration
 type GoGitClient struct {
 	repoConfig internaltypes.GitRepositoryConfig
 	repo       *git.Repository
 }
 
-// Checkout checks out the given repository and returns the hash of the last commit
+// Checkout checks out the given repository, an existing checkout of the repository in dir is updated instead of cloned again
 func (gc *GoGitClient) Checkout(repositoryConfig internaltypes.GitRepositoryConfig, dir string) error {
-	authentication := &githttp.BasicAuth{
-		Username: repositoryConfig.User,
-		Password: repositoryConfig.Token,
+	authentication, err := gitauth.NewAuthMethod(repositoryConfig.RemoteURI, repositoryConfig.User, repositoryConfig.Token, repositoryConfig.SSH)
+	if err != nil {
+		return err
 	}
 
-	cloneOptions := git.CloneOptions{
-		URL:           repositoryConfig.RemoteURI,
-		Auth:          authentication,
-		SingleBranch:  true,
-		ReferenceName: plumbing.ReferenceName("refs/heads/" + repositoryConfig.Branch),
+	repo, err := git.PlainOpen(dir)
+	if err == nil {
+		err = updateCheckout(repo, repositoryConfig, authentication)
 	}
 
-	repo, err := git.PlainClone(dir, false, &cloneOptions)
 	if err != nil {
-		return fmt.Errorf("Could not checkout "+repositoryConfig.RemoteURI+"/"+repositoryConfig.Branch, err)
+		// the directory does not contain a usable checkout, start from scratch
+		if err := cleanDir(dir); err != nil {
+			return fmt.Errorf("could not clean %s: %w", dir, err)
+		}
+
+		cloneOptions := git.CloneOptions{
+			URL:           repositoryConfig.RemoteURI,
+			Auth:          authentication,
+			SingleBranch:  true,
+			ReferenceName: plumbing.ReferenceName("refs/heads/" + repositoryConfig.Branch),
+		}
+
+		repo, err = git.PlainClone(dir, false, &cloneOptions)
+		if err != nil {
+			return fmt.Errorf("Could not checkout "+repositoryConfig.RemoteURI+"/"+repositoryConfig.Branch, err)
+		}
 	}
 
 	gc.repo = repo
@@ -67,6 +111,70 @@ func (gc *GoGitClient) Checkout(repositoryConfig internaltypes.GitRepositoryConf
 	return nil
 }
 
+//updateCheckout fetches the configured branch and resets the worktree to it, local changes are discarded
+func updateCheckout(repo *git.Repository, repositoryConfig internaltypes.GitRepositoryConfig, authentication transport.AuthMethod) error {
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return err
+	}
+	if urls := remote.Config().URLs; len(urls) != 1 || urls[0] != repositoryConfig.RemoteURI {
+		return fmt.Errorf("checkout has a different remote")
+	}
+
+	branch := plumbing.NewBranchReferenceName(repositoryConfig.Branch)
+	remoteBranch := plumbing.NewRemoteReferenceName(git.DefaultRemoteName, repositoryConfig.Branch)
+	err = repo.Fetch(&git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		Auth:       authentication,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec("+" + branch.String() + ":" + remoteBranch.String()),
+			"+refs/tags/*:refs/tags/*",
+		},
+		Force: true,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("could not fetch: %w", err)
+	}
+
+	ref, err := repo.Reference(remoteBranch, true)
+	if err != nil {
+		return err
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	err = w.Checkout(&git.CheckoutOptions{Branch: branch, Force: true})
+	if err != nil {
+		return err
+	}
+
+	err = w.Reset(&git.ResetOptions{Commit: ref.Hash(), Mode: git.HardReset})
+	if err != nil {
+		return err
+	}
+	return w.Clean(&git.CleanOptions{Dir: true})
+}
+
+//cleanDir removes the content of a directory, but keeps the directory itself
+func cleanDir(dir string) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 //TagExists checks if a git tag exists
 func (gc *GoGitClient) TagExists(tag string) error {
 	tagFoundErr := "tag was found"
@@ -95,9 +203,9 @@ func (gc *GoGitClient) GetLastCommitHash() (string, error) {
 
 //CommitAndPushUpstream commits changes and pushes them to the keptn upstream
 func (gc *GoGitClient) CommitAndPushUpstream(tag string, tagExists bool) error {
-	authentication := &githttp.BasicAuth{
-		Username: gc.repoConfig.User,
-		Password: gc.repoConfig.Token,
+	authentication, err := gitauth.NewAuthMethod(gc.repoConfig.RemoteURI, gc.repoConfig.User, gc.repoConfig.Token, gc.repoConfig.SSH)
+	if err != nil {
+		return err
 	}
 
 	commitOptions := git.CommitOptions{
@@ -0,0 +1,97 @@
+package common
+
+import (
+	internaltypes "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/controllers/common/types"
+	"github.com/stretchr/testify/require"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(cmd.Env, "GIT_AUTHOR_NAME=keptn", "GIT_AUTHOR_EMAIL=keptn@keptn.sh", "GIT_COMMITTER_NAME=keptn", "GIT_COMMITTER_EMAIL=keptn@keptn.sh", "HOME="+dir)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func newRemoteRepository(t *testing.T) string {
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-b", "main")
+	commitFile(t, dir, "README.md", "podtato-head")
+	return dir
+}
+
+func commitFile(t *testing.T, dir string, name string, content string) string {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	runGit(t, dir, "add", name)
+	runGit(t, dir, "commit", "-m", "update "+name)
+	return runGit(t, dir, "rev-parse", "HEAD")
+}
+
+func TestGetRemoteCommitHash(t *testing.T) {
+	remoteDir := newRemoteRepository(t)
+	hash := runGit(t, remoteDir, "rev-parse", "HEAD")
+
+	got, err := GoGitClientFactory{}.GetRemoteCommitHash(internaltypes.GitRepositoryConfig{RemoteURI: remoteDir, Branch: "main"})
+	require.NoError(t, err)
+	require.Equal(t, hash, got)
+
+	_, err = GoGitClientFactory{}.GetRemoteCommitHash(internaltypes.GitRepositoryConfig{RemoteURI: remoteDir, Branch: "missing"})
+	require.Error(t, err)
+}
+
+func TestCheckoutUpdatesExistingCheckout(t *testing.T) {
+	remoteDir := newRemoteRepository(t)
+	checkoutDir := t.TempDir()
+	repoConfig := internaltypes.GitRepositoryConfig{RemoteURI: remoteDir, Branch: "main"}
+
+	client, err := GoGitClientFactory{}.GetClient(repoConfig, checkoutDir)
+	require.NoError(t, err)
+	hash, err := client.GetLastCommitHash()
+	require.NoError(t, err)
+	require.Equal(t, runGit(t, remoteDir, "rev-parse", "HEAD"), hash)
+
+	// the marker is only kept if the existing checkout is updated instead of cloned again
+	require.NoError(t, os.WriteFile(filepath.Join(checkoutDir, ".git", "marker"), []byte("marker"), 0600))
+
+	// local changes are discarded when the checkout is updated
+	require.NoError(t, os.WriteFile(filepath.Join(checkoutDir, "README.md"), []byte("changed"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(checkoutDir, "untracked.txt"), []byte("untracked"), 0600))
+
+	newHash := commitFile(t, remoteDir, "shipyard.yaml", "stages: []")
+
+	client, err = GoGitClientFactory{}.GetClient(repoConfig, checkoutDir)
+	require.NoError(t, err)
+	hash, err = client.GetLastCommitHash()
+	require.NoError(t, err)
+	require.Equal(t, newHash, hash)
+	require.FileExists(t, filepath.Join(checkoutDir, "shipyard.yaml"))
+	require.NoFileExists(t, filepath.Join(checkoutDir, "untracked.txt"))
+	require.FileExists(t, filepath.Join(checkoutDir, ".git", "marker"))
+
+	readme, err := os.ReadFile(filepath.Join(checkoutDir, "README.md"))
+	require.NoError(t, err)
+	require.Equal(t, "podtato-head", string(readme))
+}
+
+func TestCheckoutReplacesCheckoutOfOtherRemote(t *testing.T) {
+	remoteDir := newRemoteRepository(t)
+	otherRemoteDir := newRemoteRepository(t)
+	otherHash := commitFile(t, otherRemoteDir, "other.txt", "other")
+	checkoutDir := t.TempDir()
+
+	_, err := GoGitClientFactory{}.GetClient(internaltypes.GitRepositoryConfig{RemoteURI: remoteDir, Branch: "main"}, checkoutDir)
+	require.NoError(t, err)
+
+	client, err := GoGitClientFactory{}.GetClient(internaltypes.GitRepositoryConfig{RemoteURI: otherRemoteDir, Branch: "main"}, checkoutDir)
+	require.NoError(t, err)
+	hash, err := client.GetLastCommitHash()
+	require.NoError(t, err)
+	require.Equal(t, otherHash, hash)
+	require.FileExists(t, filepath.Join(checkoutDir, "other.txt"))
+}
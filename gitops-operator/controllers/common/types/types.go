@@ -2,6 +2,7 @@ package types
 
 import (
 	"github.com/go-git/go-git/v5"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/gitauth"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -51,4 +52,5 @@ type GitRepositoryConfig struct {
 	User      string
 	Token     string
 	Branch    string
+	SSH       gitauth.SSHCredentials
 }
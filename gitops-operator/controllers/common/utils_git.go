@@ -6,6 +6,8 @@ import (
 	"github.com/go-git/go-git/v5"
 	commontypes "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/controllers/common/types"
 	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/gitauth"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/secrets"
 	"k8s.io/apimachinery/pkg/types"
 	"os/exec"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,12 +32,23 @@ func GetUpstreamCredentials(ctx context.Context, client client.Client, project s
 		return &commontypes.GitRepositoryConfig{}, err
 	}
 
-	return GetGitCredentials(obj.Spec.Repository, obj.Spec.Username, obj.Spec.Password, obj.Spec.DefaultBranch)
+	token := secrets.Reference(obj.Spec.SecretRef, obj.Spec.Password, namespace)
+	repositoryConfig, err := GetGitCredentials(ctx, obj.Spec.Repository, obj.Spec.Username, token, obj.Spec.DefaultBranch, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	repositoryConfig.SSH, err = gitauth.ResolveSSHCredentials(ctx, obj.Spec.SSH, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return repositoryConfig, nil
 }
 
-//GetGitCredentials creates git credentials struct from information
-func GetGitCredentials(remoteURI, user, token string, branch string) (*commontypes.GitRepositoryConfig, error) {
-	secret, err := decryptSecret(token)
+//GetGitCredentials creates git credentials struct from information, the token may be any secret understood by the secrets package
+//which may be used by resources in the given namespace
+func GetGitCredentials(ctx context.Context, remoteURI, user, token string, branch string, namespace string) (*commontypes.GitRepositoryConfig, error) {
+	secret, err := secrets.ResolveInNamespace(ctx, token, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,95 @@
+package common
+
+import (
+	"context"
+	commontypes "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/controllers/common/types"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/secrets"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestGetGitCredentials(t *testing.T) {
+	os.Setenv("GIT_TEST_TOKEN", "env-token")
+	defer os.Unsetenv("GIT_TEST_TOKEN")
+
+	type args struct {
+		remoteURI string
+		user      string
+		token     string
+		branch    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *commontypes.GitRepositoryConfig
+		wantErr bool
+	}{
+		{
+			name: "plaintext token",
+			args: args{remoteURI: "https://github.com/keptn/podtato-head", user: "keptn", token: "my-token", branch: "develop"},
+			want: &commontypes.GitRepositoryConfig{RemoteURI: "https://github.com/keptn/podtato-head", User: "keptn", Token: "my-token", Branch: "develop"},
+		},
+		{
+			name: "default branch",
+			args: args{remoteURI: "https://github.com/keptn/podtato-head", user: "keptn", token: "my-token"},
+			want: &commontypes.GitRepositoryConfig{RemoteURI: "https://github.com/keptn/podtato-head", User: "keptn", Token: "my-token", Branch: "main"},
+		},
+		{
+			name:    "env token is not allowed",
+			args:    args{remoteURI: "https://github.com/keptn/podtato-head", user: "keptn", token: "env:GIT_TEST_TOKEN"},
+			wantErr: true,
+		},
+		{
+			name:    "secret in other namespace",
+			args:    args{remoteURI: "https://github.com/keptn/podtato-head", user: "keptn", token: "k8s:other/git-credentials/token"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetGitCredentials(context.TODO(), tt.args.remoteURI, tt.args.user, tt.args.token, tt.args.branch, "keptn")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetUpstreamCredentialsWithSecretRef(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, keptnv1.AddToScheme(scheme))
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&keptnv1.KeptnProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "podtato-head", Namespace: "keptn"},
+			Spec: keptnv1.KeptnProjectSpec{
+				Repository: "https://github.com/keptn/podtato-head-upstream",
+				Username:   "keptn",
+				Password:   "ignored",
+				SecretRef:  &keptnv1.SecretKeyReference{Name: "upstream", Key: "token"},
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "upstream", Namespace: "keptn"},
+			Data:       map[string][]byte{"token": []byte("secret-token")},
+		},
+	).Build()
+	secrets.Register(secrets.KubernetesPrefix, secrets.NewKubernetesResolver(fakeClient))
+
+	got, err := GetUpstreamCredentials(context.TODO(), fakeClient, "podtato-head", "keptn")
+
+	require.NoError(t, err)
+	require.Equal(t, "secret-token", got.Token)
+	require.Equal(t, "main", got.Branch)
+}
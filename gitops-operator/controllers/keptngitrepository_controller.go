@@ -22,8 +22,10 @@ import (
 	gitopsv1 "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/api/v1"
 	"github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/controllers/common"
 	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/gitauth"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/secrets"
 	"github.com/spf13/afero"
-	"io/ioutil"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
@@ -40,6 +42,10 @@ type KeptnGitRepositoryReconciler struct {
 	// Recorder contains the Recorder of this controller
 	Recorder         record.EventRecorder
 	GitClientFactory common.GitClientFactory
+	// RepositoryCache contains the checkouts of the code and upstream repositories
+	RepositoryCache *common.RepositoryCache
+	// PollingInterval is the interval in which repositories are checked for changes
+	PollingInterval time.Duration
 }
 
 type KeptnManifests struct {
@@ -62,6 +68,7 @@ const reconcileImmediateInterval = 1 * time.Second
 //+kubebuilder:rbac:groups=keptn.sh,resources=keptnsequences,verbs=get;list
 //+kubebuilder:rbac:groups=keptn.sh,resources=keptnprojects,verbs=get;list
 //+kubebuilder:rbac:groups=keptn.sh,resources=keptninstances,verbs=get;list
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -94,41 +101,100 @@ func (r *KeptnGitRepositoryReconciler) Reconcile(ctx context.Context, req ctrl.R
 	}
 
 	fs := afero.NewOsFs()
-	codeRepoDir, _ := ioutil.TempDir("", "code_tmp_dir")
 
-	codeRepoConfig, err := common.GetGitCredentials(keptnGitRepository.Spec.Repository, keptnGitRepository.Spec.Username, keptnGitRepository.Spec.Token, keptnGitRepository.Spec.Branch)
+	codeRepoToken := secrets.Reference(keptnGitRepository.Spec.SecretRef, keptnGitRepository.Spec.Token, keptnGitRepository.Namespace)
+	codeRepoConfig, err := common.GetGitCredentials(ctx, keptnGitRepository.Spec.Repository, keptnGitRepository.Spec.Username, codeRepoToken, keptnGitRepository.Spec.Branch, keptnGitRepository.Namespace)
 	if err != nil {
 		r.Log.Error(err, "Could not decode code repo credentials", "URI", keptnGitRepository.Spec.Repository)
+		r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Could not decode code repo credentials", err)
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
 	}
 
+	codeRepoConfig.SSH, err = gitauth.ResolveSSHCredentials(ctx, keptnGitRepository.Spec.SSH, keptnGitRepository.Namespace)
+	if err != nil {
+		r.Log.Error(err, "Could not decode code repo ssh credentials", "URI", keptnGitRepository.Spec.Repository)
+		r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Could not decode code repo ssh credentials", err)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+
+	codeRepoDir, err := r.RepositoryCache.Dir(codeRepoConfig.RemoteURI, codeRepoConfig.Branch)
+	if err != nil {
+		r.Log.Error(err, "Could not get cache directory", "URI", keptnGitRepository.Spec.Repository)
+		r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Could not get cache directory", err)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+
+	// only fetch the repository if the branch has been changed
+	remoteHash, err := r.GitClientFactory.GetRemoteCommitHash(*codeRepoConfig)
+	if err != nil {
+		r.Log.Error(err, "Could not determine latest remote commit hash", "URI", keptnGitRepository.Spec.Repository)
+		r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Could not determine latest remote commit hash", err)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+
+	// a dry run has to be planned even if the commit has already been applied
+	lastCommit := keptnGitRepository.Status.LastCommit
+	if keptnGitRepository.Spec.DryRun {
+		lastCommit = keptnGitRepository.Status.PlannedCommit
+	}
+
+	// a changed spec has to be applied even if the commit has already been applied, as a failed attempt also observes
+	// the generation, the repository is only skipped if the last attempt has been successful
+	if remoteHash == lastCommit && keptnGitRepository.Status.ObservedGeneration == keptnGitRepository.Generation && conditions.IsReady(keptnGitRepository) {
+		r.Log.Info("Repository has not changed", "Repository", codeRepoConfig.RemoteURI, "Hash", remoteHash)
+		return ctrl.Result{RequeueAfter: r.PollingInterval}, nil
+	}
+
 	sourceGitClient, err := r.GitClientFactory.GetClient(*codeRepoConfig, codeRepoDir)
 	if err != nil {
 		r.Log.Error(err, "Could not initialize source git client", "URI", keptnGitRepository.Spec.Repository)
+		r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Could not initialize source git client", err)
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
 	}
 	codeRepoHash, err := sourceGitClient.GetLastCommitHash()
 	if err != nil {
 		r.Log.Error(err, "Could not determine latest commit hash", "URI", keptnGitRepository.Spec.Repository)
+		r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Could not determine latest commit hash", err)
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
 	}
 
-	if codeRepoHash == keptnGitRepository.Status.LastCommit {
-		r.Log.Info("Repository has not changed", "Repository", codeRepoConfig.RemoteURI, "Hash", codeRepoHash)
+	// TODO abstract file system read operations with an interface
+	// nothing is applied or pruned if any manifest can't be parsed, objects of a broken manifest would be pruned otherwise
+	manifests, err := r.parseKeptnManifests(codeRepoDir, keptnGitRepository.Spec.BaseDir)
+	if err != nil {
+		r.Log.Error(err, "Could not parse manifests", "Repository", codeRepoConfig.RemoteURI, "Hash", codeRepoHash)
+		r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonInvalidSpec, "Could not parse manifests", err)
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
 	}
 
-	// TODO abstract file system read operations with an interface
-	manifests, err := parseKeptnManifests(codeRepoDir, keptnGitRepository.Spec.BaseDir)
+	err = conditions.Reconciling(ctx, r.Client, keptnGitRepository, keptnv1.ReasonProgressing, "Applying commit "+codeRepoHash)
 	if err != nil {
-		r.Log.Info("Could not parse manifests", "Repository", codeRepoConfig.RemoteURI, "Hash", codeRepoHash)
+		r.Log.Error(err, "Could not update status", "keptnGitRepository", keptnGitRepository.Name)
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
 	}
 
+	if keptnGitRepository.Spec.DryRun {
+		plan, err := r.planChanges(ctx, keptnGitRepository, manifests)
+		if err != nil {
+			r.Log.Error(err, "Failed to plan changes")
+			r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Failed to plan changes", err)
+			return ctrl.Result{}, err
+		}
+
+		err = r.reportPlan(ctx, keptnGitRepository, plan, codeRepoHash)
+		if err != nil {
+			r.Log.Error(err, "Could not update status", "keptnGitRepository", keptnGitRepository.Name)
+			return ctrl.Result{}, err
+		}
+		r.Log.Info("Finished planning", "Changes", len(plan))
+		return ctrl.Result{RequeueAfter: r.PollingInterval}, nil
+	}
+
 	for _, instance := range manifests.instances {
 		created, err := r.checkCreateInstance(ctx, *keptnGitRepository, instance)
 		if err != nil {
 			r.Log.Error(err, "Failed to check or create instance")
+			r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Failed to check or create instance", err)
 			return ctrl.Result{}, err
 		} else if created {
 			return ctrl.Result{Requeue: true, RequeueAfter: reconcileImmediateInterval}, nil
@@ -139,6 +205,7 @@ func (r *KeptnGitRepositoryReconciler) Reconcile(ctx context.Context, req ctrl.R
 		err, created := r.checkCreateSequence(ctx, *keptnGitRepository, sequence)
 		if err != nil {
 			r.Log.Error(err, "Failed to check or create sequence")
+			r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Failed to check or create sequence", err)
 			return ctrl.Result{}, err
 		} else if created {
 			return ctrl.Result{Requeue: true, RequeueAfter: reconcileImmediateInterval}, nil
@@ -149,6 +216,7 @@ func (r *KeptnGitRepositoryReconciler) Reconcile(ctx context.Context, req ctrl.R
 		err, created := r.checkCreateStage(ctx, *keptnGitRepository, stage)
 		if err != nil {
 			r.Log.Error(err, "Failed to check or create stage")
+			r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Failed to check or create stage", err)
 			return ctrl.Result{}, err
 		} else if created {
 			return ctrl.Result{Requeue: true, RequeueAfter: reconcileImmediateInterval}, nil
@@ -159,6 +227,7 @@ func (r *KeptnGitRepositoryReconciler) Reconcile(ctx context.Context, req ctrl.R
 		err, created := r.checkCreateProject(ctx, *keptnGitRepository, project)
 		if err != nil {
 			r.Log.Error(err, "Failed to check or create project")
+			r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Failed to check or create project", err)
 			return ctrl.Result{}, err
 		} else if created {
 			return ctrl.Result{Requeue: true, RequeueAfter: reconcileImmediateInterval}, nil
@@ -174,6 +243,7 @@ func (r *KeptnGitRepositoryReconciler) Reconcile(ctx context.Context, req ctrl.R
 		err, created := r.checkCreateService(ctx, *keptnGitRepository, service)
 		if err != nil {
 			r.Log.Error(err, "Failed to check or create service")
+			r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Failed to check or create service", err)
 			return ctrl.Result{}, err
 		} else if created {
 			return ctrl.Result{Requeue: true, RequeueAfter: reconcileImmediateInterval}, nil
@@ -184,6 +254,18 @@ func (r *KeptnGitRepositoryReconciler) Reconcile(ctx context.Context, req ctrl.R
 		err, created := r.checkCreateSequenceExecution(ctx, *keptnGitRepository, sequenceexec)
 		if err != nil {
 			r.Log.Error(err, "Failed to check or create sequence execution")
+			r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Failed to check or create sequence execution", err)
+			return ctrl.Result{}, err
+		} else if created {
+			return ctrl.Result{Requeue: true, RequeueAfter: reconcileImmediateInterval}, nil
+		}
+	}
+
+	for _, scheduledexec := range manifests.scheduledexec {
+		err, created := r.checkCreateScheduledExecution(ctx, *keptnGitRepository, scheduledexec)
+		if err != nil {
+			r.Log.Error(err, "Failed to check or create scheduled execution")
+			r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Failed to check or create scheduled execution", err)
 			return ctrl.Result{}, err
 		} else if created {
 			return ctrl.Result{Requeue: true, RequeueAfter: reconcileImmediateInterval}, nil
@@ -194,20 +276,43 @@ func (r *KeptnGitRepositoryReconciler) Reconcile(ctx context.Context, req ctrl.R
 		err, created := r.checkCreateServiceDeployment(ctx, *keptnGitRepository, servicedeployment)
 		if err != nil {
 			r.Log.Error(err, "Failed to check or create service deployment")
+			r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Failed to check or create service deployment", err)
 			return ctrl.Result{}, err
 		} else if created {
 			return ctrl.Result{Requeue: true, RequeueAfter: reconcileImmediateInterval}, nil
 		}
 	}
 
+	inventory, err := r.getInventory(manifests)
+	if err != nil {
+		r.Log.Error(err, "Could not determine inventory")
+		r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Could not determine inventory", err)
+		return ctrl.Result{}, err
+	}
+
+	if keptnGitRepository.Spec.Prune {
+		err = r.pruneObjects(ctx, keptnGitRepository, inventory)
+		if err != nil {
+			r.Log.Error(err, "Failed to prune objects")
+			r.setStalled(ctx, keptnGitRepository, keptnv1.ReasonFailed, "Failed to prune objects", err)
+			return ctrl.Result{}, err
+		}
+	}
+
 	r.Log.Info("Finished Reconciling")
+	keptnGitRepository.Status.Inventory = inventory
+	keptnGitRepository.Status.PlannedCommit = ""
+	keptnGitRepository.Status.Plan = nil
 	r.updateStatusResult(ctx, keptnGitRepository, gitopsv1.KeptnGitRepositoryPhaseSuccessful, codeRepoHash)
-	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	return ctrl.Result{RequeueAfter: r.PollingInterval}, nil
 }
 
 func (r *KeptnGitRepositoryReconciler) updateStatusResult(ctx context.Context, keptnGitRepository *gitopsv1.KeptnGitRepository, result string, hash string) {
 	keptnGitRepository.Status.Result = result
 	keptnGitRepository.Status.LastCommit = hash
+	if result == gitopsv1.KeptnGitRepositoryPhaseSuccessful {
+		conditions.MarkReady(keptnGitRepository, keptnv1.ReasonReconciled, "Applied commit "+hash)
+	}
 	err := r.Client.Status().Update(ctx, keptnGitRepository)
 	if err != nil {
 		r.Log.Error(err, "Could not update status", "keptnGitRepository", keptnGitRepository.Name)
@@ -216,8 +321,16 @@ func (r *KeptnGitRepositoryReconciler) updateStatusResult(ctx context.Context, k
 	}
 }
 
+func (r *KeptnGitRepositoryReconciler) setStalled(ctx context.Context, keptnGitRepository *gitopsv1.KeptnGitRepository, reason string, message string, err error) {
+	err = conditions.Stalled(ctx, r.Client, keptnGitRepository, reason, message+": "+err.Error())
+	if err != nil {
+		r.Log.Error(err, "Could not update status", "keptnGitRepository", keptnGitRepository.Name)
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *KeptnGitRepositoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the webhook receiver triggers reconciliations by annotating repositories, so every update has to be reconciled
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&gitopsv1.KeptnGitRepository{}).
 		Complete(r)
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	gitopsv1 "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/controllers/common"
+	internaltypes "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/controllers/common/types"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"os"
+	"path/filepath"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"testing"
+	"time"
+)
+
+const testCommitHash = "0123456789abcdef"
+
+// fakeGitClientFactory returns clients for checkouts which have already been written to the repository cache
+type fakeGitClientFactory struct{}
+
+func (fakeGitClientFactory) GetClient(_ internaltypes.GitRepositoryConfig, _ string) (common.GitClient, error) {
+	return fakeGitClient{}, nil
+}
+
+func (fakeGitClientFactory) GetRemoteCommitHash(_ internaltypes.GitRepositoryConfig) (string, error) {
+	return testCommitHash, nil
+}
+
+type fakeGitClient struct{}
+
+func (fakeGitClient) Checkout(_ internaltypes.GitRepositoryConfig, _ string) error { return nil }
+func (fakeGitClient) GetLastCommitHash() (string, error)                           { return testCommitHash, nil }
+func (fakeGitClient) TagExists(_ string) error                                     { return nil }
+func (fakeGitClient) CommitAndPushUpstream(_ string, _ bool) error                 { return nil }
+
+//writeManifests writes the given files into the base directory of the cached checkout of the repository
+func writeManifests(t *testing.T, r *KeptnGitRepositoryReconciler, repo *gitopsv1.KeptnGitRepository, files map[string]string) {
+	dir, err := r.RepositoryCache.Dir(repo.Spec.Repository, repo.Spec.Branch)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, repo.Spec.BaseDir), 0700))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, repo.Spec.BaseDir, name), []byte(content), 0600))
+	}
+}
+
+func TestReconcileDoesNotPruneOnBrokenManifest(t *testing.T) {
+	repo := &gitopsv1.KeptnGitRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "podtato-head", Namespace: "keptn", UID: "repo-uid", Generation: 1},
+		Spec:       gitopsv1.KeptnGitRepositorySpec{Repository: "https://github.com/keptn/podtato-head", Branch: "main", BaseDir: ".keptn", Prune: true},
+	}
+
+	r := newTestReconciler(t,
+		repo,
+		controlledBy(t, repo, &keptnv1.KeptnService{ObjectMeta: metav1.ObjectMeta{Name: "helloservice", Namespace: "keptn"}}),
+	)
+	r.GitClientFactory = fakeGitClientFactory{}
+	r.RepositoryCache = common.NewRepositoryCache(t.TempDir(), time.Hour)
+
+	writeManifests(t, r, repo, map[string]string{
+		"service.yaml": `apiVersion: keptn.sh/v1
+kind: KeptnService
+metadata:
+  name: helloservice
+spec:
+  project: podtato-head
+  service: [helloservice
+`,
+	})
+
+	_, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "podtato-head", Namespace: "keptn"}})
+	require.Error(t, err)
+
+	require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "helloservice", Namespace: "keptn"}, &keptnv1.KeptnService{}))
+
+	updated := &gitopsv1.KeptnGitRepository{}
+	require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "podtato-head", Namespace: "keptn"}, updated))
+	stalled := meta.FindStatusCondition(updated.Status.Conditions, keptnv1.ConditionStalled)
+	require.NotNil(t, stalled)
+	require.Equal(t, metav1.ConditionTrue, stalled.Status)
+	require.Equal(t, keptnv1.ReasonInvalidSpec, stalled.Reason)
+}
+
+func TestReconcileRetriesFailedSpecChange(t *testing.T) {
+	repo := &gitopsv1.KeptnGitRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "podtato-head", Namespace: "keptn", UID: "repo-uid", Generation: 2},
+		Spec:       gitopsv1.KeptnGitRepositorySpec{Repository: "https://github.com/keptn/podtato-head", Branch: "main", BaseDir: ".keptn"},
+		Status:     gitopsv1.KeptnGitRepositoryStatus{LastCommit: testCommitHash, ObservedGeneration: 1},
+	}
+
+	r := newTestReconciler(t, repo)
+	r.GitClientFactory = fakeGitClientFactory{}
+	r.RepositoryCache = common.NewRepositoryCache(t.TempDir(), time.Hour)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "podtato-head", Namespace: "keptn"}}
+
+	// the changed spec points to a base directory which does not exist
+	_, err := r.Reconcile(context.TODO(), req)
+	require.Error(t, err)
+
+	updated := &gitopsv1.KeptnGitRepository{}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, updated))
+	require.Equal(t, int64(2), updated.Status.ObservedGeneration)
+	require.False(t, conditions.IsReady(updated))
+
+	// the same commit and generation are applied again, as the last attempt failed
+	writeManifests(t, r, repo, map[string]string{})
+	_, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, updated))
+	require.True(t, conditions.IsReady(updated))
+}
@@ -88,7 +88,6 @@ func (r *KeptnGitRepositoryReconciler) composeData(gitClient common.GitClient, t
 }
 
 func (r *KeptnGitRepositoryReconciler) deliverArtifacts(ctx context.Context, req ctrl.Request, fs afero.Fs, keptnGitRepository *gitopsv1.KeptnGitRepository, codeRepoDir string) error {
-	upstreamDir, _ := ioutil.TempDir("", "upstream_tmp_dir")
 	artifactBaseRoot := filepath.Join(codeRepoDir, keptnGitRepository.Spec.BaseDir, "base")
 	artifactStageRoot := filepath.Join(codeRepoDir, keptnGitRepository.Spec.BaseDir, "stages")
 	artifactProject, err := getArtifactProject(artifactBaseRoot)
@@ -111,6 +110,11 @@ func (r *KeptnGitRepositoryReconciler) deliverArtifacts(ctx context.Context, req
 			return err
 		}
 
+		upstreamDir, err := r.RepositoryCache.Dir(upstreamRepo.RemoteURI, upstreamRepo.Branch)
+		if err != nil {
+			return err
+		}
+
 		upstreamGitClient, err := r.GitClientFactory.GetClient(*upstreamRepo, upstreamDir)
 		if err != nil {
 			return err
@@ -10,7 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 )
 
-//+kubebuilder:rbac:groups=keptn.sh,resources=keptninstances,verbs=get;list;create;update;watch
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptninstances,verbs=get;list;create;update;watch;delete
 
 func (r *KeptnGitRepositoryReconciler) checkCreateInstance(ctx context.Context, repo gitopsv1.KeptnGitRepository, instance keptnv1.KeptnInstance) (bool, error) {
 	found := &keptnv1.KeptnInstance{}
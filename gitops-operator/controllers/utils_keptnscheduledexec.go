@@ -11,7 +11,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-//+kubebuilder:rbac:groups=keptn.sh,resources=keptnscheduledexecutions,verbs=get;list;create;update;watch
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptnscheduledexecs,verbs=get;list;create;update;watch;delete
 
 func (r *KeptnGitRepositoryReconciler) checkCreateScheduledExecution(ctx context.Context, repo gitopsv1.KeptnGitRepository, scheduledExecution keptnv1.KeptnScheduledExec) (error, bool) {
 	found := &keptnv1.KeptnScheduledExec{}
@@ -37,7 +37,7 @@ func (r *KeptnGitRepositoryReconciler) checkCreateScheduledExecution(ctx context
 		}
 		return nil, true
 	} else if err != nil {
-		r.Log.Error(err, "Failed to get Project")
+		r.Log.Error(err, "Failed to get ScheduledExecution")
 		return err, false
 	}
 
@@ -63,7 +63,7 @@ func (r *KeptnGitRepositoryReconciler) reconcileScheduledExecution(ctx context.C
 
 		err := r.Client.Update(ctx, obj)
 		if err != nil {
-			r.Log.Error(err, "Failed to update ScheduledExecution", "Sequence.Namespace", obj.Namespace, "Sequence.Name", obj.Name)
+			r.Log.Error(err, "Failed to update ScheduledExecution", "ScheduledExec.Namespace", obj.Namespace, "ScheduledExec.Name", obj.Name)
 			return err
 		} else {
 			r.Recorder.Event(&repo, "Normal", "Updated", fmt.Sprintf("Updated scheduledExecution %s/%s (Reason: scheduledExecution changed)", scheduledExecution.Namespace, scheduledExecution.Name))
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	gitopsv1 "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/api/v1"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckCreateScheduledExecution(t *testing.T) {
+	repo := &gitopsv1.KeptnGitRepository{ObjectMeta: metav1.ObjectMeta{Name: "podtato-head", Namespace: "keptn", UID: "repo-uid"}}
+	r := newTestReconciler(t, repo)
+	recorder := r.Recorder.(*record.FakeRecorder)
+
+	// the scheduled execution is read from a manifest, like it is done when a commit is applied
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".keptn"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".keptn", "schedule.yaml"), []byte(`apiVersion: keptn.sh/v1
+kind: KeptnScheduledExec
+metadata:
+  name: nightly
+spec:
+  startTime: "2021-11-20T02:00:00Z"
+  sequenceExecutionTemplate:
+    project: podtato-head
+    service: helloservice
+    stage: dev
+    event: sh.keptn.event.dev.delivery.triggered
+`), 0600))
+	manifests, err := r.parseKeptnManifests(dir, ".keptn")
+	require.NoError(t, err)
+	require.Len(t, manifests.scheduledexec, 1)
+	scheduledExecution := manifests.scheduledexec[0]
+
+	getScheduledExecution := func() *keptnv1.KeptnScheduledExec {
+		found := &keptnv1.KeptnScheduledExec{}
+		require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "nightly", Namespace: "keptn"}, found))
+		return found
+	}
+
+	// create
+	err, created := r.checkCreateScheduledExecution(context.TODO(), *repo, *scheduledExecution.DeepCopy())
+	require.NoError(t, err)
+	require.True(t, created)
+
+	found := getScheduledExecution()
+	require.Equal(t, scheduledExecution.Spec, found.Spec)
+	require.Equal(t, utils.GetHashStructure(scheduledExecution.Spec), found.Annotations["keptn.sh/last-applied-hash"])
+	require.True(t, metav1.IsControlledBy(found, repo))
+
+	// unchanged
+	err, created = r.checkCreateScheduledExecution(context.TODO(), *repo, *scheduledExecution.DeepCopy())
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Empty(t, recorder.Events)
+
+	// update
+	scheduledExecution.Spec.StartTime = "2021-11-21T02:00:00Z"
+	scheduledExecution.Spec.SequenceExecutionTemplate.Stage = "hardening"
+	err, created = r.checkCreateScheduledExecution(context.TODO(), *repo, *scheduledExecution.DeepCopy())
+	require.NoError(t, err)
+	require.False(t, created)
+
+	found = getScheduledExecution()
+	require.Equal(t, scheduledExecution.Spec, found.Spec)
+	require.Equal(t, utils.GetHashStructure(scheduledExecution.Spec), found.Annotations["keptn.sh/last-applied-hash"])
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events, "Updated")
+}
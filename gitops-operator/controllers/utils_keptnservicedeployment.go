@@ -11,7 +11,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-//+kubebuilder:rbac:groups=keptn.sh,resources=keptnservicedeployments,verbs=get;list;create;update;watch
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptnservicedeployments,verbs=get;list;create;update;watch;delete
 
 func (r *KeptnGitRepositoryReconciler) checkCreateServiceDeployment(ctx context.Context, repo gitopsv1.KeptnGitRepository, serviceDeployment keptnv1.KeptnServiceDeployment) (error, bool) {
 	found := &keptnv1.KeptnServiceDeployment{}
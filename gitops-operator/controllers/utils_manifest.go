@@ -14,7 +14,9 @@ import (
 	"path/filepath"
 )
 
-func parseKeptnManifests(dir string, basedir string) (KeptnManifests, error) {
+//parseKeptnManifests reads all Keptn resources from the yaml files in the base directory of a repository, an error is
+//returned if any file can't be read or parsed, as the manifests would be incomplete otherwise
+func (r *KeptnGitRepositoryReconciler) parseKeptnManifests(dir string, basedir string) (KeptnManifests, error) {
 	repoPath := filepath.Join(dir, basedir)
 	config := KeptnManifests{}
 
@@ -30,10 +32,13 @@ func parseKeptnManifests(dir string, basedir string) (KeptnManifests, error) {
 		}
 
 		yamlFile, err := ioutil.ReadFile(filepath.Join(repoPath, file.Name()))
+		if err != nil {
+			return KeptnManifests{}, fmt.Errorf("could not read file %s: %w", file.Name(), err)
+		}
 
 		splitInput, err := SplitYAML(yamlFile)
 		if err != nil {
-			return KeptnManifests{}, fmt.Errorf("could not split yaml: %w", err)
+			return KeptnManifests{}, fmt.Errorf("could not split yaml of file %s: %w", file.Name(), err)
 		}
 
 		scheme := runtime.NewScheme()
@@ -58,12 +63,14 @@ func parseKeptnManifests(dir string, basedir string) (KeptnManifests, error) {
 		objs := make([]interface{}, 0)
 
 		for _, input := range splitInput {
-			obj, _, err := decoder.Decode([]byte(input), nil, nil)
-			if err != nil {
-				fmt.Println("Could not parse file " + file.Name())
-				fmt.Println(err)
+			obj, gvk, err := decoder.Decode([]byte(input), nil, nil)
+			if runtime.IsNotRegisteredError(err) {
+				r.Log.Info("Ignoring manifest of unknown kind", "File", file.Name(), "Kind", gvk)
 				continue
 			}
+			if err != nil {
+				return KeptnManifests{}, fmt.Errorf("could not parse file %s: %w", file.Name(), err)
+			}
 			objs = append(objs, obj)
 		}
 
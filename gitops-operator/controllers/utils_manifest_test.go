@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseKeptnManifests(t *testing.T) {
+	service := `apiVersion: keptn.sh/v1
+kind: KeptnService
+metadata:
+  name: helloservice
+spec:
+  project: podtato-head
+  service: helloservice
+`
+	tests := []struct {
+		name         string
+		files        map[string]string
+		wantServices int
+		wantErr      bool
+	}{
+		{
+			name:         "valid manifest",
+			files:        map[string]string{"service.yaml": service},
+			wantServices: 1,
+		},
+		{
+			name:         "unknown kind is ignored",
+			files:        map[string]string{"service.yaml": service, "other.yaml": "apiVersion: example.com/v1\nkind: Other\nmetadata:\n  name: other\n"},
+			wantServices: 1,
+		},
+		{
+			name:    "invalid yaml",
+			files:   map[string]string{"service.yaml": service, "broken.yaml": "kind: [KeptnService\n"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid manifest",
+			files:   map[string]string{"service.yaml": service, "broken.yml": "apiVersion: keptn.sh/v1\nkind: KeptnService\nspec: invalid\n"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, ".keptn"), 0700))
+			for name, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, ".keptn", name), []byte(content), 0600))
+			}
+
+			manifests, err := newTestReconciler(t).parseKeptnManifests(dir, ".keptn")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, manifests.services, tt.wantServices)
+		})
+	}
+}
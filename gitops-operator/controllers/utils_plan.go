@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	gitopsv1 "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/api/v1"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//getSpecHash returns the hash of the spec of an object, which is stored in the keptn.sh/last-applied-hash annotation
+func getSpecHash(obj client.Object) (string, error) {
+	switch o := obj.(type) {
+	case *keptnv1.KeptnInstance:
+		return utils.GetHashStructure(o.Spec), nil
+	case *keptnv1.KeptnSequence:
+		return utils.GetHashStructure(o.Spec), nil
+	case *keptnv1.KeptnStage:
+		return utils.GetHashStructure(o.Spec), nil
+	case *keptnv1.KeptnProject:
+		return utils.GetHashStructure(o.Spec), nil
+	case *keptnv1.KeptnService:
+		return utils.GetHashStructure(o.Spec), nil
+	case *keptnv1.KeptnSequenceExecution:
+		return utils.GetHashStructure(o.Spec), nil
+	case *keptnv1.KeptnScheduledExec:
+		return utils.GetHashStructure(o.Spec), nil
+	case *keptnv1.KeptnServiceDeployment:
+		return utils.GetHashStructure(o.Spec), nil
+	default:
+		return "", fmt.Errorf("unsupported kind %T", obj)
+	}
+}
+
+//planChanges computes the changes which would be applied to the cluster for the given manifests
+func (r *KeptnGitRepositoryReconciler) planChanges(ctx context.Context, repo *gitopsv1.KeptnGitRepository, manifests KeptnManifests) ([]gitopsv1.PlannedChange, error) {
+	plan := []gitopsv1.PlannedChange{}
+
+	for _, obj := range manifests.objects() {
+		entry, err := r.inventoryEntry(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		hash, err := getSpecHash(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		found := obj.DeepCopyObject().(client.Object)
+		err = r.Client.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: repo.Namespace}, found)
+		if errors.IsNotFound(err) {
+			plan = append(plan, gitopsv1.PlannedChange{Action: gitopsv1.PlannedActionCreate, Kind: entry.Kind, Name: entry.Name})
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("could not get %s %s: %w", entry.Kind, entry.Name, err)
+		}
+
+		if found.GetAnnotations()["keptn.sh/last-applied-hash"] != hash {
+			plan = append(plan, gitopsv1.PlannedChange{Action: gitopsv1.PlannedActionUpdate, Kind: entry.Kind, Name: entry.Name})
+		}
+	}
+
+	if repo.Spec.Prune {
+		inventory, err := r.getInventory(manifests)
+		if err != nil {
+			return nil, err
+		}
+
+		prunable, err := r.getPrunableObjects(ctx, repo, inventory)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range prunable {
+			entry, err := r.inventoryEntry(obj)
+			if err != nil {
+				return nil, err
+			}
+			plan = append(plan, gitopsv1.PlannedChange{Action: gitopsv1.PlannedActionDelete, Kind: entry.Kind, Name: entry.Name})
+		}
+	}
+	return plan, nil
+}
+
+//reportPlan writes the planned changes of a commit to the status and emits an event for each of them
+func (r *KeptnGitRepositoryReconciler) reportPlan(ctx context.Context, repo *gitopsv1.KeptnGitRepository, plan []gitopsv1.PlannedChange, hash string) error {
+	for _, change := range plan {
+		r.Recorder.Event(repo, "Normal", "Planned", fmt.Sprintf("%s %s %s/%s (Reason: Dry run of commit %s)", change.Action, change.Kind, repo.Namespace, change.Name, hash))
+	}
+	if len(plan) == 0 {
+		r.Recorder.Event(repo, "Normal", "Planned", fmt.Sprintf("No changes (Reason: Dry run of commit %s)", hash))
+	}
+
+	repo.Status.Result = gitopsv1.KeptnGitRepositoryPhasePlanned
+	repo.Status.PlannedCommit = hash
+	repo.Status.Plan = plan
+	conditions.MarkReady(repo, gitopsv1.KeptnGitRepositoryPhasePlanned, fmt.Sprintf("Planned %d changes for commit %s", len(plan), hash))
+	return r.Client.Status().Update(ctx, repo)
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	gitopsv1 "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/api/v1"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"testing"
+)
+
+func TestPlanChanges(t *testing.T) {
+	repo := &gitopsv1.KeptnGitRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "podtato-head", Namespace: "keptn", UID: "repo-uid"},
+		Spec:       gitopsv1.KeptnGitRepositorySpec{Prune: true},
+	}
+
+	unchangedSpec := keptnv1.KeptnServiceSpec{Project: "podtato-head", Service: "unchanged"}
+	changedSpec := keptnv1.KeptnServiceSpec{Project: "podtato-head", Service: "changed"}
+
+	r := newTestReconciler(t,
+		repo,
+		controlledBy(t, repo, &keptnv1.KeptnService{ObjectMeta: metav1.ObjectMeta{
+			Name: "unchanged", Namespace: "keptn",
+			Annotations: map[string]string{"keptn.sh/last-applied-hash": utils.GetHashStructure(unchangedSpec)},
+		}}),
+		controlledBy(t, repo, &keptnv1.KeptnService{ObjectMeta: metav1.ObjectMeta{
+			Name: "changed", Namespace: "keptn",
+			Annotations: map[string]string{"keptn.sh/last-applied-hash": "outdated"},
+		}}),
+		controlledBy(t, repo, &keptnv1.KeptnStage{ObjectMeta: metav1.ObjectMeta{Name: "removed", Namespace: "keptn"}}),
+	)
+
+	manifests := KeptnManifests{
+		services: []keptnv1.KeptnService{
+			{ObjectMeta: metav1.ObjectMeta{Name: "unchanged"}, Spec: unchangedSpec},
+			{ObjectMeta: metav1.ObjectMeta{Name: "changed"}, Spec: changedSpec},
+			{ObjectMeta: metav1.ObjectMeta{Name: "new"}},
+		},
+	}
+
+	plan, err := r.planChanges(context.TODO(), repo, manifests)
+	require.NoError(t, err)
+	require.Equal(t, []gitopsv1.PlannedChange{
+		{Action: gitopsv1.PlannedActionUpdate, Kind: "KeptnService", Name: "changed"},
+		{Action: gitopsv1.PlannedActionCreate, Kind: "KeptnService", Name: "new"},
+		{Action: gitopsv1.PlannedActionDelete, Kind: "KeptnStage", Name: "removed"},
+	}, plan)
+
+	require.NoError(t, r.reportPlan(context.TODO(), repo, plan, "abc123"))
+
+	// nothing has been applied
+	require.Error(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "new", Namespace: "keptn"}, &keptnv1.KeptnService{}))
+	require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "removed", Namespace: "keptn"}, &keptnv1.KeptnStage{}))
+
+	updated := &gitopsv1.KeptnGitRepository{}
+	require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "podtato-head", Namespace: "keptn"}, updated))
+	require.Equal(t, gitopsv1.KeptnGitRepositoryPhasePlanned, updated.Status.Result)
+	require.Equal(t, "abc123", updated.Status.PlannedCommit)
+	require.Equal(t, plan, updated.Status.Plan)
+	require.Empty(t, updated.Status.LastCommit)
+	require.True(t, conditions.IsReady(updated))
+}
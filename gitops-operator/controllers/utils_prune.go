@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	gitopsv1 "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/api/v1"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sort"
+)
+
+//objects returns all objects of the manifests
+func (m KeptnManifests) objects() []client.Object {
+	var objects []client.Object
+	for i := range m.instances {
+		objects = append(objects, &m.instances[i])
+	}
+	for i := range m.sequences {
+		objects = append(objects, &m.sequences[i])
+	}
+	for i := range m.stages {
+		objects = append(objects, &m.stages[i])
+	}
+	for i := range m.projects {
+		objects = append(objects, &m.projects[i])
+	}
+	for i := range m.services {
+		objects = append(objects, &m.services[i])
+	}
+	for i := range m.execution {
+		objects = append(objects, &m.execution[i])
+	}
+	for i := range m.scheduledexec {
+		objects = append(objects, &m.scheduledexec[i])
+	}
+	for i := range m.servicedeployments {
+		objects = append(objects, &m.servicedeployments[i])
+	}
+	return objects
+}
+
+// prunableLists contains the lists of all kinds which are applied from a repository,
+// dependent objects come first, so they are removed before the objects they depend on
+func prunableLists() []client.ObjectList {
+	return []client.ObjectList{
+		&keptnv1.KeptnServiceDeploymentList{},
+		&keptnv1.KeptnScheduledExecList{},
+		&keptnv1.KeptnSequenceExecutionList{},
+		&keptnv1.KeptnServiceList{},
+		&keptnv1.KeptnProjectList{},
+		&keptnv1.KeptnStageList{},
+		&keptnv1.KeptnSequenceList{},
+		&keptnv1.KeptnInstanceList{},
+	}
+}
+
+//inventoryEntry returns the inventory entry of an object
+func (r *KeptnGitRepositoryReconciler) inventoryEntry(obj client.Object) (gitopsv1.InventoryEntry, error) {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return gitopsv1.InventoryEntry{}, err
+	}
+	return gitopsv1.InventoryEntry{Kind: gvk.Kind, Name: obj.GetName()}, nil
+}
+
+//getInventory returns the sorted inventory of the objects in the manifests
+func (r *KeptnGitRepositoryReconciler) getInventory(manifests KeptnManifests) ([]gitopsv1.InventoryEntry, error) {
+	inventory := []gitopsv1.InventoryEntry{}
+	for _, obj := range manifests.objects() {
+		entry, err := r.inventoryEntry(obj)
+		if err != nil {
+			return nil, err
+		}
+		inventory = append(inventory, entry)
+	}
+
+	sort.Slice(inventory, func(i, j int) bool {
+		if inventory[i].Kind != inventory[j].Kind {
+			return inventory[i].Kind < inventory[j].Kind
+		}
+		return inventory[i].Name < inventory[j].Name
+	})
+	return inventory, nil
+}
+
+//getPrunableObjects returns all objects controlled by the repository which are not part of the inventory
+func (r *KeptnGitRepositoryReconciler) getPrunableObjects(ctx context.Context, repo *gitopsv1.KeptnGitRepository, inventory []gitopsv1.InventoryEntry) ([]client.Object, error) {
+	desired := map[gitopsv1.InventoryEntry]bool{}
+	for _, entry := range inventory {
+		desired[entry] = true
+	}
+
+	var prunable []client.Object
+	for _, list := range prunableLists() {
+		if err := r.Client.List(ctx, list, client.InNamespace(repo.Namespace)); err != nil {
+			return nil, fmt.Errorf("could not list objects: %w", err)
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			obj, ok := item.(client.Object)
+			if !ok || !metav1.IsControlledBy(obj, repo) {
+				continue
+			}
+
+			entry, err := r.inventoryEntry(obj)
+			if err != nil {
+				return nil, err
+			}
+			if !desired[entry] {
+				prunable = append(prunable, obj)
+			}
+		}
+	}
+	return prunable, nil
+}
+
+//pruneObjects deletes all objects controlled by the repository which are not part of the inventory
+func (r *KeptnGitRepositoryReconciler) pruneObjects(ctx context.Context, repo *gitopsv1.KeptnGitRepository, inventory []gitopsv1.InventoryEntry) error {
+	prunable, err := r.getPrunableObjects(ctx, repo, inventory)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range prunable {
+		entry, err := r.inventoryEntry(obj)
+		if err != nil {
+			return err
+		}
+
+		r.Log.Info("Pruning object removed from repository", "Kind", entry.Kind, "Namespace", obj.GetNamespace(), "Name", obj.GetName())
+		if err := r.Client.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("could not prune %s %s: %w", entry.Kind, obj.GetName(), err)
+		}
+		r.Recorder.Event(repo, "Normal", "Pruned", fmt.Sprintf("Deleted %s %s/%s (Reason: Removed from repository)", entry.Kind, obj.GetNamespace(), obj.GetName()))
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	gitopsv1 "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/api/v1"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"testing"
+)
+
+func newTestReconciler(t *testing.T, objects ...client.Object) *KeptnGitRepositoryReconciler {
+	scheme := runtime.NewScheme()
+	require.NoError(t, gitopsv1.AddToScheme(scheme))
+	require.NoError(t, keptnv1.AddToScheme(scheme))
+
+	return &KeptnGitRepositoryReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Scheme:   scheme,
+		Log:      logr.Discard(),
+		Recorder: record.NewFakeRecorder(100),
+	}
+}
+
+func controlledBy(t *testing.T, repo *gitopsv1.KeptnGitRepository, obj client.Object) client.Object {
+	scheme := runtime.NewScheme()
+	require.NoError(t, gitopsv1.AddToScheme(scheme))
+	require.NoError(t, controllerutil.SetControllerReference(repo, obj, scheme))
+	return obj
+}
+
+func TestGetInventory(t *testing.T) {
+	r := newTestReconciler(t)
+	manifests := KeptnManifests{
+		services: []keptnv1.KeptnService{
+			{ObjectMeta: metav1.ObjectMeta{Name: "podtato-head"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "helloservice"}},
+		},
+		stages:        []keptnv1.KeptnStage{{ObjectMeta: metav1.ObjectMeta{Name: "dev"}}},
+		scheduledexec: []keptnv1.KeptnScheduledExec{{ObjectMeta: metav1.ObjectMeta{Name: "nightly"}}},
+	}
+
+	inventory, err := r.getInventory(manifests)
+	require.NoError(t, err)
+	require.Equal(t, []gitopsv1.InventoryEntry{
+		{Kind: "KeptnScheduledExec", Name: "nightly"},
+		{Kind: "KeptnService", Name: "helloservice"},
+		{Kind: "KeptnService", Name: "podtato-head"},
+		{Kind: "KeptnStage", Name: "dev"},
+	}, inventory)
+}
+
+func TestPruneObjects(t *testing.T) {
+	repo := &gitopsv1.KeptnGitRepository{ObjectMeta: metav1.ObjectMeta{Name: "podtato-head", Namespace: "keptn", UID: "repo-uid"}}
+	otherRepo := &gitopsv1.KeptnGitRepository{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "keptn", UID: "other-uid"}}
+
+	r := newTestReconciler(t,
+		repo,
+		controlledBy(t, repo, &keptnv1.KeptnService{ObjectMeta: metav1.ObjectMeta{Name: "kept", Namespace: "keptn"}}),
+		controlledBy(t, repo, &keptnv1.KeptnService{ObjectMeta: metav1.ObjectMeta{Name: "removed", Namespace: "keptn"}}),
+		controlledBy(t, repo, &keptnv1.KeptnStage{ObjectMeta: metav1.ObjectMeta{Name: "removed", Namespace: "keptn"}}),
+		controlledBy(t, otherRepo, &keptnv1.KeptnService{ObjectMeta: metav1.ObjectMeta{Name: "other-repository", Namespace: "keptn"}}),
+		&keptnv1.KeptnService{ObjectMeta: metav1.ObjectMeta{Name: "unmanaged", Namespace: "keptn"}},
+	)
+
+	err := r.pruneObjects(context.TODO(), repo, []gitopsv1.InventoryEntry{{Kind: "KeptnService", Name: "kept"}})
+	require.NoError(t, err)
+
+	exists := func(obj client.Object, name string) bool {
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "keptn"}, obj)
+		if errors.IsNotFound(err) {
+			return false
+		}
+		require.NoError(t, err)
+		return true
+	}
+
+	require.True(t, exists(&keptnv1.KeptnService{}, "kept"))
+	require.False(t, exists(&keptnv1.KeptnService{}, "removed"))
+	require.False(t, exists(&keptnv1.KeptnStage{}, "removed"))
+	require.True(t, exists(&keptnv1.KeptnService{}, "other-repository"))
+	require.True(t, exists(&keptnv1.KeptnService{}, "unmanaged"))
+}
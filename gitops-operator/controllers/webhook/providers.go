@@ -0,0 +1,268 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	errInvalidSignature = errors.New("invalid signature")
+	errUnknownProvider  = errors.New("unknown git provider")
+)
+
+//PushEvent contains the information of a push payload which is needed to find the affected repositories
+type PushEvent struct {
+	// Provider is the git provider which sent the event
+	Provider string
+	// RepositoryURLs contains all URLs of the repository (e.g. HTTP and SSH clone URLs)
+	RepositoryURLs []string
+	// Branches contains the names of the pushed branches
+	Branches []string
+}
+
+//provider parses the push payloads of a git provider
+type provider struct {
+	name string
+	// eventHeader contains the type of the event
+	eventHeader string
+	// pushEvents are the values of the event header which are considered as pushes
+	pushEvents []string
+	// verify checks if the payload has been sent by somebody knowing the secret
+	verify func(header http.Header, body []byte, secret string) error
+	// parse extracts the repository and branches of a push payload
+	parse func(body []byte) (*PushEvent, error)
+}
+
+// gitea also sets the X-GitHub-Event header and therefore has to be checked before github
+var providers = []provider{
+	{
+		name:        "gitea",
+		eventHeader: "X-Gitea-Event",
+		pushEvents:  []string{"push"},
+		verify: func(header http.Header, body []byte, secret string) error {
+			return verifyHMAC(header.Get("X-Gitea-Signature"), body, secret)
+		},
+		parse: parseGitHubPush,
+	},
+	{
+		name:        "github",
+		eventHeader: "X-GitHub-Event",
+		pushEvents:  []string{"push"},
+		verify: func(header http.Header, body []byte, secret string) error {
+			return verifyHMAC(strings.TrimPrefix(header.Get("X-Hub-Signature-256"), "sha256="), body, secret)
+		},
+		parse: parseGitHubPush,
+	},
+	{
+		name:        "gitlab",
+		eventHeader: "X-Gitlab-Event",
+		pushEvents:  []string{"Push Hook"},
+		verify: func(header http.Header, body []byte, secret string) error {
+			// gitlab does not sign payloads, but sends the secret token
+			if subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+				return errInvalidSignature
+			}
+			return nil
+		},
+		parse: parseGitLabPush,
+	},
+	{
+		name:        "bitbucket",
+		eventHeader: "X-Event-Key",
+		// repo:push is sent by Bitbucket Cloud, repo:refs_changed by Bitbucket Server
+		pushEvents: []string{"repo:push", "repo:refs_changed"},
+		verify: func(header http.Header, body []byte, secret string) error {
+			return verifyHMAC(strings.TrimPrefix(header.Get("X-Hub-Signature"), "sha256="), body, secret)
+		},
+		parse: parseBitbucketPush,
+	},
+}
+
+//ParsePushEvent detects the git provider of a request, verifies its signature and parses the push payload, nil is returned for other events
+func ParsePushEvent(header http.Header, body []byte, secret string) (*PushEvent, error) {
+	for _, p := range providers {
+		eventType := header.Get(p.eventHeader)
+		if eventType == "" {
+			continue
+		}
+
+		if secret != "" {
+			if err := p.verify(header, body, secret); err != nil {
+				return nil, err
+			}
+		}
+
+		if !contains(p.pushEvents, eventType) {
+			return nil, nil
+		}
+
+		event, err := p.parse(body)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s payload: %w", p.name, err)
+		}
+		event.Provider = p.name
+		return event, nil
+	}
+	return nil, errUnknownProvider
+}
+
+//verifyHMAC checks the hex encoded HMAC-SHA256 signature of a payload
+func verifyHMAC(signature string, body []byte, secret string) error {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || signature == "" {
+		return errInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+func parseGitHubPush(body []byte) (*PushEvent, error) {
+	payload := struct {
+		Ref        string `json:"ref"`
+		Repository struct {
+			CloneURL string `json:"clone_url"`
+			SSHURL   string `json:"ssh_url"`
+			HTMLURL  string `json:"html_url"`
+		} `json:"repository"`
+	}{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	return &PushEvent{
+		RepositoryURLs: []string{payload.Repository.CloneURL, payload.Repository.SSHURL, payload.Repository.HTMLURL},
+		Branches:       branchesOf(payload.Ref),
+	}, nil
+}
+
+func parseGitLabPush(body []byte) (*PushEvent, error) {
+	payload := struct {
+		Ref     string `json:"ref"`
+		Project struct {
+			GitHTTPURL string `json:"git_http_url"`
+			GitSSHURL  string `json:"git_ssh_url"`
+			WebURL     string `json:"web_url"`
+		} `json:"project"`
+	}{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	return &PushEvent{
+		RepositoryURLs: []string{payload.Project.GitHTTPURL, payload.Project.GitSSHURL, payload.Project.WebURL},
+		Branches:       branchesOf(payload.Ref),
+	}, nil
+}
+
+func parseBitbucketPush(body []byte) (*PushEvent, error) {
+	type link struct {
+		Href string `json:"href"`
+	}
+	payload := struct {
+		// Bitbucket Cloud
+		Push struct {
+			Changes []struct {
+				New *struct {
+					Type string `json:"type"`
+					Name string `json:"name"`
+				} `json:"new"`
+			} `json:"changes"`
+		} `json:"push"`
+		// Bitbucket Server
+		Changes []struct {
+			Ref struct {
+				ID string `json:"id"`
+			} `json:"ref"`
+			Type string `json:"type"`
+		} `json:"changes"`
+		Repository struct {
+			Links struct {
+				// html is a single link in Bitbucket Cloud
+				HTML link `json:"html"`
+				// clone contains the HTTP and SSH URLs in Bitbucket Server
+				Clone []link `json:"clone"`
+			} `json:"links"`
+		} `json:"repository"`
+	}{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	event := &PushEvent{RepositoryURLs: []string{payload.Repository.Links.HTML.Href}}
+	for _, clone := range payload.Repository.Links.Clone {
+		event.RepositoryURLs = append(event.RepositoryURLs, clone.Href)
+	}
+
+	for _, change := range payload.Push.Changes {
+		// new is null if a branch has been deleted
+		if change.New != nil && change.New.Type == "branch" {
+			event.Branches = append(event.Branches, change.New.Name)
+		}
+	}
+	for _, change := range payload.Changes {
+		if change.Type != "DELETE" {
+			event.Branches = append(event.Branches, branchesOf(change.Ref.ID)...)
+		}
+	}
+	return event, nil
+}
+
+//branchesOf returns the branch of a git reference, tags and other references don't contain a branch
+func branchesOf(ref string) []string {
+	if !strings.HasPrefix(ref, "refs/heads/") {
+		return nil
+	}
+	return []string{strings.TrimPrefix(ref, "refs/heads/")}
+}
+
+//NormalizeRepositoryURL converts the different URLs of a repository (https://, ssh://, scp-like, with or without .git) to a comparable form
+func NormalizeRepositoryURL(uri string) string {
+	uri = strings.TrimSpace(uri)
+	if uri == "" {
+		return ""
+	}
+
+	var host, path string
+	if strings.Contains(uri, "://") {
+		u, err := url.Parse(uri)
+		if err != nil {
+			return strings.ToLower(uri)
+		}
+		host, path = u.Host, u.Path
+	} else {
+		// scp-like syntax: [user@]host:path
+		if i := strings.Index(uri, "@"); i >= 0 {
+			uri = uri[i+1:]
+		}
+		host = uri
+		if i := strings.Index(uri, ":"); i >= 0 {
+			host, path = uri[:i], uri[i+1:]
+		}
+	}
+
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+	return strings.ToLower(host + "/" + path)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+const testSecret = "webhook-secret"
+
+const githubPayload = `{
+  "ref": "refs/heads/main",
+  "repository": {
+    "html_url": "https://github.com/keptn/podtato-head",
+    "clone_url": "https://github.com/keptn/podtato-head.git",
+    "ssh_url": "git@github.com:keptn/podtato-head.git"
+  }
+}`
+
+const gitlabPayload = `{
+  "object_kind": "push",
+  "ref": "refs/heads/develop",
+  "project": {
+    "web_url": "https://gitlab.com/keptn/podtato-head",
+    "git_ssh_url": "git@gitlab.com:keptn/podtato-head.git",
+    "git_http_url": "https://gitlab.com/keptn/podtato-head.git"
+  }
+}`
+
+const bitbucketCloudPayload = `{
+  "push": {
+    "changes": [
+      {"new": {"type": "branch", "name": "main"}},
+      {"new": {"type": "tag", "name": "v1.0.0"}},
+      {"new": null}
+    ]
+  },
+  "repository": {
+    "links": {"html": {"href": "https://bitbucket.org/keptn/podtato-head"}}
+  }
+}`
+
+const bitbucketServerPayload = `{
+  "changes": [
+    {"ref": {"id": "refs/heads/main"}, "type": "UPDATE"},
+    {"ref": {"id": "refs/heads/removed"}, "type": "DELETE"}
+  ],
+  "repository": {
+    "links": {
+      "clone": [
+        {"href": "ssh://git@bitbucket.example.com:7999/keptn/podtato-head.git", "name": "ssh"},
+        {"href": "https://bitbucket.example.com/scm/keptn/podtato-head.git", "name": "http"}
+      ]
+    }
+  }
+}`
+
+func sign(body string) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func header(values ...string) http.Header {
+	h := http.Header{}
+	for i := 0; i < len(values); i += 2 {
+		h.Set(values[i], values[i+1])
+	}
+	return h
+}
+
+func TestParsePushEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  http.Header
+		body    string
+		secret  string
+		want    *PushEvent
+		wantErr error
+	}{
+		{
+			name:   "github push",
+			header: header("X-GitHub-Event", "push", "X-Hub-Signature-256", "sha256="+sign(githubPayload)),
+			body:   githubPayload,
+			secret: testSecret,
+			want: &PushEvent{
+				Provider:       "github",
+				RepositoryURLs: []string{"https://github.com/keptn/podtato-head.git", "git@github.com:keptn/podtato-head.git", "https://github.com/keptn/podtato-head"},
+				Branches:       []string{"main"},
+			},
+		},
+		{
+			name:    "github push with invalid signature",
+			header:  header("X-GitHub-Event", "push", "X-Hub-Signature-256", "sha256="+sign("other")),
+			body:    githubPayload,
+			secret:  testSecret,
+			wantErr: errInvalidSignature,
+		},
+		{
+			name:    "github push without signature",
+			header:  header("X-GitHub-Event", "push"),
+			body:    githubPayload,
+			secret:  testSecret,
+			wantErr: errInvalidSignature,
+		},
+		{
+			name:   "github push without secret",
+			header: header("X-GitHub-Event", "push"),
+			body:   githubPayload,
+			want: &PushEvent{
+				Provider:       "github",
+				RepositoryURLs: []string{"https://github.com/keptn/podtato-head.git", "git@github.com:keptn/podtato-head.git", "https://github.com/keptn/podtato-head"},
+				Branches:       []string{"main"},
+			},
+		},
+		{
+			name:   "github ping",
+			header: header("X-GitHub-Event", "ping", "X-Hub-Signature-256", "sha256="+sign("{}")),
+			body:   "{}",
+			secret: testSecret,
+		},
+		{
+			name:   "gitea push",
+			header: header("X-GitHub-Event", "push", "X-Gitea-Event", "push", "X-Gitea-Signature", sign(githubPayload)),
+			body:   githubPayload,
+			secret: testSecret,
+			want: &PushEvent{
+				Provider:       "gitea",
+				RepositoryURLs: []string{"https://github.com/keptn/podtato-head.git", "git@github.com:keptn/podtato-head.git", "https://github.com/keptn/podtato-head"},
+				Branches:       []string{"main"},
+			},
+		},
+		{
+			name:   "gitlab push",
+			header: header("X-Gitlab-Event", "Push Hook", "X-Gitlab-Token", testSecret),
+			body:   gitlabPayload,
+			secret: testSecret,
+			want: &PushEvent{
+				Provider:       "gitlab",
+				RepositoryURLs: []string{"https://gitlab.com/keptn/podtato-head.git", "git@gitlab.com:keptn/podtato-head.git", "https://gitlab.com/keptn/podtato-head"},
+				Branches:       []string{"develop"},
+			},
+		},
+		{
+			name:    "gitlab push with invalid token",
+			header:  header("X-Gitlab-Event", "Push Hook", "X-Gitlab-Token", "other"),
+			body:    gitlabPayload,
+			secret:  testSecret,
+			wantErr: errInvalidSignature,
+		},
+		{
+			name:   "gitlab tag push",
+			header: header("X-Gitlab-Event", "Tag Push Hook", "X-Gitlab-Token", testSecret),
+			body:   gitlabPayload,
+			secret: testSecret,
+		},
+		{
+			name:   "bitbucket cloud push",
+			header: header("X-Event-Key", "repo:push", "X-Hub-Signature", "sha256="+sign(bitbucketCloudPayload)),
+			body:   bitbucketCloudPayload,
+			secret: testSecret,
+			want: &PushEvent{
+				Provider:       "bitbucket",
+				RepositoryURLs: []string{"https://bitbucket.org/keptn/podtato-head"},
+				Branches:       []string{"main"},
+			},
+		},
+		{
+			name:   "bitbucket server push",
+			header: header("X-Event-Key", "repo:refs_changed", "X-Hub-Signature", "sha256="+sign(bitbucketServerPayload)),
+			body:   bitbucketServerPayload,
+			secret: testSecret,
+			want: &PushEvent{
+				Provider:       "bitbucket",
+				RepositoryURLs: []string{"", "ssh://git@bitbucket.example.com:7999/keptn/podtato-head.git", "https://bitbucket.example.com/scm/keptn/podtato-head.git"},
+				Branches:       []string{"main"},
+			},
+		},
+		{
+			name:    "unknown provider",
+			header:  header("X-Other-Event", "push"),
+			body:    githubPayload,
+			wantErr: errUnknownProvider,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePushEvent(tt.header, []byte(tt.body), tt.secret)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParsePushEventInvalidPayload(t *testing.T) {
+	_, err := ParsePushEvent(header("X-GitHub-Event", "push"), []byte("not json"), "")
+	require.Error(t, err)
+}
+
+func TestNormalizeRepositoryURL(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{uri: "https://github.com/keptn/podtato-head", want: "github.com/keptn/podtato-head"},
+		{uri: "https://github.com/keptn/podtato-head.git", want: "github.com/keptn/podtato-head"},
+		{uri: "https://user@github.com/Keptn/podtato-head/", want: "github.com/keptn/podtato-head"},
+		{uri: "git@github.com:keptn/podtato-head.git", want: "github.com/keptn/podtato-head"},
+		{uri: "ssh://git@github.com/keptn/podtato-head.git", want: "github.com/keptn/podtato-head"},
+		{uri: "ssh://git@bitbucket.example.com:7999/keptn/podtato-head.git", want: "bitbucket.example.com:7999/keptn/podtato-head"},
+		{uri: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			require.Equal(t, tt.want, NormalizeRepositoryURL(tt.uri))
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-logr/logr"
+	gitopsv1 "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/api/v1"
+	"io/ioutil"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+// Path is the path on which push events are received
+const Path = "/webhook"
+
+// maxPayloadSize limits the size of accepted payloads, push payloads of big commits are usually far below
+const maxPayloadSize = 10 << 20
+
+const defaultBranch = "main"
+
+// TriggeredAnnotation is set on KeptnGitRepositories which have been pushed to, the update of the annotation triggers
+// their reconciliation by the controller of the leader
+const TriggeredAnnotation = "keptn.sh/webhook-triggered-at"
+
+//Receiver is an HTTP server receiving push events of git providers, it triggers the reconciliation of all KeptnGitRepositories which are affected by a push
+type Receiver struct {
+	// Client is used to find and annotate the KeptnGitRepositories of a push
+	Client client.Client
+	// BindAddress is the address the receiver listens on
+	BindAddress string
+	// Secret is used to verify the payloads, payloads are not verified if it is empty
+	Secret string
+	Log    logr.Logger
+}
+
+//NewReceiver creates a receiver listening on the given address
+func NewReceiver(client client.Client, bindAddress string, secret string, log logr.Logger) *Receiver {
+	return &Receiver{
+		Client:      client,
+		BindAddress: bindAddress,
+		Secret:      secret,
+		Log:         log,
+	}
+}
+
+//NeedLeaderElection returns false, as the receiver only annotates repositories it runs on every replica, so that push
+//events can be received by whichever replica the webhook service routes them to
+func (r *Receiver) NeedLeaderElection() bool {
+	return false
+}
+
+//Start serves the webhook endpoint until the context is done, this makes the receiver a manager.Runnable
+func (r *Receiver) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, r)
+
+	server := &http.Server{
+		Addr:              r.BindAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			r.Log.Error(err, "Could not shut down webhook receiver")
+		}
+	}()
+
+	r.Log.Info("Starting webhook receiver", "address", r.BindAddress, "path", Path)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("could not start webhook receiver: %w", err)
+	}
+	return nil
+}
+
+//ServeHTTP handles a single push event
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "could not read payload", http.StatusBadRequest)
+		return
+	}
+
+	push, err := ParsePushEvent(req.Header, body, r.Secret)
+	switch {
+	case errors.Is(err, errInvalidSignature):
+		r.Log.Info("Rejected webhook with invalid signature")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case push == nil:
+		// e.g. ping events which are sent when a webhook is created
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "ignored event")
+		return
+	}
+
+	repositories, err := r.findRepositories(req.Context(), push)
+	if err != nil {
+		r.Log.Error(err, "Could not find repositories of push", "provider", push.Provider)
+		http.Error(w, "could not find repositories", http.StatusInternalServerError)
+		return
+	}
+
+	for i := range repositories {
+		r.Log.Info("Triggering reconciliation", "provider", push.Provider, "namespace", repositories[i].Namespace, "name", repositories[i].Name)
+		if err := r.trigger(req.Context(), &repositories[i]); err != nil {
+			r.Log.Error(err, "Could not trigger reconciliation", "namespace", repositories[i].Namespace, "name", repositories[i].Name)
+			http.Error(w, "could not trigger repositories", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintf(w, "triggered %d repositories", len(repositories))
+}
+
+//trigger annotates a repository with the current time, which causes the controller to reconcile it
+func (r *Receiver) trigger(ctx context.Context, repository *gitopsv1.KeptnGitRepository) error {
+	patch := client.MergeFrom(repository.DeepCopy())
+	annotations := repository.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[TriggeredAnnotation] = time.Now().UTC().Format(time.RFC3339Nano)
+	repository.SetAnnotations(annotations)
+	return r.Client.Patch(ctx, repository, patch)
+}
+
+//findRepositories returns all KeptnGitRepositories which reference the pushed repository and branch
+func (r *Receiver) findRepositories(ctx context.Context, push *PushEvent) ([]gitopsv1.KeptnGitRepository, error) {
+	list := &gitopsv1.KeptnGitRepositoryList{}
+	if err := r.Client.List(ctx, list); err != nil {
+		return nil, err
+	}
+
+	urls := map[string]bool{}
+	for _, uri := range push.RepositoryURLs {
+		if normalized := NormalizeRepositoryURL(uri); normalized != "" {
+			urls[normalized] = true
+		}
+	}
+
+	var repositories []gitopsv1.KeptnGitRepository
+	for _, repository := range list.Items {
+		branch := repository.Spec.Branch
+		if branch == "" {
+			branch = defaultBranch
+		}
+		if urls[NormalizeRepositoryURL(repository.Spec.Repository)] && contains(push.Branches, branch) {
+			repositories = append(repositories, repository)
+		}
+	}
+	return repositories, nil
+}
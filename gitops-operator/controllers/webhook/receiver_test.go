@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	gitopsv1 "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/api/v1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"net/http"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func newTestReceiver(t *testing.T) *Receiver {
+	scheme := runtime.NewScheme()
+	require.NoError(t, gitopsv1.AddToScheme(scheme))
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&gitopsv1.KeptnGitRepository{
+			ObjectMeta: metav1.ObjectMeta{Name: "https-default-branch", Namespace: "keptn"},
+			Spec:       gitopsv1.KeptnGitRepositorySpec{Repository: "https://github.com/keptn/podtato-head"},
+		},
+		&gitopsv1.KeptnGitRepository{
+			ObjectMeta: metav1.ObjectMeta{Name: "ssh-main", Namespace: "other"},
+			Spec:       gitopsv1.KeptnGitRepositorySpec{Repository: "git@github.com:keptn/podtato-head.git", Branch: "main"},
+		},
+		&gitopsv1.KeptnGitRepository{
+			ObjectMeta: metav1.ObjectMeta{Name: "develop", Namespace: "keptn"},
+			Spec:       gitopsv1.KeptnGitRepositorySpec{Repository: "https://github.com/keptn/podtato-head.git", Branch: "develop"},
+		},
+		&gitopsv1.KeptnGitRepository{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-repository", Namespace: "keptn"},
+			Spec:       gitopsv1.KeptnGitRepositorySpec{Repository: "https://github.com/keptn/other"},
+		},
+	).Build()
+
+	return NewReceiver(fakeClient, "", testSecret, logr.Discard())
+}
+
+func TestReceiverServeHTTP(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		header    http.Header
+		body      string
+		wantCode  int
+		wantNames []string
+	}{
+		{
+			name:      "push to main",
+			method:    http.MethodPost,
+			header:    header("X-GitHub-Event", "push", "X-Hub-Signature-256", "sha256="+sign(githubPayload)),
+			body:      githubPayload,
+			wantCode:  http.StatusAccepted,
+			wantNames: []string{"https-default-branch", "ssh-main"},
+		},
+		{
+			name:      "push to other branch",
+			method:    http.MethodPost,
+			header:    header("X-GitHub-Event", "push", "X-Hub-Signature-256", "sha256="+sign(strings.Replace(githubPayload, "main", "feature", 1))),
+			body:      strings.Replace(githubPayload, "main", "feature", 1),
+			wantCode:  http.StatusAccepted,
+			wantNames: nil,
+		},
+		{
+			name:     "invalid signature",
+			method:   http.MethodPost,
+			header:   header("X-GitHub-Event", "push", "X-Hub-Signature-256", "sha256="+sign("other")),
+			body:     githubPayload,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "ping",
+			method:   http.MethodPost,
+			header:   header("X-GitHub-Event", "ping", "X-Hub-Signature-256", "sha256="+sign("{}")),
+			body:     "{}",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "unknown provider",
+			method:   http.MethodPost,
+			body:     githubPayload,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "get",
+			method:   http.MethodGet,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := newTestReceiver(t)
+
+			req := httptest.NewRequest(tt.method, Path, strings.NewReader(tt.body))
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			rec := httptest.NewRecorder()
+			receiver.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+
+			list := &gitopsv1.KeptnGitRepositoryList{}
+			require.NoError(t, receiver.Client.List(context.TODO(), list))
+			var names []string
+			for _, repository := range list.Items {
+				if repository.Annotations[TriggeredAnnotation] != "" {
+					names = append(names, repository.Name)
+				}
+			}
+			sort.Strings(names)
+			require.Equal(t, tt.wantNames, names)
+		})
+	}
+}
@@ -21,8 +21,12 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -38,7 +42,9 @@ import (
 	gitopsv1 "github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/api/v1"
 	"github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/controllers"
 	"github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/controllers/common"
+	"github.com/keptn-sandbox/keptn-gitops-operator/gitops-operator/controllers/webhook"
 	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/secrets"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -59,11 +65,25 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var gitCacheDir string
+	var gitCacheMaxAge time.Duration
+	var pollingInterval time.Duration
+	var webhookAddr string
+	var webhookSecret string
+	var webhookInsecure bool
+	var allowLocalSecrets bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":9080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":9081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&gitCacheDir, "git-cache-dir", filepath.Join(os.TempDir(), "keptn-gitops-cache"), "The directory in which checkouts of git repositories are kept.")
+	flag.DurationVar(&pollingInterval, "polling-interval", 30*time.Second, "The interval in which git repositories are checked for changes.")
+	flag.StringVar(&webhookAddr, "webhook-bind-address", "", "The address the git webhook receiver binds to (e.g. :9082), the receiver is disabled if empty.")
+	flag.StringVar(&webhookSecret, "webhook-secret", "", "The secret used to verify git webhook payloads (e.g. env:WEBHOOK_SECRET), required unless --webhook-insecure is set.")
+	flag.BoolVar(&webhookInsecure, "webhook-insecure", false, "Accept git webhook payloads without verifying them if no webhook secret is configured.")
+	flag.DurationVar(&gitCacheMaxAge, "git-cache-max-age", 24*time.Hour, "The duration after which unused checkouts of git repositories are removed.")
+	flag.BoolVar(&allowLocalSecrets, "allow-local-secret-references", false, "Allow env: and file: secrets in resources, these expose the environment and file system of the operator.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -85,12 +105,50 @@ func main() {
 		os.Exit(1)
 	}
 
+	// k8s: secret references are read directly from the API server, so Secrets don't need to be cached
+	secrets.Register(secrets.KubernetesPrefix, secrets.NewKubernetesResolver(mgr.GetAPIReader()))
+	secrets.AllowLocalReferences(allowLocalSecrets)
+
+	if gitCacheMaxAge <= 0 {
+		setupLog.Error(fmt.Errorf("--git-cache-max-age has to be positive, got %s", gitCacheMaxAge), "unable to set up repository cache")
+		os.Exit(1)
+	}
+	repositoryCache := common.NewRepositoryCache(gitCacheDir, gitCacheMaxAge)
+	if err := mgr.Add(repositoryCache); err != nil {
+		setupLog.Error(err, "unable to set up repository cache")
+		os.Exit(1)
+	}
+
+	if webhookAddr != "" {
+		secret, err := secrets.Resolve(context.Background(), webhookSecret)
+		if err != nil {
+			setupLog.Error(err, "unable to resolve webhook secret")
+			os.Exit(1)
+		}
+		if secret == "" {
+			if !webhookInsecure {
+				setupLog.Error(fmt.Errorf("no webhook secret configured"), "unable to set up webhook receiver, set --webhook-secret or --webhook-insecure")
+				os.Exit(1)
+			}
+			setupLog.Info("WARNING: no webhook secret configured, webhook payloads are not verified")
+		}
+
+		// the receiver runs on every replica and triggers the controller of the leader by annotating repositories
+		receiver := webhook.NewReceiver(mgr.GetClient(), webhookAddr, secret, ctrl.Log.WithName("webhook"))
+		if err := mgr.Add(receiver); err != nil {
+			setupLog.Error(err, "unable to set up webhook receiver")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controllers.KeptnGitRepositoryReconciler{
 		Client:           mgr.GetClient(),
 		Scheme:           mgr.GetScheme(),
 		Recorder:         mgr.GetEventRecorderFor("keptnproject-controller"),
 		Log:              ctrl.Log.WithName("controllers").WithName("KeptnGitRepository"),
 		GitClientFactory: &common.GoGitClientFactory{},
+		RepositoryCache:  repositoryCache,
+		PollingInterval:  pollingInterval,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KeptnGitRepository")
 		os.Exit(1)
@@ -0,0 +1,61 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+const (
+	// ConditionReady is True if the resource has been reconciled successfully
+	ConditionReady = "Ready"
+	// ConditionReconciling is True while the controller is working towards the desired state, e.g. waiting for a dependency
+	ConditionReconciling = "Reconciling"
+	// ConditionStalled is True if the controller failed to reconcile the resource
+	ConditionStalled = "Stalled"
+)
+
+const (
+	// ReasonReconciled is used if the resource has been reconciled successfully
+	ReasonReconciled = "ReconciliationSucceeded"
+	// ReasonProgressing is used while changes are being applied
+	ReasonProgressing = "Progressing"
+	// ReasonFailed is used if the reconciliation failed
+	ReasonFailed = "ReconciliationFailed"
+	// ReasonInvalidSpec is used if the spec of the resource can not be processed
+	ReasonInvalidSpec = "InvalidSpec"
+	// ReasonKeptnInstanceNotFound is used if no KeptnInstance could be found for the resource
+	ReasonKeptnInstanceNotFound = "KeptnInstanceNotFound"
+	// ReasonKeptnAPIError is used if a request to the Keptn API failed
+	ReasonKeptnAPIError = "KeptnAPIError"
+	// ReasonProjectNotFound is used while the project of the resource does not exist
+	ReasonProjectNotFound = "ProjectNotFound"
+	// ReasonServiceNotFound is used while the service of the resource does not exist
+	ReasonServiceNotFound = "ServiceNotFound"
+	// ReasonShipyardNotFound is used while the shipyard of the project of the resource does not exist
+	ReasonShipyardNotFound = "ShipyardNotFound"
+	// ReasonUpstreamError is used if the upstream repository of a project could not be updated
+	ReasonUpstreamError = "UpstreamError"
+	// ReasonDeploymentFailed is used if the sequence triggered by a KeptnServiceDeployment finished with result fail
+	ReasonDeploymentFailed = "DeploymentFailed"
+	// ReasonDeletionProtected is used while the deletion of a resource is blocked by the deletion protection annotation
+	ReasonDeletionProtected = "DeletionProtected"
+	// ReasonStageInUse is used while the deletion of a KeptnStage is blocked by KeptnServiceDeployments targeting it
+	ReasonStageInUse = "StageInUse"
+	// ReasonSequenceInUse is used while the deletion of a KeptnSequence is blocked by KeptnStages referring to it
+	ReasonSequenceInUse = "SequenceInUse"
+	// ReasonShipyardDrift is used if the shipyard in the upstream repository differs from the KeptnShipyard
+	ReasonShipyardDrift = "ShipyardDrift"
+	// ReasonSLOConflict is used if another KeptnSLO already defines the SLO of the service in one of the stages
+	ReasonSLOConflict = "SLOConflict"
+)
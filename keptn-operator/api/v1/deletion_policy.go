@@ -0,0 +1,44 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strconv"
+)
+
+// DeletionPolicy defines what happens to the Keptn object of a custom resource when the custom resource is deleted
+//+kubebuilder:validation:Enum=Delete;Retain;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the object in Keptn together with the custom resource
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain keeps the object in Keptn, the deletion is still handled by the operator and reported as event
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyOrphan keeps the object in Keptn and does not register a finalizer, so the operator is not involved in the deletion at all
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// DeletionProtectionAnnotation blocks the deletion of a KeptnProject or KeptnService if it is set to "true"
+const DeletionProtectionAnnotation = "keptn.sh/deletion-protection"
+
+// IsDeletionProtected returns true if the deletion of the object is blocked by the DeletionProtectionAnnotation
+func IsDeletionProtected(obj metav1.Object) bool {
+	protected, err := strconv.ParseBool(obj.GetAnnotations()[DeletionProtectionAnnotation])
+	return err == nil && protected
+}
@@ -0,0 +1,32 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// GitSSHCredentials configures the authentication against git remotes using SSH
+type GitSSHCredentials struct {
+	// PrivateKey is the PEM encoded private key, it may be specified in any supported secret format (e.g. k8s:, file:)
+	PrivateKey string `json:"privateKey,omitempty"`
+	// PrivateKeySecretRef references a Secret containing the private key, takes precedence over PrivateKey
+	PrivateKeySecretRef *SecretKeyReference `json:"privateKeySecretRef,omitempty"`
+	// Passphrase of the private key, if it is encrypted
+	Passphrase string `json:"passphrase,omitempty"`
+	// KnownHosts contains known_hosts entries the host key of the remote is verified against, required unless
+	// InsecureSkipHostKeyVerification is set
+	KnownHosts string `json:"knownHosts,omitempty"`
+	// InsecureSkipHostKeyVerification disables the verification of the host key of the remote if no KnownHosts are given
+	InsecureSkipHostKeyVerification bool `json:"insecureSkipHostKeyVerification,omitempty"`
+}
@@ -40,10 +40,21 @@ type KeptnDeploymentContextStatus struct {
 	KeptnContext    string            `json:"keptnContext"`
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// ObservedGeneration is the generation of the spec which has been reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the reconciliation (Ready, Reconciling, Stalled)
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KeptnDeploymentContext is the Schema for the keptndeploymentcontexts API
 type KeptnDeploymentContext struct {
@@ -63,6 +74,26 @@ type KeptnDeploymentContextList struct {
 	Items           []KeptnDeploymentContext `json:"items"`
 }
 
+// GetConditions returns the conditions of the KeptnDeploymentContext
+func (in *KeptnDeploymentContext) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions of the KeptnDeploymentContext
+func (in *KeptnDeploymentContext) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// GetObservedGeneration returns the generation which has been reconciled
+func (in *KeptnDeploymentContext) GetObservedGeneration() int64 {
+	return in.Status.ObservedGeneration
+}
+
+// SetObservedGeneration sets the generation which has been reconciled
+func (in *KeptnDeploymentContext) SetObservedGeneration(generation int64) {
+	in.Status.ObservedGeneration = generation
+}
+
 func init() {
 	SchemeBuilder.Register(&KeptnDeploymentContext{}, &KeptnDeploymentContextList{})
 }
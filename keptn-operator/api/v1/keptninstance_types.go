@@ -18,6 +18,7 @@ package v1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -28,6 +29,40 @@ type KeptnInstanceSpec struct {
 	APIUrl    string `json:"apiUrl"`
 	TokenType string `json:"tokenType,omitempty"`
 	Token     string `json:"apiToken,omitempty"`
+	// SecretRef references a Secret containing the API token, takes precedence over Token
+	SecretRef *SecretKeyReference `json:"secretRef,omitempty"`
+}
+
+// KeptnInstanceReference references a KeptnInstance
+type KeptnInstanceReference struct {
+	// Name is the name of the KeptnInstance
+	Name string `json:"name"`
+	// Namespace is the namespace of the KeptnInstance, defaults to the namespace of the referencing resource. Instances in
+	// other namespaces have to allow the reference using the keptn.sh/allowed-namespaces annotation
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Reference returns a reference to the KeptnInstance including its namespace
+func (in *KeptnInstance) Reference() *KeptnInstanceReference {
+	return &KeptnInstanceReference{Name: in.Name, Namespace: in.Namespace}
+}
+
+// AllowedNamespacesAnnotation lists the namespaces (comma separated, "*" for all namespaces) whose resources may reference
+// a KeptnInstance or KeptnSequence in another namespace, an object can always be referenced from its own namespace
+const AllowedNamespacesAnnotation = "keptn.sh/allowed-namespaces"
+
+// IsReferenceAllowed returns true if the KeptnInstance or KeptnSequence may be referenced from resources in the given namespace
+func IsReferenceAllowed(obj metav1.Object, namespace string) bool {
+	if obj.GetNamespace() == namespace {
+		return true
+	}
+	for _, allowed := range strings.Split(obj.GetAnnotations()[AllowedNamespacesAnnotation], ",") {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == "*" || allowed == namespace {
+			return true
+		}
+	}
+	return false
 }
 
 // KeptnInstanceStatus defines the observed state of KeptnInstance
@@ -36,10 +71,20 @@ type KeptnInstanceStatus struct {
 	CurrentToken string      `json:"currentToken"`
 	LastUpdated  metav1.Time `json:"lastUpdated,omitempty"`
 	Scheme       string      `json:"APIScheme,omitempty"`
+	// ObservedGeneration is the generation of the spec which has been reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the reconciliation (Ready, Reconciling, Stalled)
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KeptnInstance is the Schema for the keptninstances API
 type KeptnInstance struct {
@@ -59,6 +104,26 @@ type KeptnInstanceList struct {
 	Items           []KeptnInstance `json:"items"`
 }
 
+// GetConditions returns the conditions of the KeptnInstance
+func (in *KeptnInstance) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions of the KeptnInstance
+func (in *KeptnInstance) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// GetObservedGeneration returns the generation which has been reconciled
+func (in *KeptnInstance) GetObservedGeneration() int64 {
+	return in.Status.ObservedGeneration
+}
+
+// SetObservedGeneration sets the generation which has been reconciled
+func (in *KeptnInstance) SetObservedGeneration(generation int64) {
+	in.Status.ObservedGeneration = generation
+}
+
 func init() {
 	SchemeBuilder.Register(&KeptnInstance{}, &KeptnInstanceList{})
 }
@@ -34,17 +34,107 @@ type KeptnProjectSpec struct {
 	Password        string `json:"password,omitempty"`
 	InitialShipyard string `json:"initialShipyard,omitempty"`
 	DefaultBranch   string `json:"defaultBranch,omitempty"`
+	// InitialShipyardRef references a ConfigMap key containing the shipyard the project is created with, InitialShipyard
+	// (base64 encoded) takes precedence. If neither is set, the shipyard is composed of the KeptnStages and KeptnSequences of the project
+	InitialShipyardRef *ConfigMapKeyReference `json:"initialShipyardRef,omitempty"`
+	// SecretRef references a Secret containing the git password, takes precedence over Password
+	SecretRef *SecretKeyReference `json:"secretRef,omitempty"`
+	// SSH configures SSH authentication, used instead of username/password if a private key is given
+	SSH *GitSSHCredentials `json:"ssh,omitempty"`
+	// InstanceRef references the KeptnInstance the project is managed on, defaults to the KeptnInstance "default" in the namespace of the project
+	InstanceRef *KeptnInstanceReference `json:"instanceRef,omitempty"`
+	// DeletionPolicy defines if the project is deleted in Keptn when the KeptnProject is deleted, defaults to Retain
+	//+kubebuilder:default=Retain
+	//+optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// ShipyardDriftPolicy defines what happens if the shipyard.yaml in the upstream repository has been changed outside of the operator, defaults to Report
+	//+kubebuilder:default=Report
+	//+optional
+	ShipyardDriftPolicy ShipyardDriftPolicy `json:"shipyardDriftPolicy,omitempty"`
+	// ShipyardUploadMode defines how the shipyard is written to the project, defaults to Git. KeptnAPI uploads it via the
+	// resource API of Keptn and does not need write access to the upstream repository
+	//+kubebuilder:default=Git
+	//+optional
+	ShipyardUploadMode ShipyardUploadMode `json:"shipyardUploadMode,omitempty"`
+}
+
+// ShipyardUploadMode defines how the shipyard is written to the project
+//+kubebuilder:validation:Enum=Git;KeptnAPI
+type ShipyardUploadMode string
+
+const (
+	// ShipyardUploadModeGit pushes the shipyard to the default branch of the upstream repository
+	ShipyardUploadModeGit ShipyardUploadMode = "Git"
+	// ShipyardUploadModeKeptnAPI uploads the shipyard as a project resource via the Keptn API
+	ShipyardUploadModeKeptnAPI ShipyardUploadMode = "KeptnAPI"
+)
+
+// ShipyardDriftPolicy defines how changes of the shipyard in the upstream repository are handled
+//+kubebuilder:validation:Enum=Report;Overwrite
+type ShipyardDriftPolicy string
+
+const (
+	// ShipyardDriftPolicyReport reports a changed shipyard with an event and the condition reason ShipyardDrift
+	ShipyardDriftPolicyReport ShipyardDriftPolicy = "Report"
+	// ShipyardDriftPolicyOverwrite replaces a changed shipyard with the one composed by the operator
+	ShipyardDriftPolicyOverwrite ShipyardDriftPolicy = "Overwrite"
+)
+
+// ConfigMapKeyReference references a key of a ConfigMap in the namespace of the referencing resource
+type ConfigMapKeyReference struct {
+	// Name is the name of the ConfigMap
+	Name string `json:"name"`
+	// Key is the key in the ConfigMap which contains the value
+	Key string `json:"key"`
+}
+
+// GetDeletionPolicy returns the deletion policy of the project, Retain is used if none is set
+func (s KeptnProjectSpec) GetDeletionPolicy() DeletionPolicy {
+	if s.DeletionPolicy == "" {
+		return DeletionPolicyRetain
+	}
+	return s.DeletionPolicy
+}
+
+// GetShipyardDriftPolicy returns the shipyard drift policy of the project, Report is used if none is set
+func (s KeptnProjectSpec) GetShipyardDriftPolicy() ShipyardDriftPolicy {
+	if s.ShipyardDriftPolicy == "" {
+		return ShipyardDriftPolicyReport
+	}
+	return s.ShipyardDriftPolicy
+}
+
+// GetShipyardUploadMode returns the shipyard upload mode of the project, Git is used if none is set
+func (s KeptnProjectSpec) GetShipyardUploadMode() ShipyardUploadMode {
+	if s.ShipyardUploadMode == "" {
+		return ShipyardUploadModeGit
+	}
+	return s.ShipyardUploadMode
 }
 
 // KeptnProjectStatus defines the observed state of KeptnProject
 type KeptnProjectStatus struct {
 	ProjectExists bool `json:"projectExists,omitempty"`
+	// LastAppliedHash is the hash of the upstream repository, user and version of the git token which have been applied to
+	// the project in Keptn, the token itself is not part of the hash
+	LastAppliedHash string `json:"lastAppliedHash,omitempty"`
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// ObservedGeneration is the generation of the spec which has been reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the reconciliation (Ready, Reconciling, Stalled)
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KeptnProject is the Schema for the keptnprojects API
 type KeptnProject struct {
@@ -64,6 +154,26 @@ type KeptnProjectList struct {
 	Items           []KeptnProject `json:"items"`
 }
 
+// GetConditions returns the conditions of the KeptnProject
+func (in *KeptnProject) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions of the KeptnProject
+func (in *KeptnProject) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// GetObservedGeneration returns the generation which has been reconciled
+func (in *KeptnProject) GetObservedGeneration() int64 {
+	return in.Status.ObservedGeneration
+}
+
+// SetObservedGeneration sets the generation which has been reconciled
+func (in *KeptnProject) SetObservedGeneration(generation int64) {
+	in.Status.ObservedGeneration = generation
+}
+
 func init() {
 	SchemeBuilder.Register(&KeptnProject{}, &KeptnProjectList{})
 }
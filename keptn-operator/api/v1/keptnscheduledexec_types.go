@@ -36,10 +36,20 @@ type KeptnScheduledExecSpec struct {
 // KeptnScheduledExecStatus defines the observed state of KeptnScheduledExec
 type KeptnScheduledExecStatus struct {
 	Started bool `json:"started,omitempty"`
+	// ObservedGeneration is the generation of the spec which has been reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the reconciliation (Ready, Reconciling, Stalled)
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KeptnScheduledExec is the Schema for the keptnscheduledexecs API
 type KeptnScheduledExec struct {
@@ -59,6 +69,26 @@ type KeptnScheduledExecList struct {
 	Items           []KeptnScheduledExec `json:"items"`
 }
 
+// GetConditions returns the conditions of the KeptnScheduledExec
+func (in *KeptnScheduledExec) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions of the KeptnScheduledExec
+func (in *KeptnScheduledExec) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// GetObservedGeneration returns the generation which has been reconciled
+func (in *KeptnScheduledExec) GetObservedGeneration() int64 {
+	return in.Status.ObservedGeneration
+}
+
+// SetObservedGeneration sets the generation which has been reconciled
+func (in *KeptnScheduledExec) SetObservedGeneration(generation int64) {
+	in.Status.ObservedGeneration = generation
+}
+
 func init() {
 	SchemeBuilder.Register(&KeptnScheduledExec{}, &KeptnScheduledExecList{})
 }
@@ -33,10 +33,28 @@ type KeptnSequenceSpec struct {
 type KeptnSequenceStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// ObservedGeneration is the generation of the spec which has been reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the reconciliation (Ready, Reconciling, Stalled)
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Stages contains the names of the KeptnStages referring to this sequence, stages in other namespaces are prefixed with their namespace
+	//+optional
+	Stages []string `json:"stages,omitempty"`
+	// Projects contains the projects whose shipyards have been updated with this sequence, prefixed with their namespace like Stages
+	//+optional
+	Projects []string `json:"projects,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KeptnSequence is the Schema for the keptnsequences API
 type KeptnSequence struct {
@@ -58,6 +76,26 @@ type KeptnSequenceList struct {
 	Items           []KeptnSequence `json:"items"`
 }
 
+// GetConditions returns the conditions of the KeptnSequence
+func (in *KeptnSequence) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions of the KeptnSequence
+func (in *KeptnSequence) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// GetObservedGeneration returns the generation which has been reconciled
+func (in *KeptnSequence) GetObservedGeneration() int64 {
+	return in.Status.ObservedGeneration
+}
+
+// SetObservedGeneration sets the generation which has been reconciled
+func (in *KeptnSequence) SetObservedGeneration(generation int64) {
+	in.Status.ObservedGeneration = generation
+}
+
 func init() {
 	SchemeBuilder.Register(&KeptnSequence{}, &KeptnSequenceList{})
 }
@@ -71,9 +109,9 @@ type Sequence struct {
 
 // Task defines a task by its name and optional properties
 type Task struct {
-	Name           string            `json:"name" yaml:"name"`
-	TriggeredAfter string            `json:"triggeredAfter,omitempty" yaml:"triggeredAfter,omitempty"`
-	Properties     map[string]string `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Name           string          `json:"name" yaml:"name"`
+	TriggeredAfter string          `json:"triggeredAfter,omitempty" yaml:"triggeredAfter,omitempty"`
+	Properties     *TaskProperties `json:"properties,omitempty" yaml:"properties,omitempty"`
 }
 
 // Trigger defines a trigger which causes a sequence to get activated
@@ -45,11 +45,21 @@ type KeptnSequenceExecutionStatus struct {
 	KeptnContext    string `json:"keptnContext,omitempty"`
 	LastAppliedHash string `json:"lastAppliedHash,omitempty"`
 	UpdatePending   bool   `json:"updatePending,omitempty"`
+	// ObservedGeneration is the generation of the spec which has been reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the reconciliation (Ready, Reconciling, Stalled)
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:resource:shortName=kse
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KeptnSequenceExecution is the Schema for the keptnsequenceexecutions API
 type KeptnSequenceExecution struct {
@@ -69,6 +79,26 @@ type KeptnSequenceExecutionList struct {
 	Items           []KeptnSequenceExecution `json:"items"`
 }
 
+// GetConditions returns the conditions of the KeptnSequenceExecution
+func (in *KeptnSequenceExecution) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions of the KeptnSequenceExecution
+func (in *KeptnSequenceExecution) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// GetObservedGeneration returns the generation which has been reconciled
+func (in *KeptnSequenceExecution) GetObservedGeneration() int64 {
+	return in.Status.ObservedGeneration
+}
+
+// SetObservedGeneration sets the generation which has been reconciled
+func (in *KeptnSequenceExecution) SetObservedGeneration(generation int64) {
+	in.Status.ObservedGeneration = generation
+}
+
 func init() {
 	SchemeBuilder.Register(&KeptnSequenceExecution{}, &KeptnSequenceExecutionList{})
 }
@@ -34,6 +34,18 @@ type KeptnServiceSpec struct {
 	Version         string `json:"version,omitempty"`
 	Stage           string `json:"stage,omitempty"`
 	DeploymentEvent string `json:"deploymentEvent,omitempty"`
+	// DeletionPolicy defines if the service is deleted in Keptn when the KeptnService is deleted, defaults to Delete
+	//+kubebuilder:default=Delete
+	//+optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// GetDeletionPolicy returns the deletion policy of the service, Delete is used if none is set
+func (s KeptnServiceSpec) GetDeletionPolicy() DeletionPolicy {
+	if s.DeletionPolicy == "" {
+		return DeletionPolicyDelete
+	}
+	return s.DeletionPolicy
 }
 
 // KeptnServiceStatus defines the observed state of KeptnService
@@ -41,10 +53,22 @@ type KeptnServiceStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 	ProjectExists bool `json:"projectExists,omitempty"`
+	// Instance is the KeptnInstance the service has been created in, the service is only deleted in this instance
+	Instance *KeptnInstanceReference `json:"instance,omitempty"`
+	// ObservedGeneration is the generation of the spec which has been reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the reconciliation (Ready, Reconciling, Stalled)
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KeptnService is the Schema for the keptnservices API
 type KeptnService struct {
@@ -64,6 +88,26 @@ type KeptnServiceList struct {
 	Items           []KeptnService `json:"items"`
 }
 
+// GetConditions returns the conditions of the KeptnService
+func (in *KeptnService) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions of the KeptnService
+func (in *KeptnService) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// GetObservedGeneration returns the generation which has been reconciled
+func (in *KeptnService) GetObservedGeneration() int64 {
+	return in.Status.ObservedGeneration
+}
+
+// SetObservedGeneration sets the generation which has been reconciled
+func (in *KeptnService) SetObservedGeneration(generation int64) {
+	in.Status.ObservedGeneration = generation
+}
+
 func init() {
 	SchemeBuilder.Register(&KeptnService{}, &KeptnServiceList{})
 }
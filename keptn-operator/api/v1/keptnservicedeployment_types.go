@@ -46,6 +46,15 @@ type KeptnServiceDeploymentStatus struct {
 	LastAppliedHash       string                              `json:"lastAppliedHash,omitempty"`
 	Prerequisites         KeptnServiceDeploymentPrerequisites `json:"prerequisites,omitempty"`
 	DeploymentProgress    KeptnServiceDeploymentProgress      `json:"progress,omitempty"`
+	// TriggeredEventID is the id of the event which triggered the current sequence, earlier events of the Keptn context are ignored
+	TriggeredEventID string `json:"triggeredEventID,omitempty"`
+	// ObservedGeneration is the generation of the spec which has been reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the reconciliation (Ready, Reconciling, Stalled)
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //KeptnServiceDeploymentPrerequisites defines all of the objects needed to deploy a service
@@ -59,10 +68,45 @@ type KeptnServiceDeploymentPrerequisites struct {
 type KeptnServiceDeploymentProgress struct {
 	ArtifactAvailable   bool `json:"artifactAvailable,omitempty"`
 	DeploymentTriggered bool `json:"deploymentTriggered,omitempty"`
+	// SequenceState is the state of the triggered sequence in the stage (triggered, started, finished)
+	SequenceState string `json:"sequenceState,omitempty"`
+	// Result is the result of the finished sequence (pass, warning, fail)
+	Result string `json:"result,omitempty"`
+	// EvaluationScore is the score of the last evaluation in the stage
+	EvaluationScore string `json:"evaluationScore,omitempty"`
+	// SLO references the KeptnSLO the last evaluation in the stage has been based on
+	SLO *KeptnSLOReference `json:"slo,omitempty"`
+	// Tasks describes the progress of the tasks of the sequence in the order they have been triggered
+	Tasks []KeptnTaskProgress `json:"tasks,omitempty"`
+}
+
+//KeptnSLOReference references the KeptnSLO used in an evaluation
+type KeptnSLOReference struct {
+	// Name is the name of the KeptnSLO
+	Name string `json:"name"`
+	// Generation is the generation of the KeptnSLO which had been uploaded when the evaluation finished
+	Generation int64 `json:"generation,omitempty"`
+}
+
+//KeptnTaskProgress describes the state of a task of a triggered sequence
+type KeptnTaskProgress struct {
+	// Name is the name of the task
+	Name string `json:"name"`
+	// State is the state of the task (triggered, started, finished)
+	State string `json:"state"`
+	// Result is the result of the finished task (pass, warning, fail)
+	Result string `json:"result,omitempty"`
+	// Status is the status of the finished task (succeeded, errored)
+	Status string `json:"status,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Deployed",type="string",JSONPath=".status.deployedVersion"
+//+kubebuilder:printcolumn:name="Result",type="string",JSONPath=".status.progress.result"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KeptnServiceDeployment is the Schema for the keptnservicedeployments API
 type KeptnServiceDeployment struct {
@@ -82,6 +126,26 @@ type KeptnServiceDeploymentList struct {
 	Items           []KeptnServiceDeployment `json:"items"`
 }
 
+// GetConditions returns the conditions of the KeptnServiceDeployment
+func (in *KeptnServiceDeployment) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions of the KeptnServiceDeployment
+func (in *KeptnServiceDeployment) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// GetObservedGeneration returns the generation which has been reconciled
+func (in *KeptnServiceDeployment) GetObservedGeneration() int64 {
+	return in.Status.ObservedGeneration
+}
+
+// SetObservedGeneration sets the generation which has been reconciled
+func (in *KeptnServiceDeployment) SetObservedGeneration(generation int64) {
+	in.Status.ObservedGeneration = generation
+}
+
 func init() {
 	SchemeBuilder.Register(&KeptnServiceDeployment{}, &KeptnServiceDeploymentList{})
 }
@@ -0,0 +1,113 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KeptnServiceResourceSpec defines the desired state of KeptnServiceResource
+type KeptnServiceResourceSpec struct {
+	// Project is the Keptn project of the service
+	Project string `json:"project"`
+	// Service is the Keptn service the resource belongs to
+	Service string `json:"service"`
+	// Stage is the stage the resource is added to, the resource is added to all stages of the project if it is not set
+	//+optional
+	Stage string `json:"stage,omitempty"`
+	// ResourceURI is the path of the resource in the service directory, e.g. slo.yaml or jmeter/load.jmx
+	ResourceURI string `json:"resourceURI"`
+	// Content is the content of the resource, takes precedence over ContentRef
+	//+optional
+	Content string `json:"content,omitempty"`
+	// ContentRef references a ConfigMap key containing the content of the resource
+	//+optional
+	ContentRef *ConfigMapKeyReference `json:"contentRef,omitempty"`
+}
+
+// KeptnServiceResourceStatus defines the observed state of KeptnServiceResource
+type KeptnServiceResourceStatus struct {
+	// LastAppliedHash is the hash of the spec, content and stages which have been uploaded to Keptn
+	LastAppliedHash string `json:"lastAppliedHash,omitempty"`
+	// Instance is the KeptnInstance the resource has been uploaded to
+	Instance *KeptnInstanceReference `json:"instance,omitempty"`
+	// Project is the project the resource has been uploaded to
+	Project string `json:"project,omitempty"`
+	// Service is the service the resource has been uploaded to
+	Service string `json:"service,omitempty"`
+	// ResourceURI is the path the resource has been uploaded to
+	ResourceURI string `json:"resourceURI,omitempty"`
+	// Stages are the stages the resource has been uploaded to
+	Stages []string `json:"stages,omitempty"`
+	// ObservedGeneration is the generation of the spec which has been reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the reconciliation (Ready, Reconciling, Stalled)
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Service",type="string",JSONPath=".spec.service"
+//+kubebuilder:printcolumn:name="Resource",type="string",JSONPath=".spec.resourceURI"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KeptnServiceResource is the Schema for the keptnserviceresources API
+type KeptnServiceResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KeptnServiceResourceSpec   `json:"spec,omitempty"`
+	Status KeptnServiceResourceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KeptnServiceResourceList contains a list of KeptnServiceResource
+type KeptnServiceResourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KeptnServiceResource `json:"items"`
+}
+
+// GetConditions returns the conditions of the KeptnServiceResource
+func (in *KeptnServiceResource) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions of the KeptnServiceResource
+func (in *KeptnServiceResource) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// GetObservedGeneration returns the generation which has been reconciled
+func (in *KeptnServiceResource) GetObservedGeneration() int64 {
+	return in.Status.ObservedGeneration
+}
+
+// SetObservedGeneration sets the generation which has been reconciled
+func (in *KeptnServiceResource) SetObservedGeneration(generation int64) {
+	in.Status.ObservedGeneration = generation
+}
+
+func init() {
+	SchemeBuilder.Register(&KeptnServiceResource{}, &KeptnServiceResourceList{})
+}
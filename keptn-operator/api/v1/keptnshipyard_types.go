@@ -36,10 +36,24 @@ type KeptnShipyardStatus struct {
 	ProjectExists    bool   `json:"projectExists,omitempty"`
 	LastAppliedHash  string `json:"lastAppliedHash,omitempty"`
 	LastUploadedHash string `json:"LastUploadedHash,omitempty"`
+	// LastUpstreamCommit is the commit of the upstream repository whose shipyard.yaml has been pushed or checked for drift last
+	LastUpstreamCommit string `json:"lastUpstreamCommit,omitempty"`
+	// Drifted is true if the shipyard.yaml in the upstream repository differs from the KeptnShipyard
+	Drifted bool `json:"drifted,omitempty"`
+	// ObservedGeneration is the generation of the spec which has been reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the reconciliation (Ready, Reconciling, Stalled)
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KeptnShipyard is the Schema for the keptnshipyards API
 type KeptnShipyard struct {
@@ -80,6 +94,26 @@ type CreateProject struct {
 	Shipyard []byte `json:"shipyard"`
 }
 
+// GetConditions returns the conditions of the KeptnShipyard
+func (in *KeptnShipyard) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions of the KeptnShipyard
+func (in *KeptnShipyard) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// GetObservedGeneration returns the generation which has been reconciled
+func (in *KeptnShipyard) GetObservedGeneration() int64 {
+	return in.Status.ObservedGeneration
+}
+
+// SetObservedGeneration sets the generation which has been reconciled
+func (in *KeptnShipyard) SetObservedGeneration(generation int64) {
+	in.Status.ObservedGeneration = generation
+}
+
 func init() {
 	SchemeBuilder.Register(&KeptnShipyard{}, &KeptnShipyardList{})
 }
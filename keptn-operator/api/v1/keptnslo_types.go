@@ -0,0 +1,171 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KeptnSLOSpec defines the desired state of KeptnSLO
+type KeptnSLOSpec struct {
+	// Project is the Keptn project of the service
+	Project string `json:"project"`
+	// Service is the Keptn service which is evaluated with the SLO
+	Service string `json:"service"`
+	// Stage is the stage the SLO is used in, the SLO is used in all stages of the project if it is not set
+	//+optional
+	Stage string `json:"stage,omitempty"`
+	// Comparison defines which previous evaluations the SLIs are compared with
+	//+optional
+	Comparison SLOComparison `json:"comparison,omitempty"`
+	// Filter contains the filters passed to the SLI provider
+	//+optional
+	Filter map[string]string `json:"filter,omitempty"`
+	// Objectives are the SLIs which are evaluated
+	//+kubebuilder:validation:MinItems=1
+	Objectives []SLOObjective `json:"objectives"`
+	// TotalScore defines the scores needed for the evaluation to pass or to end with a warning
+	//+optional
+	TotalScore SLOTotalScore `json:"totalScore,omitempty"`
+}
+
+// SLOComparison defines how the SLIs are compared with previous evaluations
+type SLOComparison struct {
+	// AggregateFunction is used to aggregate the results of the previous evaluations, defaults to avg
+	//+kubebuilder:validation:Enum=avg;p50;p90;p95
+	//+optional
+	AggregateFunction string `json:"aggregateFunction,omitempty"`
+	// CompareWith defines if one or several previous results are used, defaults to single_result
+	//+kubebuilder:validation:Enum=single_result;several_results
+	//+optional
+	CompareWith string `json:"compareWith,omitempty"`
+	// IncludeResultWithScore defines which previous results are used, defaults to pass
+	//+kubebuilder:validation:Enum=all;pass;pass_or_warn
+	//+optional
+	IncludeResultWithScore string `json:"includeResultWithScore,omitempty"`
+	// NumberOfComparisonResults is the number of previous results used, defaults to 1
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	NumberOfComparisonResults int `json:"numberOfComparisonResults,omitempty"`
+}
+
+// SLOObjective defines the criteria of a single SLI
+type SLOObjective struct {
+	// SLI is the name of the SLI as returned by the SLI provider
+	SLI string `json:"sli"`
+	// DisplayName is shown instead of the name of the SLI
+	//+optional
+	DisplayName string `json:"displayName,omitempty"`
+	// Pass contains the criteria for the objective to pass, at least one of the groups has to be fulfilled.
+	// Objectives without pass criteria are only informative
+	//+optional
+	Pass []SLOCriteria `json:"pass,omitempty"`
+	// Warning contains the criteria for the objective to end with a warning
+	//+optional
+	Warning []SLOCriteria `json:"warning,omitempty"`
+	// Weight is the weight of the objective in the total score, defaults to 1
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	Weight int `json:"weight,omitempty"`
+	// KeySLI lets the whole evaluation fail if the objective fails
+	//+optional
+	KeySLI bool `json:"keySLI,omitempty"`
+}
+
+// SLOCriteria is a group of criteria which all have to be fulfilled, e.g. "<600" or "<=+10%"
+type SLOCriteria struct {
+	//+kubebuilder:validation:MinItems=1
+	Criteria []string `json:"criteria"`
+}
+
+// SLOTotalScore defines the percentage of the maximum score needed to pass or to end with a warning
+type SLOTotalScore struct {
+	// Pass is the score needed to pass, defaults to 90%
+	//+optional
+	Pass string `json:"pass,omitempty"`
+	// Warning is the score needed to end with a warning, defaults to 75%
+	//+optional
+	Warning string `json:"warning,omitempty"`
+}
+
+// KeptnSLOStatus defines the observed state of KeptnSLO
+type KeptnSLOStatus struct {
+	// ResourceName is the name of the KeptnServiceResource the rendered slo.yaml is uploaded with
+	ResourceName string `json:"resourceName,omitempty"`
+	// Stages are the stages the SLO has been uploaded to
+	Stages []string `json:"stages,omitempty"`
+	// UploadedGeneration is the generation of the spec whose slo.yaml has been uploaded to all stages
+	UploadedGeneration int64 `json:"uploadedGeneration,omitempty"`
+	// ObservedGeneration is the generation of the spec which has been reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the reconciliation (Ready, Reconciling, Stalled)
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:path=keptnslos
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Service",type="string",JSONPath=".spec.service"
+//+kubebuilder:printcolumn:name="Stage",type="string",JSONPath=".spec.stage"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KeptnSLO is the Schema for the keptnslos API
+type KeptnSLO struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KeptnSLOSpec   `json:"spec,omitempty"`
+	Status KeptnSLOStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KeptnSLOList contains a list of KeptnSLO
+type KeptnSLOList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KeptnSLO `json:"items"`
+}
+
+// GetConditions returns the conditions of the KeptnSLO
+func (in *KeptnSLO) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions of the KeptnSLO
+func (in *KeptnSLO) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// GetObservedGeneration returns the generation which has been reconciled
+func (in *KeptnSLO) GetObservedGeneration() int64 {
+	return in.Status.ObservedGeneration
+}
+
+// SetObservedGeneration sets the generation which has been reconciled
+func (in *KeptnSLO) SetObservedGeneration(generation int64) {
+	in.Status.ObservedGeneration = generation
+}
+
+func init() {
+	SchemeBuilder.Register(&KeptnSLO{}, &KeptnSLOList{})
+}
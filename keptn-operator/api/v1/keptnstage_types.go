@@ -33,24 +33,73 @@ type KeptnStageSpec struct {
 
 	// Sequence defines an array of sequences this KeptnStage will use
 	Sequence []KeptnSequenceRefSpec `json:"sequence"`
+
+	// After is the name of the stage this stage follows in the shipyard (e.g. production after hardening),
+	// stages without predecessor are ordered by their name
+	//+optional
+	After string `json:"after,omitempty"`
+
+	// Prune deletes the branch of the stage in the upstream repository and the stage in Keptn when the KeptnStage is deleted,
+	// otherwise the stage is only removed from the shipyard
+	//+optional
+	Prune bool `json:"prune,omitempty"`
 }
 
 // KeptnStageStatus defines the observed state of KeptnStage
 type KeptnStageStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Instance is the KeptnInstance of the project of the stage, the stage is only pruned in this instance
+	Instance *KeptnInstanceReference `json:"instance,omitempty"`
+	// ObservedGeneration is the generation of the spec which has been reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the reconciliation (Ready, Reconciling, Stalled)
+	//+listType=map
+	//+listMapKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+const (
+	// SequenceRefTypeReference refers to a KeptnSequence by its name
+	SequenceRefTypeReference = "sequenceref"
+	// SequenceRefTypeInline defines the sequence directly in the KeptnStage
+	SequenceRefTypeInline = "inline"
+)
+
 // KeptnSequenceRefSpec defines a KeptnSequence which is used in this stage
 type KeptnSequenceRefSpec struct {
-	// Type describes how the sequence is defined in this KeptnSequenceRefSpec
+	// Type describes how the sequence is defined in this KeptnSequenceRefSpec, either sequenceref or inline
+	//+kubebuilder:validation:Enum=sequenceref;inline
 	Type string `json:"type"`
 	// SequenceRef is used to set a reference to a KeptnSequence
 	SequenceRef string `json:"sequenceRef,omitempty"`
+	// Namespace of the referenced KeptnSequence, defaults to the namespace of the KeptnStage. Sequences in other namespaces
+	// have to allow the namespace of the stage in their keptn.sh/allowed-namespaces annotation
+	//+optional
+	Namespace string `json:"namespace,omitempty"`
+	// Sequence is the definition of an inline sequence
+	//+optional
+	Sequence *Sequence `json:"sequence,omitempty"`
+	// Parameters are substituted for ${name} placeholders in the sequence, in addition to ${stage}, ${previousStage} and ${project}
+	//+optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// GetSequenceNamespace returns the namespace of the referenced KeptnSequence, stageNamespace is used if no namespace is set
+func (in KeptnSequenceRefSpec) GetSequenceNamespace(stageNamespace string) string {
+	if in.Namespace == "" {
+		return stageNamespace
+	}
+	return in.Namespace
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KeptnStage is the Schema for the keptnstages API
 type KeptnStage struct {
@@ -70,6 +119,26 @@ type KeptnStageList struct {
 	Items           []KeptnStage `json:"items"`
 }
 
+// GetConditions returns the conditions of the KeptnStage
+func (in *KeptnStage) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions of the KeptnStage
+func (in *KeptnStage) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// GetObservedGeneration returns the generation which has been reconciled
+func (in *KeptnStage) GetObservedGeneration() int64 {
+	return in.Status.ObservedGeneration
+}
+
+// SetObservedGeneration sets the generation which has been reconciled
+func (in *KeptnStage) SetObservedGeneration(generation int64) {
+	in.Status.ObservedGeneration = generation
+}
+
 func init() {
 	SchemeBuilder.Register(&KeptnStage{}, &KeptnStageList{})
 }
@@ -0,0 +1,89 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+)
+
+// TaskProperties contains the properties of a task, which can be any object (e.g. nested lists of test strategies or numeric timeouts)
+//+kubebuilder:validation:Type=object
+//+kubebuilder:pruning:PreserveUnknownFields
+type TaskProperties struct {
+	// Raw contains the properties encoded as JSON
+	Raw []byte `json:"-"`
+}
+
+// NewTaskProperties returns the TaskProperties containing the given properties
+func NewTaskProperties(properties map[string]interface{}) (*TaskProperties, error) {
+	raw, err := json.Marshal(properties)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal task properties: %w", err)
+	}
+	return &TaskProperties{Raw: raw}, nil
+}
+
+// Map returns the decoded properties
+func (in *TaskProperties) Map() (map[string]interface{}, error) {
+	properties := map[string]interface{}{}
+	if in == nil || len(in.Raw) == 0 {
+		return properties, nil
+	}
+	if err := json.Unmarshal(in.Raw, &properties); err != nil {
+		return nil, fmt.Errorf("could not unmarshal task properties: %w", err)
+	}
+	return properties, nil
+}
+
+// MarshalJSON returns the raw properties
+func (in TaskProperties) MarshalJSON() ([]byte, error) {
+	if len(in.Raw) == 0 {
+		return []byte("null"), nil
+	}
+	return in.Raw, nil
+}
+
+// UnmarshalJSON keeps the properties as they are
+func (in *TaskProperties) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		in.Raw = nil
+		return nil
+	}
+	in.Raw = append(in.Raw[:0], data...)
+	return nil
+}
+
+// MarshalYAML returns the decoded properties, so that they are written as nested YAML object into the shipyard
+func (in TaskProperties) MarshalYAML() (interface{}, error) {
+	return in.Map()
+}
+
+// UnmarshalYAML reads the properties from a YAML object
+func (in *TaskProperties) UnmarshalYAML(value *yaml.Node) error {
+	properties := map[string]interface{}{}
+	if err := value.Decode(&properties); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(properties)
+	if err != nil {
+		return fmt.Errorf("could not marshal task properties: %w", err)
+	}
+	in.Raw = raw
+	return nil
+}
@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+func TestTaskPropertiesRoundTrip(t *testing.T) {
+	manifest := `{"name": "delivery", "tasks": [
+		{"name": "deployment", "properties": {"deploymentstrategy": "user_managed"}},
+		{"name": "test", "properties": {"teststrategy": ["functional", "performance"], "timeout": 300, "webhook": {"url": "https://example.com", "retries": 3}}},
+		{"name": "release"}
+	]}`
+
+	sequence := Sequence{}
+	require.NoError(t, json.Unmarshal([]byte(manifest), &sequence))
+	require.Nil(t, sequence.Tasks[2].Properties)
+
+	shipyard, err := yaml.Marshal(sequence)
+	require.NoError(t, err)
+	require.Equal(t, `name: delivery
+tasks:
+    - name: deployment
+      properties:
+        deploymentstrategy: user_managed
+    - name: test
+      properties:
+        teststrategy:
+            - functional
+            - performance
+        timeout: 300
+        webhook:
+            retries: 3
+            url: https://example.com
+    - name: release
+`, string(shipyard))
+
+	parsed := Sequence{}
+	require.NoError(t, yaml.Unmarshal(shipyard, &parsed))
+	for i, task := range sequence.Tasks {
+		if task.Properties == nil {
+			require.Nil(t, parsed.Tasks[i].Properties)
+			continue
+		}
+		require.JSONEq(t, string(task.Properties.Raw), string(parsed.Tasks[i].Properties.Raw))
+	}
+}
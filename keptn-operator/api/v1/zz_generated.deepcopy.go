@@ -22,9 +22,25 @@ limitations under the License.
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapKeyReference) DeepCopyInto(out *ConfigMapKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeyReference.
+func (in *ConfigMapKeyReference) DeepCopy() *ConfigMapKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CreateProject) DeepCopyInto(out *CreateProject) {
 	*out = *in
@@ -50,6 +66,26 @@ func (in *CreateProject) DeepCopy() *CreateProject {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitSSHCredentials) DeepCopyInto(out *GitSSHCredentials) {
+	*out = *in
+	if in.PrivateKeySecretRef != nil {
+		in, out := &in.PrivateKeySecretRef, &out.PrivateKeySecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitSSHCredentials.
+func (in *GitSSHCredentials) DeepCopy() *GitSSHCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(GitSSHCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnDeploymentContext) DeepCopyInto(out *KeptnDeploymentContext) {
 	*out = *in
@@ -134,6 +170,13 @@ func (in *KeptnDeploymentContextStatus) DeepCopyInto(out *KeptnDeploymentContext
 			(*out)[key] = val
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnDeploymentContextStatus.
@@ -151,7 +194,7 @@ func (in *KeptnInstance) DeepCopyInto(out *KeptnInstance) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -205,9 +248,29 @@ func (in *KeptnInstanceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeptnInstanceReference) DeepCopyInto(out *KeptnInstanceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnInstanceReference.
+func (in *KeptnInstanceReference) DeepCopy() *KeptnInstanceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(KeptnInstanceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnInstanceSpec) DeepCopyInto(out *KeptnInstanceSpec) {
 	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnInstanceSpec.
@@ -224,6 +287,13 @@ func (in *KeptnInstanceSpec) DeepCopy() *KeptnInstanceSpec {
 func (in *KeptnInstanceStatus) DeepCopyInto(out *KeptnInstanceStatus) {
 	*out = *in
 	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnInstanceStatus.
@@ -241,8 +311,8 @@ func (in *KeptnProject) DeepCopyInto(out *KeptnProject) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnProject.
@@ -298,6 +368,26 @@ func (in *KeptnProjectList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnProjectSpec) DeepCopyInto(out *KeptnProjectSpec) {
 	*out = *in
+	if in.InitialShipyardRef != nil {
+		in, out := &in.InitialShipyardRef, &out.InitialShipyardRef
+		*out = new(ConfigMapKeyReference)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.SSH != nil {
+		in, out := &in.SSH, &out.SSH
+		*out = new(GitSSHCredentials)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstanceRef != nil {
+		in, out := &in.InstanceRef, &out.InstanceRef
+		*out = new(KeptnInstanceReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnProjectSpec.
@@ -313,6 +403,13 @@ func (in *KeptnProjectSpec) DeepCopy() *KeptnProjectSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnProjectStatus) DeepCopyInto(out *KeptnProjectStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnProjectStatus.
@@ -325,13 +422,145 @@ func (in *KeptnProjectStatus) DeepCopy() *KeptnProjectStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeptnSLO) DeepCopyInto(out *KeptnSLO) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnSLO.
+func (in *KeptnSLO) DeepCopy() *KeptnSLO {
+	if in == nil {
+		return nil
+	}
+	out := new(KeptnSLO)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KeptnSLO) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeptnSLOList) DeepCopyInto(out *KeptnSLOList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KeptnSLO, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnSLOList.
+func (in *KeptnSLOList) DeepCopy() *KeptnSLOList {
+	if in == nil {
+		return nil
+	}
+	out := new(KeptnSLOList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KeptnSLOList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeptnSLOReference) DeepCopyInto(out *KeptnSLOReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnSLOReference.
+func (in *KeptnSLOReference) DeepCopy() *KeptnSLOReference {
+	if in == nil {
+		return nil
+	}
+	out := new(KeptnSLOReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeptnSLOSpec) DeepCopyInto(out *KeptnSLOSpec) {
+	*out = *in
+	out.Comparison = in.Comparison
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Objectives != nil {
+		in, out := &in.Objectives, &out.Objectives
+		*out = make([]SLOObjective, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.TotalScore = in.TotalScore
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnSLOSpec.
+func (in *KeptnSLOSpec) DeepCopy() *KeptnSLOSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeptnSLOSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeptnSLOStatus) DeepCopyInto(out *KeptnSLOStatus) {
+	*out = *in
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnSLOStatus.
+func (in *KeptnSLOStatus) DeepCopy() *KeptnSLOStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KeptnSLOStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnScheduledExec) DeepCopyInto(out *KeptnScheduledExec) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnScheduledExec.
@@ -403,6 +632,13 @@ func (in *KeptnScheduledExecSpec) DeepCopy() *KeptnScheduledExecSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnScheduledExecStatus) DeepCopyInto(out *KeptnScheduledExecStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnScheduledExecStatus.
@@ -421,7 +657,7 @@ func (in *KeptnSequence) DeepCopyInto(out *KeptnSequence) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnSequence.
@@ -448,7 +684,7 @@ func (in *KeptnSequenceExecution) DeepCopyInto(out *KeptnSequenceExecution) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnSequenceExecution.
@@ -526,6 +762,13 @@ func (in *KeptnSequenceExecutionSpec) DeepCopy() *KeptnSequenceExecutionSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnSequenceExecutionStatus) DeepCopyInto(out *KeptnSequenceExecutionStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnSequenceExecutionStatus.
@@ -573,6 +816,18 @@ func (in *KeptnSequenceList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnSequenceRefSpec) DeepCopyInto(out *KeptnSequenceRefSpec) {
 	*out = *in
+	if in.Sequence != nil {
+		in, out := &in.Sequence, &out.Sequence
+		*out = new(Sequence)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnSequenceRefSpec.
@@ -604,6 +859,23 @@ func (in *KeptnSequenceSpec) DeepCopy() *KeptnSequenceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnSequenceStatus) DeepCopyInto(out *KeptnSequenceStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Projects != nil {
+		in, out := &in.Projects, &out.Projects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnSequenceStatus.
@@ -622,7 +894,7 @@ func (in *KeptnService) DeepCopyInto(out *KeptnService) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnService.
@@ -649,7 +921,7 @@ func (in *KeptnServiceDeployment) DeepCopyInto(out *KeptnServiceDeployment) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnServiceDeployment.
@@ -720,6 +992,16 @@ func (in *KeptnServiceDeploymentPrerequisites) DeepCopy() *KeptnServiceDeploymen
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnServiceDeploymentProgress) DeepCopyInto(out *KeptnServiceDeploymentProgress) {
 	*out = *in
+	if in.SLO != nil {
+		in, out := &in.SLO, &out.SLO
+		*out = new(KeptnSLOReference)
+		**out = **in
+	}
+	if in.Tasks != nil {
+		in, out := &in.Tasks, &out.Tasks
+		*out = make([]KeptnTaskProgress, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnServiceDeploymentProgress.
@@ -758,7 +1040,14 @@ func (in *KeptnServiceDeploymentSpec) DeepCopy() *KeptnServiceDeploymentSpec {
 func (in *KeptnServiceDeploymentStatus) DeepCopyInto(out *KeptnServiceDeploymentStatus) {
 	*out = *in
 	out.Prerequisites = in.Prerequisites
-	out.DeploymentProgress = in.DeploymentProgress
+	in.DeploymentProgress.DeepCopyInto(&out.DeploymentProgress)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnServiceDeploymentStatus.
@@ -803,6 +1092,117 @@ func (in *KeptnServiceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeptnServiceResource) DeepCopyInto(out *KeptnServiceResource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnServiceResource.
+func (in *KeptnServiceResource) DeepCopy() *KeptnServiceResource {
+	if in == nil {
+		return nil
+	}
+	out := new(KeptnServiceResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KeptnServiceResource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeptnServiceResourceList) DeepCopyInto(out *KeptnServiceResourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KeptnServiceResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnServiceResourceList.
+func (in *KeptnServiceResourceList) DeepCopy() *KeptnServiceResourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(KeptnServiceResourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KeptnServiceResourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeptnServiceResourceSpec) DeepCopyInto(out *KeptnServiceResourceSpec) {
+	*out = *in
+	if in.ContentRef != nil {
+		in, out := &in.ContentRef, &out.ContentRef
+		*out = new(ConfigMapKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnServiceResourceSpec.
+func (in *KeptnServiceResourceSpec) DeepCopy() *KeptnServiceResourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeptnServiceResourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeptnServiceResourceStatus) DeepCopyInto(out *KeptnServiceResourceStatus) {
+	*out = *in
+	if in.Instance != nil {
+		in, out := &in.Instance, &out.Instance
+		*out = new(KeptnInstanceReference)
+		**out = **in
+	}
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnServiceResourceStatus.
+func (in *KeptnServiceResourceStatus) DeepCopy() *KeptnServiceResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KeptnServiceResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnServiceSpec) DeepCopyInto(out *KeptnServiceSpec) {
 	*out = *in
@@ -821,6 +1221,18 @@ func (in *KeptnServiceSpec) DeepCopy() *KeptnServiceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnServiceStatus) DeepCopyInto(out *KeptnServiceStatus) {
 	*out = *in
+	if in.Instance != nil {
+		in, out := &in.Instance, &out.Instance
+		*out = new(KeptnInstanceReference)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnServiceStatus.
@@ -839,7 +1251,7 @@ func (in *KeptnShipyard) DeepCopyInto(out *KeptnShipyard) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnShipyard.
@@ -911,6 +1323,13 @@ func (in *KeptnShipyardSpec) DeepCopy() *KeptnShipyardSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnShipyardStatus) DeepCopyInto(out *KeptnShipyardStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnShipyardStatus.
@@ -929,7 +1348,7 @@ func (in *KeptnStage) DeepCopyInto(out *KeptnStage) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnStage.
@@ -988,7 +1407,9 @@ func (in *KeptnStageSpec) DeepCopyInto(out *KeptnStageSpec) {
 	if in.Sequence != nil {
 		in, out := &in.Sequence, &out.Sequence
 		*out = make([]KeptnSequenceRefSpec, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -1005,6 +1426,18 @@ func (in *KeptnStageSpec) DeepCopy() *KeptnStageSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeptnStageStatus) DeepCopyInto(out *KeptnStageStatus) {
 	*out = *in
+	if in.Instance != nil {
+		in, out := &in.Instance, &out.Instance
+		*out = new(KeptnInstanceReference)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnStageStatus.
@@ -1017,6 +1450,21 @@ func (in *KeptnStageStatus) DeepCopy() *KeptnStageStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeptnTaskProgress) DeepCopyInto(out *KeptnTaskProgress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeptnTaskProgress.
+func (in *KeptnTaskProgress) DeepCopy() *KeptnTaskProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(KeptnTaskProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Metadata) DeepCopyInto(out *Metadata) {
 	*out = *in
@@ -1032,6 +1480,100 @@ func (in *Metadata) DeepCopy() *Metadata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLOComparison) DeepCopyInto(out *SLOComparison) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLOComparison.
+func (in *SLOComparison) DeepCopy() *SLOComparison {
+	if in == nil {
+		return nil
+	}
+	out := new(SLOComparison)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLOCriteria) DeepCopyInto(out *SLOCriteria) {
+	*out = *in
+	if in.Criteria != nil {
+		in, out := &in.Criteria, &out.Criteria
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLOCriteria.
+func (in *SLOCriteria) DeepCopy() *SLOCriteria {
+	if in == nil {
+		return nil
+	}
+	out := new(SLOCriteria)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLOObjective) DeepCopyInto(out *SLOObjective) {
+	*out = *in
+	if in.Pass != nil {
+		in, out := &in.Pass, &out.Pass
+		*out = make([]SLOCriteria, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Warning != nil {
+		in, out := &in.Warning, &out.Warning
+		*out = make([]SLOCriteria, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLOObjective.
+func (in *SLOObjective) DeepCopy() *SLOObjective {
+	if in == nil {
+		return nil
+	}
+	out := new(SLOObjective)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLOTotalScore) DeepCopyInto(out *SLOTotalScore) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLOTotalScore.
+func (in *SLOTotalScore) DeepCopy() *SLOTotalScore {
+	if in == nil {
+		return nil
+	}
+	out := new(SLOTotalScore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Selector) DeepCopyInto(out *Selector) {
 	*out = *in
@@ -1149,10 +1691,8 @@ func (in *Task) DeepCopyInto(out *Task) {
 	*out = *in
 	if in.Properties != nil {
 		in, out := &in.Properties, &out.Properties
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+		*out = new(TaskProperties)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -1166,6 +1706,26 @@ func (in *Task) DeepCopy() *Task {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskProperties) DeepCopyInto(out *TaskProperties) {
+	*out = *in
+	if in.Raw != nil {
+		in, out := &in.Raw, &out.Raw
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskProperties.
+func (in *TaskProperties) DeepCopy() *TaskProperties {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskProperties)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Trigger) DeepCopyInto(out *Trigger) {
 	*out = *in
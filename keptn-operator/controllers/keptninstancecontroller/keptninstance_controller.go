@@ -19,6 +19,8 @@ package keptninstancecontroller
 import (
 	"context"
 	"github.com/go-logr/logr"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/secrets"
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -81,7 +83,11 @@ func (r *KeptnInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	url, err := url.Parse(instance.Spec.APIUrl)
 	if err != nil {
-		panic(err)
+		r.ReqLogger.Error(err, "Could not parse the API URL of keptninstance "+instance.Name)
+		if err := conditions.Stalled(ctx, r.Client, instance, apiv1.ReasonInvalidSpec, "Could not parse apiUrl: "+err.Error()); err != nil {
+			return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
+		}
+		return ctrl.Result{}, nil
 	}
 	instance.Status.Scheme = url.Scheme
 
@@ -91,8 +97,11 @@ func (r *KeptnInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not get Keptn Token")
 		}
-		encToken, err := utils.EncryptPublicPEM(token)
+		encToken, err := secrets.EncryptPublicPEM(token)
 		if err != nil {
+			if err := conditions.Stalled(ctx, r.Client, instance, apiv1.ReasonFailed, "Could not encrypt Keptn Token: "+err.Error()); err != nil {
+				r.ReqLogger.Error(err, "Could not update status of keptninstance "+instance.Name)
+			}
 			return ctrl.Result{Requeue: true}, err
 		}
 		instance.Status.AuthHeader = "x-token"
@@ -100,6 +109,7 @@ func (r *KeptnInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 		if encToken != instance.Status.CurrentToken || instance.Status.LastUpdated.Add(refreshInterval).Before(time.Now()) {
 			instance.Status.LastUpdated = metav1.Time{Time: time.Now()}
+			conditions.MarkReady(instance, apiv1.ReasonReconciled, "Token has been updated")
 			err = r.Client.Status().Update(ctx, instance)
 			if err != nil {
 				r.ReqLogger.Error(err, "Could not update status of keptninstance "+instance.Name)
@@ -108,11 +118,14 @@ func (r *KeptnInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			return ctrl.Result{Requeue: true}, err
 		}
 	case "x-token":
+		// the status only holds a reference to the token, it is resolved when the instance is used
+		token := secrets.Reference(instance.Spec.SecretRef, instance.Spec.Token, instance.Namespace)
 		instance.Status.AuthHeader = "x-token"
-		instance.Status.CurrentToken = instance.Spec.Token
+		instance.Status.CurrentToken = token
 
-		if instance.Spec.Token != instance.Status.CurrentToken || instance.Status.LastUpdated.Add(refreshInterval).Before(time.Now()) {
+		if token != instance.Status.CurrentToken || instance.Status.LastUpdated.Add(refreshInterval).Before(time.Now()) {
 			instance.Status.LastUpdated = metav1.Time{Time: time.Now()}
+			conditions.MarkReady(instance, apiv1.ReasonReconciled, "Token has been updated")
 			err = r.Client.Status().Update(ctx, instance)
 			if err != nil {
 				r.ReqLogger.Error(err, "Could not update status of keptninstance "+instance.Name)
@@ -121,9 +134,17 @@ func (r *KeptnInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			return ctrl.Result{Requeue: true}, err
 		}
 
+		if err := conditions.Ready(ctx, r.Client, instance, apiv1.ReasonReconciled, "Token reference is up to date"); err != nil {
+			r.ReqLogger.Error(err, "Could not update status of keptninstance "+instance.Name)
+		}
 		return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
 	}
 
+	if err := conditions.Ready(ctx, r.Client, instance, apiv1.ReasonReconciled, "Token is up to date"); err != nil {
+		r.ReqLogger.Error(err, "Could not update status of keptninstance "+instance.Name)
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
+	}
+
 	r.ReqLogger.Info("Finished Reconciling KeptnInstance")
 	return ctrl.Result{RequeueAfter: reconcileSuccessInterval}, nil
 }
@@ -17,16 +17,20 @@ limitations under the License.
 package keptnprojectcontroller
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"encoding/base64"
 	"fmt"
 	"github.com/go-logr/logr"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/secrets"
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
-	nethttp "net/http"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"time"
 
@@ -50,6 +54,8 @@ type KeptnProjectReconciler struct {
 	KeptnInstance apiv1.KeptnInstance
 	// KeptnToken contains the API token used in this controller
 	KeptnToken string
+	// KeptnCache contains the cached projects and services of the Keptn instances
+	KeptnCache *keptnclient.Cache
 }
 
 const reconcileErrorInterval = 10 * time.Second
@@ -72,13 +78,6 @@ func (r *KeptnProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	r.ReqLogger = ctrl.Log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
 	r.ReqLogger.Info("Reconciling Project")
 
-	var err error
-	r.KeptnInstance, r.KeptnToken, err = utils.GetKeptnInstance(ctx, r.Client, req.Namespace)
-	if err != nil {
-		r.ReqLogger.Error(err, "Could not get Keptn Instance")
-		return r.finishReconcile(err, false)
-	}
-
 	keptnproject := &apiv1.KeptnProject{}
 
 	if err := r.Client.Get(ctx, req.NamespacedName, keptnproject); err != nil {
@@ -92,26 +91,46 @@ func (r *KeptnProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	myFinalizerName := "keptnprojects.keptn.sh/finalizer"
+	deletionPolicy := keptnproject.Spec.GetDeletionPolicy()
+	protected := apiv1.IsDeletionProtected(keptnproject)
 
 	// examine DeletionTimestamp to determine if object is under deletion
 	if keptnproject.ObjectMeta.DeletionTimestamp.IsZero() {
 		// The object is not being deleted, so if it does not have our finalizer,
 		// then lets add the finalizer and update the object. This is equivalent
-		// registering our finalizer.
-		if !utils.ContainsString(keptnproject.GetFinalizers(), myFinalizerName) {
+		// registering our finalizer. Orphaned projects don't need the finalizer unless they are protected.
+		needsFinalizer := deletionPolicy != apiv1.DeletionPolicyOrphan || protected
+		hasFinalizer := utils.ContainsString(keptnproject.GetFinalizers(), myFinalizerName)
+		if needsFinalizer && !hasFinalizer {
 			controllerutil.AddFinalizer(keptnproject, myFinalizerName)
 			if err := r.Update(ctx, keptnproject); err != nil {
 				return r.finishReconcile(err, false)
 			}
+		} else if !needsFinalizer && hasFinalizer {
+			controllerutil.RemoveFinalizer(keptnproject, myFinalizerName)
+			if err := r.Update(ctx, keptnproject); err != nil {
+				return r.finishReconcile(err, false)
+			}
 		}
 	} else {
 		// The object is being deleted
 		if utils.ContainsString(keptnproject.GetFinalizers(), myFinalizerName) {
-			// our finalizer is present, so lets handle any external dependency
-			if err := r.deleteKeptnProject(keptnproject); err != nil {
-				// if fail to delete the external dependency here, return with error
-				// so that it can be retried
-				return ctrl.Result{}, err
+			if protected {
+				r.Recorder.Event(keptnproject, "Warning", "DeletionProtected", fmt.Sprintf("Deletion of project %s is blocked by the %s annotation", keptnproject.Name, apiv1.DeletionProtectionAnnotation))
+				r.setStalled(ctx, keptnproject, apiv1.ReasonDeletionProtected, "Remove the annotation "+apiv1.DeletionProtectionAnnotation+" to delete the project")
+				// removing the annotation triggers a new reconciliation
+				return ctrl.Result{}, nil
+			}
+
+			if deletionPolicy == apiv1.DeletionPolicyDelete {
+				// our finalizer is present, so lets handle any external dependency
+				if err := r.deleteKeptnProject(ctx, keptnproject); err != nil {
+					// if fail to delete the external dependency here, return with error
+					// so that it can be retried
+					return ctrl.Result{}, err
+				}
+			} else {
+				r.Recorder.Event(keptnproject, "Normal", "KeptnProjectRetained", fmt.Sprintf("Keptn project %s has not been deleted in Keptn (deletionPolicy: %s)", keptnproject.Name, deletionPolicy))
 			}
 
 			// remove our finalizer from the list and update it.
@@ -122,15 +141,20 @@ func (r *KeptnProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 
 		// Stop reconciliation as the item is being deleted
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.getKeptnInstance(ctx, keptnproject); err != nil {
 		return r.finishReconcile(err, false)
 	}
 
-	projectExists, err := utils.CheckKeptnProjectExists(ctx, req, r.Client, keptnproject.Name)
+	projectExists, err := utils.CheckKeptnProjectExists(ctx, r.KeptnCache, r.KeptnInstance, r.KeptnToken, keptnproject.Name)
 	if !projectExists {
 		if keptnproject.Status.ProjectExists {
 			fmt.Println("Test 1")
 			r.Recorder.Event(keptnproject, "Warning", "KeptnProjectNotFound", fmt.Sprintf("Keptn project %s does not exist in Keptn", keptnproject.Name))
 			keptnproject.Status.ProjectExists = false
+			conditions.MarkReconciling(keptnproject, apiv1.ReasonProjectNotFound, "Keptn project does not exist in Keptn")
 			err := r.Status().Update(ctx, keptnproject)
 			if err != nil {
 				r.ReqLogger.Error(err, "Could not update status of project "+keptnproject.Name)
@@ -138,11 +162,29 @@ func (r *KeptnProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			}
 			return r.finishReconcile(nil, true)
 		}
-		err := r.createProject(keptnproject)
+		shipyard, err := r.initialShipyard(ctx, keptnproject)
+		if err != nil {
+			r.ReqLogger.Error(err, "Could not get initial shipyard")
+			r.setStalled(ctx, keptnproject, apiv1.ReasonInvalidSpec, err.Error())
+			return r.finishReconcile(err, false)
+		}
+		if shipyard == "" {
+			if err := conditions.Reconciling(ctx, r.Client, keptnproject, apiv1.ReasonShipyardNotFound, "Waiting for KeptnStages of the project to compose the initial shipyard"); err != nil {
+				r.ReqLogger.Error(err, "Could not update status of project "+keptnproject.Name)
+			}
+			return ctrl.Result{RequeueAfter: reconcileErrorInterval}, nil
+		}
+
+		err = r.createProject(ctx, keptnproject, shipyard)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not create project")
+			r.setStalled(ctx, keptnproject, apiv1.ReasonKeptnAPIError, err.Error())
 			return r.finishReconcile(err, false)
 		}
+		conditions.MarkReconciling(keptnproject, apiv1.ReasonProgressing, "Keptn project has been created")
+		if err := r.Status().Update(ctx, keptnproject); err != nil {
+			r.ReqLogger.Error(err, "Could not update status of project "+keptnproject.Name)
+		}
 		return ctrl.Result{RequeueAfter: reconcileErrorInterval}, nil
 	} else if !keptnproject.Status.ProjectExists {
 
@@ -156,9 +198,44 @@ func (r *KeptnProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return r.finishReconcile(err, true)
 	}
 
-	shipyard, err := utils.CreateShipyard(ctx, r.Client, keptnproject.Name)
+	upstream, err := r.upstreamRepository(ctx, keptnproject)
+	if err != nil {
+		r.ReqLogger.Error(err, "Could not resolve upstream credentials")
+		r.setStalled(ctx, keptnproject, apiv1.ReasonInvalidSpec, err.Error())
+		return r.finishReconcile(err, false)
+	}
+
+	upstreamHash, err := r.upstreamHash(ctx, keptnproject)
+	if err != nil {
+		r.ReqLogger.Error(err, "Could not determine version of upstream credentials")
+		r.setStalled(ctx, keptnproject, apiv1.ReasonInvalidSpec, err.Error())
+		return r.finishReconcile(err, false)
+	}
+
+	if upstreamHash != keptnproject.Status.LastAppliedHash {
+		if err := r.updateProject(ctx, upstream); err != nil {
+			r.Recorder.Event(keptnproject, "Warning", "KeptnProjectUpdateFailed", fmt.Sprintf("Could not update upstream repository of Keptn project %s: %v", keptnproject.Name, err))
+			r.setStalled(ctx, keptnproject, apiv1.ReasonKeptnAPIError, "Could not update upstream repository: "+err.Error())
+			return r.finishReconcile(err, false)
+		}
+		r.Recorder.Event(keptnproject, "Normal", "KeptnProjectUpdated", fmt.Sprintf("Updated upstream repository of Keptn project %s", keptnproject.Name))
+		keptnproject.Status.LastAppliedHash = upstreamHash
+		conditions.MarkReconciling(keptnproject, apiv1.ReasonProgressing, "Upstream repository and credentials have been updated in Keptn")
+		if err := r.Status().Update(ctx, keptnproject); err != nil {
+			r.ReqLogger.Error(err, "Could not update status of project "+keptnproject.Name)
+			return r.finishReconcile(err, false)
+		}
+		return r.finishReconcile(nil, true)
+	}
+
+	shipyard, err := utils.CreateShipyard(ctx, r.Client, keptnproject.Name, keptnproject.Namespace)
 	if err != nil {
 		r.ReqLogger.Error(err, "Could not create shipyard")
+		if utils.IsInvalidStageOrder(err) {
+			r.setStalled(ctx, keptnproject, apiv1.ReasonInvalidSpec, err.Error())
+			return r.finishReconcile(nil, false)
+		}
+		r.setStalled(ctx, keptnproject, apiv1.ReasonFailed, "Could not create shipyard: "+err.Error())
 		return r.finishReconcile(err, false)
 	}
 
@@ -176,6 +253,7 @@ func (r *KeptnProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		err = r.Client.Create(ctx, &shipyard)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not create shipyard")
+			r.setStalled(ctx, keptnproject, apiv1.ReasonFailed, "Could not create KeptnShipyard: "+err.Error())
 			return r.finishReconcile(err, false)
 		}
 		return r.finishReconcile(nil, true)
@@ -184,6 +262,12 @@ func (r *KeptnProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	err = utils.UpdateShipyard(ctx, r.Client, shipyard, shipyardHash, req.Namespace)
 	if err != nil {
 		r.ReqLogger.Error(err, "Could not update shipyard")
+		r.setStalled(ctx, keptnproject, apiv1.ReasonFailed, "Could not update shipyard: "+err.Error())
+		return r.finishReconcile(err, false)
+	}
+
+	if err := conditions.Ready(ctx, r.Client, keptnproject, apiv1.ReasonReconciled, "Keptn project exists and the shipyard is up to date"); err != nil {
+		r.ReqLogger.Error(err, "Could not update status of project "+keptnproject.Name)
 		return r.finishReconcile(err, false)
 	}
 
@@ -191,6 +275,12 @@ func (r *KeptnProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	return r.finishReconcile(nil, false)
 }
 
+func (r *KeptnProjectReconciler) setStalled(ctx context.Context, keptnproject *apiv1.KeptnProject, reason, message string) {
+	if err := conditions.Stalled(ctx, r.Client, keptnproject, reason, message); err != nil {
+		r.ReqLogger.Error(err, "Could not update status of project "+keptnproject.Name)
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *KeptnProjectReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -199,75 +289,131 @@ func (r *KeptnProjectReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-// Helper functions to check and remove string from a slice of strings.
-
-func (r *KeptnProjectReconciler) deleteKeptnProject(keptnproject *apiv1.KeptnProject) error {
-	httpclient := nethttp.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	request, err := nethttp.NewRequest("DELETE", r.KeptnInstance.Spec.APIUrl+"/controlPlane/v1/project/"+keptnproject.Name, bytes.NewBuffer(nil))
+//getKeptnInstance fetches the KeptnInstance the project is managed on
+func (r *KeptnProjectReconciler) getKeptnInstance(ctx context.Context, keptnproject *apiv1.KeptnProject) error {
+	var err error
+	r.KeptnInstance, r.KeptnToken, err = utils.GetKeptnInstanceByRef(ctx, r.Client, keptnproject.Spec.InstanceRef, keptnproject.Namespace)
 	if err != nil {
-		r.ReqLogger.Error(err, "Could not delete Project "+keptnproject.Name)
+		r.ReqLogger.Error(err, "Could not get Keptn Instance")
+		r.setStalled(ctx, keptnproject, apiv1.ReasonKeptnInstanceNotFound, err.Error())
 	}
+	return err
+}
 
-	request.Header.Set("content-type", "application/json")
-	request.Header.Set(r.KeptnInstance.Status.AuthHeader, r.KeptnToken)
+func (r *KeptnProjectReconciler) deleteKeptnProject(ctx context.Context, keptnproject *apiv1.KeptnProject) error {
+	// the Keptn instance is only needed if the project is deleted in Keptn, retained projects can be deleted without it
+	if err := r.getKeptnInstance(ctx, keptnproject); err != nil {
+		return err
+	}
 
 	r.ReqLogger.Info("Deleting Keptn Project " + keptnproject.Name)
-	_, err = httpclient.Do(request)
-	if err != nil {
-		return err
+	keptnClient := keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnToken)
+	err := keptnClient.DeleteProject(ctx, keptnproject.Name)
+	r.KeptnCache.Invalidate(keptnClient)
+	if err != nil && !keptnclient.IsNotFound(err) {
+		return fmt.Errorf("could not delete project %s: %w", keptnproject.Name, err)
 	}
-	return err
+	return nil
 }
 
-func (r *KeptnProjectReconciler) createProject(project *apiv1.KeptnProject) error {
-	var shipyard string
-	httpclient := nethttp.Client{
-		Timeout: 30 * time.Second,
+func (r *KeptnProjectReconciler) createProject(ctx context.Context, project *apiv1.KeptnProject, shipyard string) error {
+	upstream, err := r.upstreamRepository(ctx, project)
+	if err != nil {
+		r.ReqLogger.Error(err, "could not resolve secret")
+		return err
 	}
 
-	secret, err := utils.DecryptSecret(project.Spec.Password)
+	r.ReqLogger.Info("Creating Keptn Project " + project.Name)
+	keptnClient := keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnToken)
+	err = keptnClient.CreateProject(ctx, keptnclient.CreateProjectRequest{
+		Name:         upstream.Name,
+		Shipyard:     shipyard,
+		GitRemoteURL: upstream.GitRemoteURL,
+		GitUser:      upstream.GitUser,
+		GitToken:     upstream.GitToken,
+	})
+	r.KeptnCache.Invalidate(keptnClient)
 	if err != nil {
-		r.ReqLogger.Error(err, "could not decrypt secret")
-		return err
+		return fmt.Errorf("could not create project %v: %w", project.Name, err)
 	}
+	project.Status.LastAppliedHash, err = r.upstreamHash(ctx, project)
+	return err
+}
 
-	if project.Spec.InitialShipyard == "" {
-		shipyard = "YXBpVmVyc2lvbjogInNwZWMua2VwdG4uc2gvMC4yLjAiCmtpbmQ6ICJTaGlweWFyZCIKbWV0YWRhdGE6CiAgbmFtZTogInBvZHRhdG8taGVhZCIKc3BlYzoKICBzdGFnZXM6CiAgICAtIG5hbWU6ICJkZXYiCiAgICAgIHNlcXVlbmNlczoKICAgICAgICAtIG5hbWU6ICJkdW1teSIKICAgICAgICAgIHRhc2tzOgogICAgICAgICAgICAtIG5hbWU6ICJkdW1teSIKICAgIC0gbmFtZTogImhhcmRlbmluZyIKICAgICAgc2VxdWVuY2VzOgogICAgICAgIC0gbmFtZTogImR1bW15IgogICAgICAgICAgdGFza3M6CiAgICAgICAgICAgIC0gbmFtZTogImR1bW15IgogICAgLSBuYW1lOiAicHJvZHVjdGlvbiIKICAgICAgc2VxdWVuY2VzOgogICAgICAgIC0gbmFtZTogImR1bW15IgogICAgICAgICAgdGFza3M6CiAgICAgICAgICAgIC0gbmFtZTogImR1bW15IgoK"
-	} else {
-		shipyard = project.Spec.InitialShipyard
+//initialShipyard returns the base64 encoded shipyard the project is created with, an empty string is returned if the
+//shipyard should be composed of KeptnStages but none exist yet
+func (r *KeptnProjectReconciler) initialShipyard(ctx context.Context, project *apiv1.KeptnProject) (string, error) {
+	if project.Spec.InitialShipyard != "" {
+		return project.Spec.InitialShipyard, nil
 	}
 
-	data, _ := json.Marshal(map[string]string{
-		"gitRemoteURL": project.Spec.Repository,
-		"gitToken":     secret,
-		"gitUser":      project.Spec.Username,
-		"shipyard":     shipyard,
-		"name":         project.Name,
-	})
+	if ref := project.Spec.InitialShipyardRef; ref != nil {
+		configMap := &corev1.ConfigMap{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: project.Namespace}, configMap)
+		if err != nil {
+			return "", fmt.Errorf("could not get ConfigMap %s containing the initial shipyard: %w", ref.Name, err)
+		}
+		shipyard, ok := configMap.Data[ref.Key]
+		if !ok {
+			return "", fmt.Errorf("ConfigMap %s does not contain the key %s", ref.Name, ref.Key)
+		}
+		return base64.StdEncoding.EncodeToString([]byte(shipyard)), nil
+	}
 
-	request, err := nethttp.NewRequest("POST", r.KeptnInstance.Spec.APIUrl+"/controlPlane/v1/project", bytes.NewBuffer(data))
+	shipyard, err := utils.CreateShipyard(ctx, r.Client, project.Name, project.Namespace)
 	if err != nil {
-		r.ReqLogger.Error(err, "Could not create project "+project.Name)
-		return err
+		return "", fmt.Errorf("could not compose initial shipyard: %w", err)
+	}
+	if len(shipyard.Spec.Shipyard.Spec.Stages) == 0 {
+		return "", nil
 	}
+	shipyardYaml, err := yaml.Marshal(shipyard.Spec.Shipyard)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal initial shipyard: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(shipyardYaml), nil
+}
 
-	request.Header.Set("content-type", "application/json")
-	request.Header.Set(r.KeptnInstance.Status.AuthHeader, r.KeptnToken)
+//updateProject applies the upstream repository and credentials to an existing project in Keptn
+func (r *KeptnProjectReconciler) updateProject(ctx context.Context, upstream keptnclient.UpdateProjectRequest) error {
+	r.ReqLogger.Info("Updating upstream repository of Keptn Project " + upstream.Name)
+	err := keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnToken).UpdateProject(ctx, upstream)
+	if err != nil {
+		return fmt.Errorf("could not update project %v: %w", upstream.Name, err)
+	}
+	return nil
+}
 
-	r.ReqLogger.Info("Creating Keptn Project " + project.Name)
-	response, err := httpclient.Do(request)
+//upstreamRepository returns the upstream repository of a project with the resolved git token
+func (r *KeptnProjectReconciler) upstreamRepository(ctx context.Context, project *apiv1.KeptnProject) (keptnclient.UpdateProjectRequest, error) {
+	secret, err := secrets.ResolveInNamespace(ctx, secrets.Reference(project.Spec.SecretRef, project.Spec.Password, project.Namespace), project.Namespace)
 	if err != nil {
-		return err
+		return keptnclient.UpdateProjectRequest{}, fmt.Errorf("could not resolve git token of project %s: %w", project.Name, err)
 	}
+	return keptnclient.UpdateProjectRequest{
+		Name:         project.Name,
+		GitRemoteURL: project.Spec.Repository,
+		GitUser:      project.Spec.Username,
+		GitToken:     secret,
+	}, nil
+}
 
-	err = utils.CheckResponseCode(response, nethttp.StatusOK)
+//upstreamHash returns the hash of the upstream repository of a project. The git token is covered by the version of its
+//secret instead of its value, so rotating a referenced Secret is detected without storing a hash of the token
+func (r *KeptnProjectReconciler) upstreamHash(ctx context.Context, project *apiv1.KeptnProject) (string, error) {
+	tokenVersion, err := secrets.Version(ctx, secrets.Reference(project.Spec.SecretRef, project.Spec.Password, project.Namespace))
 	if err != nil {
-		return fmt.Errorf("could not create project %v: %v", project.Name, err)
+		return "", fmt.Errorf("could not determine version of git token of project %s: %w", project.Name, err)
 	}
-	return err
+	return utils.GetHashStructure(struct {
+		GitRemoteURL string
+		GitUser      string
+		TokenVersion string
+	}{
+		GitRemoteURL: project.Spec.Repository,
+		GitUser:      project.Spec.Username,
+		TokenVersion: tokenVersion,
+	}), nil
 }
 
 func (r *KeptnProjectReconciler) finishReconcile(err error, requeueImmediate bool) (ctrl.Result, error) {
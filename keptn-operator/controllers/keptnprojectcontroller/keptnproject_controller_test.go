@@ -0,0 +1,233 @@
+package keptnprojectcontroller
+
+import (
+	"context"
+	"encoding/base64"
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	keptnfake "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient/fake"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/secrets"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"strings"
+	"testing"
+)
+
+func TestReconcileUpdatesUpstream(t *testing.T) {
+	server := keptnfake.NewServer()
+	defer server.Close()
+	server.AddProject("podtato", "dev")
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+
+	spec := apiv1.KeptnProjectSpec{Repository: "https://git.example.com/moved", Username: "keptn", Password: "rotated"}
+	r := &KeptnProjectReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&apiv1.KeptnInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "keptn"},
+				Spec:       apiv1.KeptnInstanceSpec{APIUrl: server.URL},
+			},
+			&apiv1.KeptnProject{
+				ObjectMeta: metav1.ObjectMeta{Name: "podtato", Namespace: "keptn", Finalizers: []string{"keptnprojects.keptn.sh/finalizer"}},
+				Spec:       spec,
+				Status:     apiv1.KeptnProjectStatus{ProjectExists: true, LastAppliedHash: "outdated"},
+			},
+		).Build(),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(100),
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "podtato", Namespace: "keptn"}}
+
+	_, err := r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	project, ok := server.Project("podtato")
+	require.True(t, ok)
+	require.Equal(t, "https://git.example.com/moved", project.GitRemoteURL)
+	require.Equal(t, "keptn", project.GitUser)
+	require.Equal(t, "rotated", project.GitToken)
+
+	keptnproject := &apiv1.KeptnProject{}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, keptnproject))
+	require.NotEqual(t, "outdated", keptnproject.Status.LastAppliedHash)
+	require.Equal(t, "KeptnProjectUpdated", eventReason(t, r.Recorder.(*record.FakeRecorder)))
+
+	// an unchanged spec must not update the project again, the result of the shipyard handling does not matter here
+	_, _ = r.Reconcile(context.TODO(), req)
+	require.Equal(t, 1, countUpdates(server.Requests()))
+}
+
+func TestReconcileDetectsRotatedSecret(t *testing.T) {
+	server := keptnfake.NewServer()
+	defer server.Close()
+	server.AddProject("podtato", "dev")
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	r := &KeptnProjectReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&apiv1.KeptnInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "keptn"},
+				Spec:       apiv1.KeptnInstanceSpec{APIUrl: server.URL},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "git-credentials", Namespace: "keptn"},
+				Data:       map[string][]byte{"token": []byte("initial")},
+			},
+			&apiv1.KeptnProject{
+				ObjectMeta: metav1.ObjectMeta{Name: "podtato", Namespace: "keptn", Finalizers: []string{"keptnprojects.keptn.sh/finalizer"}},
+				Spec: apiv1.KeptnProjectSpec{
+					Repository: "https://git.example.com/podtato",
+					Username:   "keptn",
+					SecretRef:  &apiv1.SecretKeyReference{Name: "git-credentials", Key: "token"},
+				},
+				Status: apiv1.KeptnProjectStatus{ProjectExists: true},
+			},
+		).Build(),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(100),
+	}
+	secrets.Register(secrets.KubernetesPrefix, secrets.NewKubernetesResolver(r.Client))
+	defer secrets.Register(secrets.KubernetesPrefix, &secrets.KubernetesResolver{})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "podtato", Namespace: "keptn"}}
+
+	_, _ = r.Reconcile(context.TODO(), req)
+	require.Equal(t, 1, countUpdates(server.Requests()))
+
+	keptnproject := &apiv1.KeptnProject{}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, keptnproject))
+	appliedHash := keptnproject.Status.LastAppliedHash
+	require.NotEmpty(t, appliedHash)
+
+	// the hash does not depend on the value of the token, rotating the secret changes it nevertheless
+	secret := &corev1.Secret{}
+	require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "git-credentials", Namespace: "keptn"}, secret))
+	secret.Data["token"] = []byte("rotated")
+	require.NoError(t, r.Client.Update(context.TODO(), secret))
+
+	_, _ = r.Reconcile(context.TODO(), req)
+	require.Equal(t, 2, countUpdates(server.Requests()))
+	project, ok := server.Project("podtato")
+	require.True(t, ok)
+	require.Equal(t, "rotated", project.GitToken)
+
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, keptnproject))
+	require.NotEqual(t, appliedHash, keptnproject.Status.LastAppliedHash)
+}
+
+func TestInitialShipyard(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	r := &KeptnProjectReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "shipyards", Namespace: "keptn"},
+				Data:       map[string]string{"podtato": "kind: Shipyard\n"},
+			},
+			&apiv1.KeptnSequence{
+				ObjectMeta: metav1.ObjectMeta{Name: "delivery", Namespace: "keptn"},
+				Spec:       apiv1.KeptnSequenceSpec{Sequence: apiv1.Sequence{Name: "delivery", Tasks: []apiv1.Task{{Name: "deployment"}}}},
+			},
+			&apiv1.KeptnStage{
+				ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "keptn"},
+				Spec:       apiv1.KeptnStageSpec{Project: "composed", Sequence: []apiv1.KeptnSequenceRefSpec{{SequenceRef: "delivery"}}},
+			},
+		).Build(),
+		Scheme: scheme,
+	}
+
+	tests := []struct {
+		name         string
+		project      string
+		spec         apiv1.KeptnProjectSpec
+		wantShipyard string
+		wantContains string
+		wantErr      bool
+	}{
+		{
+			name:         "inline",
+			project:      "podtato",
+			spec:         apiv1.KeptnProjectSpec{InitialShipyard: "aW5saW5l", InitialShipyardRef: &apiv1.ConfigMapKeyReference{Name: "shipyards", Key: "podtato"}},
+			wantShipyard: "inline",
+		},
+		{
+			name:         "configmap",
+			project:      "podtato",
+			spec:         apiv1.KeptnProjectSpec{InitialShipyardRef: &apiv1.ConfigMapKeyReference{Name: "shipyards", Key: "podtato"}},
+			wantShipyard: "kind: Shipyard\n",
+		},
+		{
+			name:    "configmap_key_missing",
+			project: "podtato",
+			spec:    apiv1.KeptnProjectSpec{InitialShipyardRef: &apiv1.ConfigMapKeyReference{Name: "shipyards", Key: "missing"}},
+			wantErr: true,
+		},
+		{
+			name:    "configmap_missing",
+			project: "podtato",
+			spec:    apiv1.KeptnProjectSpec{InitialShipyardRef: &apiv1.ConfigMapKeyReference{Name: "missing", Key: "podtato"}},
+			wantErr: true,
+		},
+		{
+			name:         "composed_of_stages",
+			project:      "composed",
+			wantContains: "- name: dev",
+		},
+		{
+			name:         "no_stages",
+			project:      "empty",
+			wantShipyard: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := &apiv1.KeptnProject{ObjectMeta: metav1.ObjectMeta{Name: tt.project, Namespace: "keptn"}, Spec: tt.spec}
+			got, err := r.initialShipyard(context.TODO(), project)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			decoded, err := base64.StdEncoding.DecodeString(got)
+			require.NoError(t, err)
+			if tt.wantContains != "" {
+				require.Contains(t, string(decoded), tt.wantContains)
+				require.Contains(t, string(decoded), "- name: deployment")
+				return
+			}
+			require.Equal(t, tt.wantShipyard, string(decoded))
+		})
+	}
+}
+
+func countUpdates(requests []string) int {
+	count := 0
+	for _, request := range requests {
+		if request == "PUT /controlPlane/v1/project" {
+			count++
+		}
+	}
+	return count
+}
+
+func eventReason(t *testing.T, recorder *record.FakeRecorder) string {
+	select {
+	case event := <-recorder.Events:
+		// events are formatted as "<type> <reason> <message>"
+		return strings.Fields(event)[1]
+	default:
+		t.Fatal("no event has been recorded")
+		return ""
+	}
+}
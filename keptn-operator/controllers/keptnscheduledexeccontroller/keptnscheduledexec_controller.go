@@ -19,6 +19,7 @@ package keptnscheduledexeccontroller
 import (
 	"context"
 	"github.com/go-logr/logr"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -73,11 +74,16 @@ func (r *KeptnScheduledExecReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 	scheduledTime, err := time.Parse(time.RFC3339, keptnexec.Spec.StartTime)
 	if err != nil {
-		return ctrl.Result{}, err
+		r.ReqLogger.Error(err, "Could not parse startTime")
+		if err := conditions.Stalled(ctx, r.Client, keptnexec, apiv1.ReasonInvalidSpec, "Could not parse startTime: "+err.Error()); err != nil {
+			return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
+		}
+		return ctrl.Result{}, nil
 	}
 
 	if scheduledTime.After(time.Now()) && keptnexec.Status.Started == true {
 		keptnexec.Status.Started = false
+		conditions.MarkReady(keptnexec, apiv1.ReasonReconciled, "Waiting for "+keptnexec.Spec.StartTime)
 		err = r.Client.Status().Update(ctx, keptnexec)
 		if err != nil {
 			return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
@@ -96,10 +102,14 @@ func (r *KeptnScheduledExecReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 		err := r.Client.Create(ctx, &seq)
 		if err != nil {
+			if err := conditions.Stalled(ctx, r.Client, keptnexec, apiv1.ReasonFailed, "Could not create KeptnSequenceExecution: "+err.Error()); err != nil {
+				r.ReqLogger.Error(err, "Could not update status")
+			}
 			return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
 		}
 
 		keptnexec.Status.Started = true
+		conditions.MarkReady(keptnexec, apiv1.ReasonReconciled, "Started KeptnSequenceExecution "+seq.Name)
 		err = r.Client.Status().Update(ctx, keptnexec)
 		if err != nil {
 			return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
@@ -107,6 +117,14 @@ func (r *KeptnScheduledExecReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	message := "Waiting for " + keptnexec.Spec.StartTime
+	if keptnexec.Status.Started {
+		message = "KeptnSequenceExecution has been started"
+	}
+	if err := conditions.Ready(ctx, r.Client, keptnexec, apiv1.ReasonReconciled, message); err != nil {
+		return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
+	}
+
 	r.ReqLogger.Info("Finished Reconciling KeptnScheduledExec")
 	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 }
@@ -18,11 +18,22 @@ package keptnsequencecontroller
 
 import (
 	"context"
+	"fmt"
 	"github.com/go-logr/logr"
 	keptnshv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sort"
+	"strings"
 	"time"
 
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -44,29 +55,218 @@ type KeptnSequenceReconciler struct {
 //+kubebuilder:rbac:groups=keptn.sh,resources=keptnsequences,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=keptn.sh,resources=keptnsequences/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=keptn.sh,resources=keptnsequences/finalizers,verbs=update
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptnstages,verbs=get;list;watch
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptnshipyards,verbs=get;list;watch;update
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the KeptnSequence object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
+const sequenceFinalizerName = "keptnsequences.keptn.sh/finalizer"
+
+// Reconcile recomposes the shipyards of all projects with a KeptnStage referring to the KeptnSequence, so that
+// changes of the sequence are rolled out to Keptn. A deleted sequence is kept until no stage refers to it anymore,
+// afterwards the shipyards of the projects which used it are recomposed once more. Changes of KeptnStages trigger the
+// reconciliation of the sequences they refer to, so the sequence is only requeued after errors.
 func (r *KeptnSequenceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Reconciling KeptnSequence")
 
-	// your logic here
+	keptnsequence := &keptnshv1.KeptnSequence{}
+	if err := r.Client.Get(ctx, req.NamespacedName, keptnsequence); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("KeptnSequence resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to get the KeptnSequence")
+		return ctrl.Result{}, err
+	}
+
+	// examine DeletionTimestamp to determine if object is under deletion
+	if keptnsequence.ObjectMeta.DeletionTimestamp.IsZero() {
+		// the finalizer ensures that the sequence is only deleted once it is not part of any shipyard anymore
+		if !utils.ContainsString(keptnsequence.GetFinalizers(), sequenceFinalizerName) {
+			controllerutil.AddFinalizer(keptnsequence, sequenceFinalizerName)
+			if err := r.Update(ctx, keptnsequence); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	} else {
+		if utils.ContainsString(keptnsequence.GetFinalizers(), sequenceFinalizerName) {
+			return r.removeSequence(ctx, req, keptnsequence)
+		}
+
+		// Stop reconciliation as the item is being deleted
+		return ctrl.Result{}, nil
+	}
+
+	stages, err := utils.GetStagesForSequence(ctx, r.Client, keptnsequence)
+	if err != nil {
+		logger.Error(err, "Could not get stages referring to the sequence")
+		r.setStalled(ctx, keptnsequence, keptnshv1.ReasonFailed, "Could not get stages: "+err.Error())
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	stageNames := []string{}
+	var projects []types.NamespacedName
+	for _, stage := range stages {
+		stageNames = append(stageNames, displayName(req.Namespace, stage.Namespace, stage.Name))
+		project := types.NamespacedName{Namespace: stage.Namespace, Name: stage.Spec.Project}
+		if !containsProject(projects, project) {
+			projects = append(projects, project)
+		}
+	}
+	sort.Strings(stageNames)
+
+	updatedProjects, err := r.updateShipyards(ctx, req.Namespace, projects)
+	if err != nil {
+		logger.Error(err, "Could not update shipyards")
+		r.setStalled(ctx, keptnsequence, keptnshv1.ReasonFailed, err.Error())
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	keptnsequence.Status.Stages = stageNames
+	keptnsequence.Status.Projects = updatedProjects
+	conditions.MarkReady(keptnsequence, keptnshv1.ReasonReconciled, fmt.Sprintf("Sequence is used by %d KeptnStages", len(stageNames)))
+	if err := r.Client.Status().Update(ctx, keptnsequence); err != nil {
+		logger.Error(err, "Could not update status")
+		return ctrl.Result{}, err
+	}
 
 	logger.Info("Finished Reconciling KeptnSequence")
-	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	return ctrl.Result{}, nil
+}
+
+//removeSequence recomposes the shipyards of the projects which used a deleted sequence as soon as no stage refers to it anymore
+func (r *KeptnSequenceReconciler) removeSequence(ctx context.Context, req ctrl.Request, keptnsequence *keptnshv1.KeptnSequence) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	stages, err := utils.GetStagesForSequence(ctx, r.Client, keptnsequence)
+	if err != nil {
+		logger.Error(err, "Could not get stages referring to the sequence")
+		r.setStalled(ctx, keptnsequence, keptnshv1.ReasonFailed, "Could not get stages: "+err.Error())
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	if len(stages) > 0 {
+		stageNames := []string{}
+		for _, stage := range stages {
+			stageNames = append(stageNames, displayName(req.Namespace, stage.Namespace, stage.Name))
+		}
+		sort.Strings(stageNames)
+		message := fmt.Sprintf("Sequence %s is still used by the KeptnStages %s", keptnsequence.Name, strings.Join(stageNames, ", "))
+		r.Recorder.Event(keptnsequence, "Warning", "SequenceInUse", message)
+		r.setStalled(ctx, keptnsequence, keptnshv1.ReasonSequenceInUse, message)
+		// removing the reference from the last stage triggers a new reconciliation
+		return ctrl.Result{}, nil
+	}
+
+	// the stages of these projects referred to the sequence during the last reconciliation, projects in namespaces which
+	// are not allowed to reference the sequence anymore are left alone
+	var projects []types.NamespacedName
+	for _, project := range keptnsequence.Status.Projects {
+		if name := namespacedName(req.Namespace, project); keptnshv1.IsReferenceAllowed(keptnsequence, name.Namespace) {
+			projects = append(projects, name)
+		}
+	}
+	if _, err := r.updateShipyards(ctx, req.Namespace, projects); err != nil {
+		logger.Error(err, "Could not update shipyards")
+		r.setStalled(ctx, keptnsequence, keptnshv1.ReasonFailed, err.Error())
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	controllerutil.RemoveFinalizer(keptnsequence, sequenceFinalizerName)
+	if err := r.Update(ctx, keptnsequence); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+//updateShipyards recomposes the shipyards of the projects and returns the names of the projects having a shipyard, unchanged shipyards are not updated
+func (r *KeptnSequenceReconciler) updateShipyards(ctx context.Context, sequenceNamespace string, projects []types.NamespacedName) ([]string, error) {
+	updatedProjects := []string{}
+	for _, project := range projects {
+		projectName := displayName(sequenceNamespace, project.Namespace, project.Name)
+		shipyardPresent, shipyardHash := utils.CheckKeptnShipyard(ctx, ctrl.Request{NamespacedName: project}, r.Client, project.Name)
+		if !shipyardPresent {
+			// the shipyard is composed by the project controller as soon as it is created
+			continue
+		}
+
+		shipyard, err := utils.CreateShipyard(ctx, r.Client, project.Name, project.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("could not create shipyard of project %s: %w", projectName, err)
+		}
+
+		if err := utils.UpdateShipyard(ctx, r.Client, shipyard, shipyardHash, project.Namespace); err != nil {
+			return nil, fmt.Errorf("could not update shipyard of project %s: %w", projectName, err)
+		}
+		updatedProjects = append(updatedProjects, projectName)
+	}
+	sort.Strings(updatedProjects)
+	return updatedProjects, nil
+}
+
+//displayName returns the name of an object, prefixed with its namespace if it differs from the namespace of the sequence
+func displayName(sequenceNamespace, namespace, name string) string {
+	if namespace == sequenceNamespace {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+//namespacedName reverses displayName
+func namespacedName(sequenceNamespace, name string) types.NamespacedName {
+	if i := strings.Index(name, "/"); i >= 0 {
+		return types.NamespacedName{Namespace: name[:i], Name: name[i+1:]}
+	}
+	return types.NamespacedName{Namespace: sequenceNamespace, Name: name}
+}
+
+func containsProject(projects []types.NamespacedName, project types.NamespacedName) bool {
+	for _, p := range projects {
+		if p == project {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *KeptnSequenceReconciler) setStalled(ctx context.Context, keptnsequence *keptnshv1.KeptnSequence, reason, message string) {
+	if err := conditions.Stalled(ctx, r.Client, keptnsequence, reason, message); err != nil {
+		log.FromContext(ctx).Error(err, "Could not update status")
+	}
+}
+
+//sequencesOfStage maps a KeptnStage to the KeptnSequences it refers to, updates are mapped for the old and the new
+//version of the stage, so sequences are also reconciled when a stage stops referring to them
+func sequencesOfStage(obj client.Object) []reconcile.Request {
+	stage, ok := obj.(*keptnshv1.KeptnStage)
+	if !ok {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, seq := range stage.Spec.Sequence {
+		if seq.SequenceRef == "" {
+			continue
+		}
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: seq.GetSequenceNamespace(stage.Namespace), Name: seq.SequenceRef}}
+		if !containsRequest(requests, request) {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+func containsRequest(requests []reconcile.Request, request reconcile.Request) bool {
+	for _, r := range requests {
+		if r == request {
+			return true
+		}
+	}
+	return false
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *KeptnSequenceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&keptnshv1.KeptnSequence{}).
+		Watches(&source.Kind{Type: &keptnshv1.KeptnStage{}}, handler.EnqueueRequestsFromMapFunc(sequencesOfStage)).
 		Complete(r)
 }
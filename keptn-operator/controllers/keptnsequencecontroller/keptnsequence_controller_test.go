@@ -0,0 +1,191 @@
+package keptnsequencecontroller
+
+import (
+	"context"
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"testing"
+)
+
+func newStage(name, project string, sequences ...string) *apiv1.KeptnStage {
+	stage := &apiv1.KeptnStage{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "keptn"},
+		Spec:       apiv1.KeptnStageSpec{Project: project},
+	}
+	for _, sequence := range sequences {
+		stage.Spec.Sequence = append(stage.Spec.Sequence, apiv1.KeptnSequenceRefSpec{Type: "sequenceref", SequenceRef: sequence})
+	}
+	return stage
+}
+
+func TestReconcileUpdatesShipyards(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+
+	r := &KeptnSequenceReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&apiv1.KeptnSequence{
+				ObjectMeta: metav1.ObjectMeta{Name: "delivery", Namespace: "keptn"},
+				Spec: apiv1.KeptnSequenceSpec{Sequence: apiv1.Sequence{
+					Name:  "delivery",
+					Tasks: []apiv1.Task{{Name: "deployment"}, {Name: "evaluation"}},
+				}},
+			},
+			&apiv1.KeptnSequence{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "keptn"},
+				Spec:       apiv1.KeptnSequenceSpec{Sequence: apiv1.Sequence{Name: "other", Tasks: []apiv1.Task{{Name: "test"}}}},
+			},
+			newStage("dev", "podtato", "delivery"),
+			newStage("prod", "podtato", "other", "delivery"),
+			newStage("staging", "sockshop", "delivery"),
+			newStage("unrelated", "unrelated", "other"),
+			&apiv1.KeptnShipyard{
+				ObjectMeta: metav1.ObjectMeta{Name: "podtato", Namespace: "keptn"},
+				Spec:       apiv1.KeptnShipyardSpec{Project: "podtato"},
+			},
+		).Build(),
+		Scheme: scheme,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "delivery", Namespace: "keptn"}}
+
+	_, err := r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	shipyard := &apiv1.KeptnShipyard{}
+	require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "podtato", Namespace: "keptn"}, shipyard))
+	stages := shipyard.Spec.Shipyard.Spec.Stages
+	require.Len(t, stages, 2)
+	for _, stage := range stages {
+		require.Equal(t, "delivery", stage.Sequences[len(stage.Sequences)-1].Name)
+		require.Len(t, stage.Sequences[len(stage.Sequences)-1].Tasks, 2)
+	}
+
+	// the project sockshop has no shipyard yet, so only podtato has been updated
+	sequence := &apiv1.KeptnSequence{}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, sequence))
+	require.Equal(t, []string{"dev", "prod", "staging"}, sequence.Status.Stages)
+	require.Equal(t, []string{"podtato"}, sequence.Status.Projects)
+	require.True(t, conditions.IsReady(sequence))
+}
+
+func TestReconcileDeletion(t *testing.T) {
+	tests := []struct {
+		name          string
+		inUse         bool
+		foreignStage  bool
+		wantFinalizer bool
+		wantSequences []string
+	}{
+		{name: "in_use", inUse: true, wantFinalizer: true, wantSequences: []string{"delivery"}},
+		{name: "unused", inUse: false, wantFinalizer: false, wantSequences: []string{"other"}},
+		// stages in namespaces which are not allowed to reference the sequence neither block the deletion nor are recomposed
+		{name: "used_from_other_namespace", foreignStage: true, wantFinalizer: false, wantSequences: []string{"other"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, apiv1.AddToScheme(scheme))
+
+			stage := newStage("dev", "podtato", "other")
+			if tt.inUse {
+				stage = newStage("dev", "podtato", "delivery")
+			}
+			now := metav1.Now()
+			deleted := &apiv1.KeptnSequence{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "delivery",
+					Namespace:         "keptn",
+					DeletionTimestamp: &now,
+					Finalizers:        []string{sequenceFinalizerName},
+				},
+				Spec:   apiv1.KeptnSequenceSpec{Sequence: apiv1.Sequence{Name: "delivery", Tasks: []apiv1.Task{{Name: "deployment"}}}},
+				Status: apiv1.KeptnSequenceStatus{Stages: []string{"dev"}, Projects: []string{"podtato"}},
+			}
+			shipyard := &apiv1.KeptnShipyard{
+				ObjectMeta: metav1.ObjectMeta{Name: "podtato", Namespace: "keptn"},
+				Spec:       apiv1.KeptnShipyardSpec{Project: "podtato"},
+			}
+			shipyard.Spec.Shipyard.Spec.Stages = []apiv1.Stage{{Name: "dev", Sequences: []apiv1.Sequence{deleted.Spec.Sequence}}}
+
+			foreignStage := newStage("dev", "sockshop")
+			foreignStage.Namespace = "team-a"
+			foreignStage.Spec.Sequence = []apiv1.KeptnSequenceRefSpec{{Type: "sequenceref", SequenceRef: "delivery", Namespace: "keptn"}}
+			foreignShipyard := &apiv1.KeptnShipyard{
+				ObjectMeta: metav1.ObjectMeta{Name: "sockshop", Namespace: "team-a"},
+				Spec:       apiv1.KeptnShipyardSpec{Project: "sockshop"},
+			}
+			if tt.foreignStage {
+				deleted.Status.Projects = append(deleted.Status.Projects, "team-a/sockshop")
+			} else {
+				foreignStage.Spec.Sequence = nil
+			}
+
+			r := &KeptnSequenceReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+					deleted,
+					&apiv1.KeptnSequence{
+						ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "keptn"},
+						Spec:       apiv1.KeptnSequenceSpec{Sequence: apiv1.Sequence{Name: "other", Tasks: []apiv1.Task{{Name: "test"}}}},
+					},
+					stage,
+					shipyard,
+					foreignStage,
+					foreignShipyard,
+				).Build(),
+				Scheme:   scheme,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "delivery", Namespace: "keptn"}}
+
+			_, err := r.Reconcile(context.TODO(), req)
+			require.NoError(t, err)
+
+			require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "podtato", Namespace: "keptn"}, shipyard))
+			require.Len(t, shipyard.Spec.Shipyard.Spec.Stages, 1)
+			var sequences []string
+			for _, sequence := range shipyard.Spec.Shipyard.Spec.Stages[0].Sequences {
+				sequences = append(sequences, sequence.Name)
+			}
+			require.Equal(t, tt.wantSequences, sequences)
+
+			require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "sockshop", Namespace: "team-a"}, foreignShipyard))
+			require.Empty(t, foreignShipyard.Spec.Shipyard.Spec.Stages)
+
+			sequence := &apiv1.KeptnSequence{}
+			err = r.Client.Get(context.TODO(), req.NamespacedName, sequence)
+			if tt.wantFinalizer {
+				require.NoError(t, err)
+				stalled := meta.FindStatusCondition(sequence.Status.Conditions, apiv1.ConditionStalled)
+				require.NotNil(t, stalled)
+				require.Equal(t, apiv1.ReasonSequenceInUse, stalled.Reason)
+			} else {
+				require.True(t, errors.IsNotFound(err))
+			}
+		})
+	}
+}
+
+func TestSequencesOfStage(t *testing.T) {
+	stage := newStage("prod", "podtato", "delivery", "other", "delivery")
+	stage.Spec.Sequence = append(stage.Spec.Sequence,
+		apiv1.KeptnSequenceRefSpec{Type: "sequenceref", SequenceRef: "delivery", Namespace: "shared"},
+		apiv1.KeptnSequenceRefSpec{Type: "inline", Sequence: &apiv1.Sequence{Name: "inline"}},
+	)
+
+	require.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "delivery", Namespace: "keptn"}},
+		{NamespacedName: types.NamespacedName{Name: "other", Namespace: "keptn"}},
+		{NamespacedName: types.NamespacedName{Name: "delivery", Namespace: "shared"}},
+	}, sequencesOfStage(stage))
+	require.Empty(t, sequencesOfStage(&apiv1.KeptnSequence{}))
+}
@@ -17,19 +17,16 @@ limitations under the License.
 package keptnsequenceexecutioncontroller
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/go-logr/logr"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
-	apiutils "github.com/keptn/go-utils/pkg/api/utils"
-	"io"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
-	nethttp "net/http"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"time"
 
@@ -51,35 +48,8 @@ type KeptnSequenceExecutionReconciler struct {
 	KeptnInstance apiv1.KeptnInstance
 	// KeptnAPIToken contains the API token used in this controller
 	KeptnAPIToken string
-}
-
-// KeptnTriggerEvent describes a Keptn Event which should be triggered
-type KeptnTriggerEvent struct {
-	ContentType string         `json:"contenttype,omitempty"`
-	Data        KeptnEventData `json:"data,omitempty"`
-	Source      string         `json:"source,omitempty"`
-	SpecVersion string         `json:"specversion,omitempty"`
-	Type        string         `json:"type,omitempty"`
-}
-
-// KeptnEventData describes the Event Data of an KeptnTriggerEvent
-type KeptnEventData struct {
-	Project             string                  `json:"project,omitempty"`
-	Service             string                  `json:"service,omitempty"`
-	Stage               string                  `json:"stage,omitempty"`
-	Image               string                  `json:"image,omitempty"`
-	Labels              map[string]string       `json:"labels,omitempty"`
-	ConfigurationChange ConfigurationChangeData `json:"configurationChange,omitempty"`
-}
-
-// ConfigurationChangeData describes the Configuration Change block of a KeptnEventData
-type ConfigurationChangeData struct {
-	Values map[string]string `json:"values,omitempty"`
-}
-
-// CreateEventResponse describes the Response of a sent Keptn Event
-type CreateEventResponse struct {
-	KeptnContext string `json:"keptnContext"`
+	// KeptnCache contains the cached projects and services of the Keptn instances
+	KeptnCache *keptnclient.Cache
 }
 
 const reconcileErrorInterval = 10 * time.Second
@@ -102,13 +72,6 @@ func (r *KeptnSequenceExecutionReconciler) Reconcile(ctx context.Context, req ct
 	r.ReqLogger = ctrl.Log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
 	r.ReqLogger.Info("Reconciling KeptnSequenceExecution")
 
-	var err error
-	r.KeptnInstance, r.KeptnAPIToken, err = utils.GetKeptnInstance(ctx, r.Client, req.Namespace)
-	if err != nil {
-		r.ReqLogger.Error(err, "Could not get Keptn Instance")
-		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
-	}
-
 	kse := &apiv1.KeptnSequenceExecution{}
 
 	if err := r.Client.Get(ctx, req.NamespacedName, kse); err != nil {
@@ -121,10 +84,21 @@ func (r *KeptnSequenceExecutionReconciler) Reconcile(ctx context.Context, req ct
 		return ctrl.Result{}, err
 	}
 
+	var err error
+	r.KeptnInstance, r.KeptnAPIToken, err = utils.GetKeptnInstanceForProject(ctx, r.Client, kse.Spec.Project, req.Namespace)
+	if err != nil {
+		r.ReqLogger.Error(err, "Could not get Keptn Instance")
+		if err := conditions.Stalled(ctx, r.Client, kse, apiv1.ReasonKeptnInstanceNotFound, err.Error()); err != nil {
+			r.ReqLogger.Error(err, "Could not update status of kse "+kse.Name)
+		}
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
+
 	if !r.checkKeptnProject(ctx, req, kse.Spec.Project) {
 		r.Recorder.Event(kse, "Warning", "KeptnProjectNotFound", fmt.Sprintf("Keptn project %s does not exist", kse.Spec.Project))
 		kse.Status.ProjectExists = false
 		kse.Status.UpdatePending = true
+		conditions.MarkReconciling(kse, apiv1.ReasonProjectNotFound, fmt.Sprintf("Waiting for Keptn project %s", kse.Spec.Project))
 		err := r.Client.Status().Update(ctx, kse)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not update status of KeptnSequenceExecution "+kse.Name)
@@ -139,11 +113,12 @@ func (r *KeptnSequenceExecutionReconciler) Reconcile(ctx context.Context, req ct
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	exists, err := r.checkIfServiceExists(kse.Spec.Project, kse.Spec.Service)
+	exists, err := r.checkIfServiceExists(ctx, kse.Spec.Project, kse.Spec.Service)
 	if !exists {
 		r.Recorder.Event(kse, "Warning", "KeptnServiceNotFound", fmt.Sprintf("Keptn service %s in project %s does not exist", kse.Spec.Service, kse.Spec.Project))
 		kse.Status.ServiceExists = false
 		kse.Status.UpdatePending = true
+		conditions.MarkReconciling(kse, apiv1.ReasonServiceNotFound, fmt.Sprintf("Waiting for Keptn service %s in project %s", kse.Spec.Service, kse.Spec.Project))
 		err := r.Client.Status().Update(ctx, kse)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not update status of kse "+kse.Name)
@@ -159,14 +134,18 @@ func (r *KeptnSequenceExecutionReconciler) Reconcile(ctx context.Context, req ct
 	}
 
 	if kse.Status.KeptnContext == "" || kse.Status.LastAppliedHash != utils.GetHashStructure(kse.Spec) || kse.Status.UpdatePending {
-		kcontext, err := r.triggerTask(kse)
+		kcontext, err := r.triggerTask(ctx, kse)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not trigger task")
+			if err := conditions.Stalled(ctx, r.Client, kse, apiv1.ReasonKeptnAPIError, "Could not trigger sequence: "+err.Error()); err != nil {
+				r.ReqLogger.Error(err, "Could not update status of kse "+kse.Name)
+			}
 			return ctrl.Result{Requeue: true}, err
 		}
 		kse.Status.UpdatePending = false
 		kse.Status.KeptnContext = kcontext
 		kse.Status.LastAppliedHash = utils.GetHashStructure(kse.Spec)
+		conditions.MarkReady(kse, apiv1.ReasonReconciled, "Triggered sequence with Keptn context "+kcontext)
 		err = r.Client.Status().Update(ctx, kse)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not update status of kse "+kse.Name)
@@ -195,95 +174,35 @@ func (r *KeptnSequenceExecutionReconciler) checkKeptnProject(ctx context.Context
 	return true
 }
 
-func (r *KeptnSequenceExecutionReconciler) checkIfServiceExists(project string, service string) (bool, error) {
-
-	projectsHandler := apiutils.NewAuthenticatedProjectHandler(r.KeptnInstance.Spec.APIUrl, r.KeptnAPIToken, r.KeptnInstance.Status.AuthHeader, nil, r.KeptnInstance.Status.Scheme)
-	servicesHandler := apiutils.NewAuthenticatedServiceHandler(r.KeptnInstance.Spec.APIUrl, r.KeptnAPIToken, r.KeptnInstance.Status.AuthHeader, nil, r.KeptnInstance.Status.Scheme)
-
-	projects, err := projectsHandler.GetAllProjects()
-	if err != nil {
-		return false, err
-	}
-
-	filteredProjects := utils.FilterProjects(projects, project)
-	if len(filteredProjects) == 0 {
-		if project != "" {
-			return false, fmt.Errorf("no project %s found", project)
-		}
-		return false, fmt.Errorf("no projects found")
-	}
-
-	for _, proj := range filteredProjects {
-		for _, stage := range proj.Stages {
-			services, err := servicesHandler.GetAllServices(proj.ProjectName, stage.StageName)
-			if err != nil {
-				return false, err
-			}
-			filteredServices := utils.FilterServices(services, service)
-			if len(filteredServices) == 0 {
-				return false, fmt.Errorf("no services %s found in project %s", service, project)
-			}
-			return true, nil
-		}
-	}
-	return false, nil
+func (r *KeptnSequenceExecutionReconciler) checkIfServiceExists(ctx context.Context, project string, service string) (bool, error) {
+	return r.KeptnCache.ServiceExists(ctx, keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnAPIToken), project, service)
 }
 
-func (r *KeptnSequenceExecutionReconciler) triggerTask(exec *apiv1.KeptnSequenceExecution) (string, error) {
-
-	httpclient := nethttp.Client{
-		Timeout: 30 * time.Second,
-	}
-
+func (r *KeptnSequenceExecutionReconciler) triggerTask(ctx context.Context, exec *apiv1.KeptnSequenceExecution) (string, error) {
 	version := "undefined"
 
 	if exec.Spec.Labels["version"] != "" {
 		version = exec.Spec.Labels[version]
 	}
 
-	data, err := json.Marshal(KeptnTriggerEvent{
+	event := keptnclient.Event{
 		ContentType: "application/json",
-		Data: KeptnEventData{
+		Data: keptnclient.EventData{
 			Service: exec.Spec.Service,
 			Project: exec.Spec.Project,
 			Stage:   exec.Spec.Stage,
 			Labels:  exec.Spec.Labels,
 			Image:   exec.Spec.Service + ":" + version,
 		},
-		Source:      "Keptn GitOps Operator",
+		Source:      keptnclient.EventSource,
 		SpecVersion: "1.0",
 		Type:        "sh.keptn.event." + exec.Spec.Event,
-	})
-	if err != nil {
-		r.ReqLogger.Info("Could not marshal Keptn Trigger Event")
 	}
 
 	r.ReqLogger.Info("Triggering Event " + exec.Spec.Event + " for service " + exec.Spec.Service)
-	request, err := nethttp.NewRequest("POST", r.KeptnInstance.Spec.APIUrl+"/v1/event", bytes.NewBuffer(data))
-	if err != nil {
-		r.ReqLogger.Error(err, "Could not trigger event "+exec.Spec.Event+" for service "+exec.Spec.Service)
-		return "", err
-	}
-
-	request.Header.Set("content-type", "application/cloudevents+json")
-	request.Header.Set(r.KeptnInstance.Status.AuthHeader, r.KeptnAPIToken)
-
-	response, err := httpclient.Do(request)
-	if err != nil {
-		return "", err
-	}
-
-	err = utils.CheckResponseCode(response, nethttp.StatusOK)
-	if err != nil {
-		return "", err
-	}
-
-	respBody, err := io.ReadAll(response.Body)
-	kcontext := &CreateEventResponse{}
-
-	err = json.Unmarshal(respBody, kcontext)
+	kcontext, err := keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnAPIToken).SendEvent(ctx, event)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("could not trigger event %s for service %s: %w", exec.Spec.Event, exec.Spec.Service, err)
 	}
-	return kcontext.KeptnContext, err
+	return kcontext, nil
 }
@@ -17,23 +17,21 @@ limitations under the License.
 package keptnservicecontroller
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/go-logr/logr"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
-	nethttp "net/http"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"time"
 
 	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
-	apiutils "github.com/keptn/go-utils/pkg/api/utils"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -51,6 +49,8 @@ type KeptnServiceReconciler struct {
 	KeptnInstance apiv1.KeptnInstance
 	// KeptnAPIToken contains the API token used in this controller
 	KeptnAPIToken string
+	// KeptnCache contains the cached projects and services of the Keptn instances
+	KeptnCache *keptnclient.Cache
 }
 
 const reconcileErrorInterval = 10 * time.Second
@@ -73,13 +73,6 @@ func (r *KeptnServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	r.ReqLogger = ctrl.Log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
 	r.ReqLogger.Info("Reconciling KeptnService")
 
-	var err error
-	r.KeptnInstance, r.KeptnAPIToken, err = utils.GetKeptnInstance(ctx, r.Client, req.Namespace)
-	if err != nil {
-		r.ReqLogger.Error(err, "Could not get Keptn Instance")
-		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
-	}
-
 	keptnservice := &apiv1.KeptnService{}
 
 	if err := r.Client.Get(ctx, req.NamespacedName, keptnservice); err != nil {
@@ -94,26 +87,48 @@ func (r *KeptnServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	// name of our custom finalizer
 	myFinalizerName := "keptnservices.keptn.sh/finalizer"
+	deletionPolicy := keptnservice.Spec.GetDeletionPolicy()
+	protected := apiv1.IsDeletionProtected(keptnservice)
 
 	// examine DeletionTimestamp to determine if object is under deletion
 	if keptnservice.ObjectMeta.DeletionTimestamp.IsZero() {
 		// The object is not being deleted, so if it does not have our finalizer,
 		// then lets add the finalizer and update the object. This is equivalent
-		// registering our finalizer.
-		if !utils.ContainsString(keptnservice.GetFinalizers(), myFinalizerName) {
+		// registering our finalizer. Orphaned services don't need the finalizer unless they are protected.
+		needsFinalizer := deletionPolicy != apiv1.DeletionPolicyOrphan || protected
+		hasFinalizer := utils.ContainsString(keptnservice.GetFinalizers(), myFinalizerName)
+		if needsFinalizer && !hasFinalizer {
 			controllerutil.AddFinalizer(keptnservice, myFinalizerName)
 			if err := r.Update(ctx, keptnservice); err != nil {
 				return ctrl.Result{}, err
 			}
+		} else if !needsFinalizer && hasFinalizer {
+			controllerutil.RemoveFinalizer(keptnservice, myFinalizerName)
+			if err := r.Update(ctx, keptnservice); err != nil {
+				return ctrl.Result{}, err
+			}
 		}
 	} else {
 		// The object is being deleted
 		if utils.ContainsString(keptnservice.GetFinalizers(), myFinalizerName) {
-			// our finalizer is present, so lets handle any external dependency
-			if err := r.deleteKeptnService(keptnservice); err != nil {
-				// if fail to delete the external dependency here, return with error
-				// so that it can be retried
-				return ctrl.Result{}, err
+			if protected {
+				r.Recorder.Event(keptnservice, "Warning", "DeletionProtected", fmt.Sprintf("Deletion of service %s is blocked by the %s annotation", keptnservice.Name, apiv1.DeletionProtectionAnnotation))
+				if err := conditions.Stalled(ctx, r.Client, keptnservice, apiv1.ReasonDeletionProtected, "Remove the annotation "+apiv1.DeletionProtectionAnnotation+" to delete the service"); err != nil {
+					r.ReqLogger.Error(err, "Could not update status of service "+keptnservice.Name)
+				}
+				// removing the annotation triggers a new reconciliation
+				return ctrl.Result{}, nil
+			}
+
+			if deletionPolicy == apiv1.DeletionPolicyDelete {
+				// our finalizer is present, so lets handle any external dependency
+				if err := r.deleteKeptnService(ctx, keptnservice); err != nil {
+					// if fail to delete the external dependency here, return with error
+					// so that it can be retried
+					return ctrl.Result{}, err
+				}
+			} else {
+				r.Recorder.Event(keptnservice, "Normal", "KeptnServiceRetained", fmt.Sprintf("Keptn service %s has not been deleted in Keptn (deletionPolicy: %s)", keptnservice.Spec.Service, deletionPolicy))
 			}
 
 			// remove our finalizer from the list and update it.
@@ -127,9 +142,23 @@ func (r *KeptnServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, nil
 	}
 
+	if err := r.getKeptnInstance(ctx, keptnservice); err != nil {
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
+
+	// the instance is recorded before the service is created, so the service is deleted in the same instance
+	if ref := r.KeptnInstance.Reference(); keptnservice.Status.Instance == nil || *keptnservice.Status.Instance != *ref {
+		keptnservice.Status.Instance = ref
+		if err := r.Client.Status().Update(ctx, keptnservice); err != nil {
+			r.ReqLogger.Error(err, "Could not update status of service "+keptnservice.Name)
+			return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
+		}
+	}
+
 	if !r.checkKeptnProject(ctx, req, keptnservice.Spec.Project) {
 		r.Recorder.Event(keptnservice, "Warning", "KeptnProjectNotFound", fmt.Sprintf("Keptn project %s does not exist", keptnservice.Spec.Project))
 		keptnservice.Status.ProjectExists = false
+		conditions.MarkReconciling(keptnservice, apiv1.ReasonProjectNotFound, fmt.Sprintf("Waiting for Keptn project %s", keptnservice.Spec.Project))
 		err := r.Client.Status().Update(ctx, keptnservice)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not update status of project "+keptnservice.Spec.Project)
@@ -146,15 +175,31 @@ func (r *KeptnServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	exists, err := r.checkIfServiceExists(keptnservice.Spec.Project, keptnservice.Spec.Service)
+	exists, err := r.checkIfServiceExists(ctx, keptnservice.Spec.Project, keptnservice.Spec.Service)
+	if err != nil {
+		// the service must not be created again if it can't be determined whether it exists
+		r.ReqLogger.Error(err, "Could not check if service "+keptnservice.Spec.Service+" exists")
+		if err := conditions.Stalled(ctx, r.Client, keptnservice, apiv1.ReasonKeptnAPIError, err.Error()); err != nil {
+			r.ReqLogger.Error(err, "Could not update status of service "+keptnservice.Name)
+		}
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
 	if !exists {
-		err := r.createService(keptnservice.Spec.Service, keptnservice.Spec.Project)
+		err := r.createService(ctx, keptnservice.Spec.Service, keptnservice.Spec.Project)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not create service "+keptnservice.Spec.Service)
+			if err := conditions.Stalled(ctx, r.Client, keptnservice, apiv1.ReasonKeptnAPIError, err.Error()); err != nil {
+				r.ReqLogger.Error(err, "Could not update status of service "+keptnservice.Name)
+			}
 			return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
 		}
 	}
 
+	if err := conditions.Ready(ctx, r.Client, keptnservice, apiv1.ReasonReconciled, fmt.Sprintf("Service exists in Keptn project %s", keptnservice.Spec.Project)); err != nil {
+		r.ReqLogger.Error(err, "Could not update status of service "+keptnservice.Name)
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
+	}
+
 	r.ReqLogger.Info("Finished Reconciling KeptnService")
 	return ctrl.Result{Requeue: true, RequeueAfter: reconcileSuccessInterval}, err
 }
@@ -180,90 +225,52 @@ func (r *KeptnServiceReconciler) checkKeptnProject(ctx context.Context, req ctrl
 	return true
 }
 
-// Helper functions to check and remove string from a slice of strings.
-
-func (r *KeptnServiceReconciler) deleteKeptnService(keptnservice *apiv1.KeptnService) error {
-	httpclient := nethttp.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	request, err := nethttp.NewRequest("DELETE", r.KeptnInstance.Spec.APIUrl+"/controlPlane/v1/project/"+keptnservice.Spec.Project+"/service/"+keptnservice.Spec.Service, bytes.NewBuffer(nil))
-	if err != nil {
-		r.ReqLogger.Error(err, "Could not delete service "+keptnservice.Name)
-	}
-
-	request.Header.Set("content-type", "application/json")
-	request.Header.Set(r.KeptnInstance.Status.AuthHeader, r.KeptnAPIToken)
-
-	r.ReqLogger.Info("Deleting Keptn Service " + keptnservice.Name)
-	_, err = httpclient.Do(request)
+//getKeptnInstance fetches the KeptnInstance of the project of the service
+func (r *KeptnServiceReconciler) getKeptnInstance(ctx context.Context, keptnservice *apiv1.KeptnService) error {
+	var err error
+	r.KeptnInstance, r.KeptnAPIToken, err = utils.GetKeptnInstanceForProject(ctx, r.Client, keptnservice.Spec.Project, keptnservice.Namespace)
 	if err != nil {
-		return err
+		r.ReqLogger.Error(err, "Could not get Keptn Instance")
+		if err := conditions.Stalled(ctx, r.Client, keptnservice, apiv1.ReasonKeptnInstanceNotFound, err.Error()); err != nil {
+			r.ReqLogger.Error(err, "Could not update status of service "+keptnservice.Name)
+		}
 	}
 	return err
 }
 
-func (r *KeptnServiceReconciler) createService(service string, project string) error {
-	httpclient := nethttp.Client{
-		Timeout: 30 * time.Second,
+//deleteKeptnService deletes the service in the KeptnInstance recorded in the status, the deletion is skipped if the instance
+//is unknown or does not exist anymore
+func (r *KeptnServiceReconciler) deleteKeptnService(ctx context.Context, keptnservice *apiv1.KeptnService) error {
+	instance, token, err := utils.GetRecordedKeptnInstance(ctx, r.Client, keptnservice.Status.Instance, keptnservice.Namespace)
+	if utils.IsKeptnInstanceUnavailable(err) {
+		r.Recorder.Event(keptnservice, "Warning", "CleanupSkipped", fmt.Sprintf("Keptn service %s has not been deleted in Keptn: %s", keptnservice.Spec.Service, err.Error()))
+		return nil
 	}
-
-	data, _ := json.Marshal(map[string]string{
-		"serviceName": service,
-	})
-
-	request, err := nethttp.NewRequest("POST", r.KeptnInstance.Spec.APIUrl+"/controlPlane/v1/project/"+project+"/service", bytes.NewBuffer(data))
 	if err != nil {
-		r.ReqLogger.Error(err, "Could not create service "+service)
 		return err
 	}
 
-	request.Header.Set("content-type", "application/json")
-	request.Header.Set(r.KeptnInstance.Status.AuthHeader, r.KeptnAPIToken)
-
-	r.ReqLogger.Info("Creating Keptn Service " + service)
-	response, err := httpclient.Do(request)
-	if err != nil {
-		return err
-	}
-	err = utils.CheckResponseCode(response, nethttp.StatusOK)
-	if err != nil {
-		return fmt.Errorf("could not create service %v: %v", service, err)
+	r.ReqLogger.Info("Deleting Keptn Service " + keptnservice.Name)
+	keptnClient := keptnclient.NewClientForInstance(instance, token)
+	err = keptnClient.DeleteService(ctx, keptnservice.Spec.Project, keptnservice.Spec.Service)
+	r.KeptnCache.Invalidate(keptnClient)
+	if err != nil && !keptnclient.IsNotFound(err) {
+		return fmt.Errorf("could not delete service %v: %w", keptnservice.Spec.Service, err)
 	}
-
-	return err
+	return nil
 }
 
-func (r *KeptnServiceReconciler) checkIfServiceExists(project string, service string) (bool, error) {
-
-	projectsHandler := apiutils.NewAuthenticatedProjectHandler(r.KeptnInstance.Spec.APIUrl, r.KeptnAPIToken, r.KeptnInstance.Status.AuthHeader, nil, r.KeptnInstance.Status.Scheme)
-	servicesHandler := apiutils.NewAuthenticatedServiceHandler(r.KeptnInstance.Spec.APIUrl, r.KeptnAPIToken, r.KeptnInstance.Status.AuthHeader, nil, r.KeptnInstance.Status.Scheme)
-
-	projects, err := projectsHandler.GetAllProjects()
+func (r *KeptnServiceReconciler) createService(ctx context.Context, service string, project string) error {
+	r.ReqLogger.Info("Creating Keptn Service " + service)
+	keptnClient := keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnAPIToken)
+	err := keptnClient.CreateService(ctx, project, service)
+	r.KeptnCache.Invalidate(keptnClient)
 	if err != nil {
-		return false, err
-	}
-
-	filteredProjects := utils.FilterProjects(projects, project)
-	if len(filteredProjects) == 0 {
-		if project != "" {
-			return false, fmt.Errorf("no project %s found: %w", project, err)
-		}
-		return false, fmt.Errorf("no projects found")
+		return fmt.Errorf("could not create service %v: %w", service, err)
 	}
+	return nil
+}
 
-	for _, proj := range filteredProjects {
-		for _, stage := range proj.Stages {
-			services, err := servicesHandler.GetAllServices(proj.ProjectName, stage.StageName)
-			if err != nil {
-				return false, err
-			}
-			filteredServices := utils.FilterServices(services, service)
-			if len(filteredServices) == 0 {
-				return false, fmt.Errorf("no services %s found in project %s", service, project)
-			}
-			return true, nil
-		}
-	}
-	return false, err
+func (r *KeptnServiceReconciler) checkIfServiceExists(ctx context.Context, project string, service string) (bool, error) {
+	return r.KeptnCache.ServiceExists(ctx, keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnAPIToken), project, service)
 }
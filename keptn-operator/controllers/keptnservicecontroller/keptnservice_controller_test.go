@@ -0,0 +1,234 @@
+package keptnservicecontroller
+
+import (
+	"context"
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	keptnfake "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient/fake"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	nethttp "net/http"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func newReconciler(t *testing.T, server *keptnfake.Server, objects ...client.Object) *KeptnServiceReconciler {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+
+	objects = append(objects,
+		&apiv1.KeptnInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "keptn"},
+			Spec:       apiv1.KeptnInstanceSpec{APIUrl: server.URL},
+			Status:     apiv1.KeptnInstanceStatus{CurrentToken: server.Token},
+		},
+		&apiv1.KeptnProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "podtato", Namespace: "keptn"},
+			Status:     apiv1.KeptnProjectStatus{ProjectExists: true},
+		},
+	)
+
+	return &KeptnServiceReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(100),
+	}
+}
+
+func TestReconcileCreatesService(t *testing.T) {
+	server := keptnfake.NewServer()
+	defer server.Close()
+	server.Token = "my-token"
+	server.AddProject("podtato", "dev", "prod")
+
+	r := newReconciler(t, server, &apiv1.KeptnService{
+		ObjectMeta: metav1.ObjectMeta{Name: "podtato-hello", Namespace: "keptn"},
+		Spec:       apiv1.KeptnServiceSpec{Project: "podtato", Service: "hello"},
+	})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "podtato-hello", Namespace: "keptn"}}
+
+	// the first run adds the finalizer and marks the project as existing
+	_, err := r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+	_, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"hello"}, server.Services("podtato"))
+
+	service := &apiv1.KeptnService{}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, service))
+	require.True(t, conditions.IsReady(service))
+	require.Contains(t, service.Finalizers, "keptnservices.keptn.sh/finalizer")
+	require.Equal(t, &apiv1.KeptnInstanceReference{Name: "default", Namespace: "keptn"}, service.Status.Instance)
+}
+
+func TestReconcileServiceCheckFails(t *testing.T) {
+	server := keptnfake.NewServer()
+	defer server.Close()
+	server.Token = "my-token"
+	server.AddProject("podtato", "dev", "prod")
+	server.FailRequests(nethttp.MethodGet, "/controlPlane/v1/project/podtato/stage", nethttp.StatusBadRequest, 1)
+
+	r := newReconciler(t, server, &apiv1.KeptnService{
+		ObjectMeta: metav1.ObjectMeta{Name: "podtato-hello", Namespace: "keptn", Finalizers: []string{"keptnservices.keptn.sh/finalizer"}},
+		Spec:       apiv1.KeptnServiceSpec{Project: "podtato", Service: "hello"},
+		Status:     apiv1.KeptnServiceStatus{ProjectExists: true},
+	})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "podtato-hello", Namespace: "keptn"}}
+
+	result, err := r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+	require.True(t, result.Requeue)
+	require.Empty(t, server.Services("podtato"))
+
+	service := &apiv1.KeptnService{}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, service))
+	stalled := meta.FindStatusCondition(service.Status.Conditions, apiv1.ConditionStalled)
+	require.NotNil(t, stalled)
+	require.Equal(t, apiv1.ReasonKeptnAPIError, stalled.Reason)
+
+	// the service is created once the Keptn API responds again
+	_, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+	require.Equal(t, []string{"hello"}, server.Services("podtato"))
+}
+
+func TestReconcileDeletion(t *testing.T) {
+	tests := []struct {
+		name           string
+		deletionPolicy apiv1.DeletionPolicy
+		annotations    map[string]string
+		instance       *apiv1.KeptnInstanceReference
+		wantServices   []string
+		wantFinalizer  bool
+	}{
+		{name: "default_policy", wantServices: []string{}},
+		// the service is never deleted in another instance than the one it has been created in
+		{name: "unrecorded_instance", deletionPolicy: apiv1.DeletionPolicyDelete, instance: &apiv1.KeptnInstanceReference{}, wantServices: []string{"hello"}},
+		{name: "deleted_instance", deletionPolicy: apiv1.DeletionPolicyDelete, instance: &apiv1.KeptnInstanceReference{Name: "other", Namespace: "keptn"}, wantServices: []string{"hello"}},
+		{name: "delete", deletionPolicy: apiv1.DeletionPolicyDelete, wantServices: []string{}},
+		{name: "retain", deletionPolicy: apiv1.DeletionPolicyRetain, wantServices: []string{"hello"}},
+		{name: "orphan", deletionPolicy: apiv1.DeletionPolicyOrphan, wantServices: []string{"hello"}},
+		{
+			name:           "protected",
+			deletionPolicy: apiv1.DeletionPolicyDelete,
+			annotations:    map[string]string{apiv1.DeletionProtectionAnnotation: "true"},
+			wantServices:   []string{"hello"},
+			wantFinalizer:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := keptnfake.NewServer()
+			defer server.Close()
+			server.AddProject("podtato", "dev")
+			server.AddService("podtato", "hello")
+
+			instance := &apiv1.KeptnInstanceReference{Name: "default", Namespace: "keptn"}
+			if tt.instance != nil {
+				instance = tt.instance
+			}
+			if instance.Name == "" {
+				instance = nil
+			}
+
+			now := metav1.Now()
+			r := newReconciler(t, server, &apiv1.KeptnService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "podtato-hello",
+					Namespace:         "keptn",
+					DeletionTimestamp: &now,
+					Finalizers:        []string{"keptnservices.keptn.sh/finalizer"},
+					Annotations:       tt.annotations,
+				},
+				Spec:   apiv1.KeptnServiceSpec{Project: "podtato", Service: "hello", DeletionPolicy: tt.deletionPolicy},
+				Status: apiv1.KeptnServiceStatus{Instance: instance},
+			})
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "podtato-hello", Namespace: "keptn"}}
+
+			_, err := r.Reconcile(context.TODO(), req)
+			require.NoError(t, err)
+			require.ElementsMatch(t, tt.wantServices, server.Services("podtato"))
+
+			// the object is gone as soon as the finalizer has been removed
+			err = r.Client.Get(context.TODO(), req.NamespacedName, &apiv1.KeptnService{})
+			if tt.wantFinalizer {
+				require.NoError(t, err)
+			} else {
+				require.True(t, errors.IsNotFound(err))
+			}
+		})
+	}
+}
+
+func TestReconcileOrphanRemovesFinalizer(t *testing.T) {
+	server := keptnfake.NewServer()
+	defer server.Close()
+	server.AddProject("podtato", "dev")
+	server.AddService("podtato", "hello")
+
+	r := newReconciler(t, server, &apiv1.KeptnService{
+		ObjectMeta: metav1.ObjectMeta{Name: "podtato-hello", Namespace: "keptn", Finalizers: []string{"keptnservices.keptn.sh/finalizer"}},
+		Spec:       apiv1.KeptnServiceSpec{Project: "podtato", Service: "hello", DeletionPolicy: apiv1.DeletionPolicyOrphan},
+	})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "podtato-hello", Namespace: "keptn"}}
+
+	_, err := r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	service := &apiv1.KeptnService{}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, service))
+	require.Empty(t, service.Finalizers)
+}
+
+func TestDeleteKeptnServiceNotFound(t *testing.T) {
+	server := keptnfake.NewServer()
+	defer server.Close()
+	server.AddProject("podtato", "dev")
+	r := newReconciler(t, server)
+	r.ReqLogger = ctrl.Log
+
+	// a service which has already been removed from Keptn must not block the deletion
+	require.NoError(t, r.deleteKeptnService(context.TODO(), &apiv1.KeptnService{
+		ObjectMeta: metav1.ObjectMeta{Name: "podtato-hello", Namespace: "keptn"},
+		Spec:       apiv1.KeptnServiceSpec{Project: "podtato", Service: "hello"},
+		Status:     apiv1.KeptnServiceStatus{Instance: &apiv1.KeptnInstanceReference{Name: "default", Namespace: "keptn"}},
+	}))
+}
+
+func TestReconcileDeletionWithoutInstance(t *testing.T) {
+	server := keptnfake.NewServer()
+	defer server.Close()
+	server.AddProject("podtato", "dev")
+	server.AddService("podtato", "hello")
+
+	now := metav1.Now()
+	r := newReconciler(t, server, &apiv1.KeptnService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "podtato-hello",
+			Namespace:         "keptn",
+			DeletionTimestamp: &now,
+			Finalizers:        []string{"keptnservices.keptn.sh/finalizer"},
+		},
+		Spec:   apiv1.KeptnServiceSpec{Project: "podtato", Service: "hello", DeletionPolicy: apiv1.DeletionPolicyDelete},
+		Status: apiv1.KeptnServiceStatus{Instance: &apiv1.KeptnInstanceReference{Name: "default", Namespace: "keptn"}},
+	})
+	require.NoError(t, r.Client.Delete(context.TODO(), &apiv1.KeptnInstance{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "keptn"}}))
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "podtato-hello", Namespace: "keptn"}}
+
+	// the deletion is not blocked by the missing instance, the cleanup is skipped with a warning
+	_, err := r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+	require.True(t, errors.IsNotFound(r.Client.Get(context.TODO(), req.NamespacedName, &apiv1.KeptnService{})))
+	require.Equal(t, []string{"hello"}, server.Services("podtato"))
+
+	event := <-r.Recorder.(*record.FakeRecorder).Events
+	require.Contains(t, event, "Warning CleanupSkipped")
+}
@@ -17,20 +17,18 @@ limitations under the License.
 package keptnservicedeploymentcontroller
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
-	"github.com/keptn/go-utils/pkg/api/models"
-	apiutils "github.com/keptn/go-utils/pkg/api/utils"
-	"io"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
-	nethttp "net/http"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"time"
 
@@ -54,14 +52,18 @@ type KeptnServiceDeploymentReconciler struct {
 	KeptnInstance apiv1.KeptnInstance
 	// KeptnAPIToken contains the API token used in this controller
 	KeptnAPIToken string
+	// KeptnCache contains the cached projects and services of the Keptn instances
+	KeptnCache *keptnclient.Cache
 }
 
 const reconcileErrorInterval = 10 * time.Second
 const reconcileSuccessInterval = 120 * time.Second
+const reconcileProgressInterval = 10 * time.Second
 
 //+kubebuilder:rbac:groups=keptn.sh,resources=keptnservicedeployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=keptn.sh,resources=keptnservicedeployments/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=keptn.sh,resources=keptnservicedeployments/finalizers,verbs=update
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptnslos,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -76,13 +78,6 @@ func (r *KeptnServiceDeploymentReconciler) Reconcile(ctx context.Context, req ct
 	r.ReqLogger = ctrl.Log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
 	r.ReqLogger.Info("Reconciling KeptnSequenceExecution")
 
-	var err error
-	r.KeptnInstance, r.KeptnAPIToken, err = utils.GetKeptnInstance(ctx, r.Client, req.Namespace)
-	if err != nil {
-		r.ReqLogger.Error(err, "Could not get Keptn Instance")
-		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
-	}
-
 	ksd := &apiv1.KeptnServiceDeployment{}
 
 	if err := r.Client.Get(ctx, req.NamespacedName, ksd); err != nil {
@@ -95,10 +90,21 @@ func (r *KeptnServiceDeploymentReconciler) Reconcile(ctx context.Context, req ct
 		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
 	}
 
+	var err error
+	r.KeptnInstance, r.KeptnAPIToken, err = utils.GetKeptnInstanceForProject(ctx, r.Client, ksd.Spec.Project, req.Namespace)
+	if err != nil {
+		r.ReqLogger.Error(err, "Could not get Keptn Instance")
+		if err := conditions.Stalled(ctx, r.Client, ksd, apiv1.ReasonKeptnInstanceNotFound, err.Error()); err != nil {
+			r.ReqLogger.Error(err, "Could not update status of ksd "+ksd.Name)
+		}
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
+
 	if !r.checkKeptnProject(ctx, req, ksd.Spec.Project) {
 		r.Recorder.Event(ksd, "Warning", "KeptnProjectNotFound", fmt.Sprintf("Keptn project %s does not exist", ksd.Spec.Project))
 		ksd.Status.Prerequisites.ProjectExists = false
 		ksd.Status.UpdatePending = true
+		conditions.MarkReconciling(ksd, apiv1.ReasonProjectNotFound, fmt.Sprintf("Waiting for Keptn project %s", ksd.Spec.Project))
 		err := r.Client.Status().Update(ctx, ksd)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not update status of KeptnServiceDeployment "+ksd.Name)
@@ -115,11 +121,12 @@ func (r *KeptnServiceDeploymentReconciler) Reconcile(ctx context.Context, req ct
 		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
 	}
 
-	service, _, serviceExists := r.checkIfServiceExists(ctx, req, ksd.Spec.Project, ksd.Spec.Service)
+	service, serviceExists := r.checkIfServiceExists(ctx, req, ksd.Spec.Project, ksd.Spec.Service)
 	if !serviceExists {
 		r.Recorder.Event(ksd, "Warning", "KeptnServiceNotFound", fmt.Sprintf("Keptn service %s in project %s does not exist", ksd.Spec.Service, ksd.Spec.Project))
 		ksd.Status.Prerequisites.ServiceExists = false
 		ksd.Status.UpdatePending = true
+		conditions.MarkReconciling(ksd, apiv1.ReasonServiceNotFound, fmt.Sprintf("Waiting for Keptn service %s in project %s", ksd.Spec.Service, ksd.Spec.Project))
 		err := r.Client.Status().Update(ctx, ksd)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not update status of ksd "+ksd.Name)
@@ -167,13 +174,17 @@ func (r *KeptnServiceDeploymentReconciler) Reconcile(ctx context.Context, req ct
 	}
 
 	if keptncontext.Status.LastAppliedHash[ksd.Spec.Stage] != utils.GetHashStructure(ksd.Spec) || ksd.Status.UpdatePending {
-		kcontext, err := r.triggerTask(ksd, service.Spec.DeploymentEvent, keptncontext.Status.KeptnContext)
+		kcontext, eventID, err := r.triggerTask(ctx, ksd, service.Spec.DeploymentEvent, keptncontext.Status.KeptnContext)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not trigger task")
+			if err := conditions.Stalled(ctx, r.Client, ksd, apiv1.ReasonKeptnAPIError, "Could not trigger deployment: "+err.Error()); err != nil {
+				r.ReqLogger.Error(err, "Could not update status of ksd "+ksd.Name)
+			}
 			return ctrl.Result{Requeue: true}, err
 		}
 		keptncontext.Status.KeptnContext = kcontext
 		keptncontext.Status.LastAppliedHash[ksd.Spec.Stage] = utils.GetHashStructure(ksd.Spec)
+		conditions.MarkReady(keptncontext, apiv1.ReasonReconciled, "Triggered deployment in stage "+ksd.Spec.Stage)
 		err = r.Client.Status().Update(ctx, keptncontext)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not update status of ksd "+ksd.Name)
@@ -181,16 +192,82 @@ func (r *KeptnServiceDeploymentReconciler) Reconcile(ctx context.Context, req ct
 
 		ksd.Status.UpdatePending = false
 		ksd.Status.KeptnContext = kcontext
+		ksd.Status.TriggeredEventID = eventID
 		ksd.Status.LastAppliedHash = utils.GetHashStructure(ksd.Spec)
+		ksd.Status.DeploymentProgress = apiv1.KeptnServiceDeploymentProgress{
+			ArtifactAvailable:   ksd.Status.DeploymentProgress.ArtifactAvailable,
+			DeploymentTriggered: true,
+			SequenceState:       sequenceStateTriggered,
+		}
+		conditions.MarkReconciling(ksd, apiv1.ReasonProgressing, "Triggered deployment with Keptn context "+kcontext)
 		err = r.Client.Status().Update(ctx, ksd)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not update status of ksd "+ksd.Name)
 		}
+		return ctrl.Result{RequeueAfter: reconcileProgressInterval}, nil
+	}
+
+	if ksd.Status.KeptnContext != "" && ksd.Status.DeploymentProgress.SequenceState != sequenceStateFinished {
+		return r.updateProgress(ctx, ksd)
 	}
 	r.ReqLogger.Info("Finished Reconciling KeptnSequenceExecution")
 	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 }
 
+//updateProgress polls the state of the sequence triggered by the KeptnServiceDeployment and updates its status
+func (r *KeptnServiceDeploymentReconciler) updateProgress(ctx context.Context, ksd *apiv1.KeptnServiceDeployment) (ctrl.Result, error) {
+	progress, err := r.getSequenceProgress(ctx, ksd.Spec.Project, ksd.Spec.Stage, ksd.Status.KeptnContext, ksd.Status.TriggeredEventID)
+	if err != nil {
+		r.ReqLogger.Error(err, "Could not get progress of ksd "+ksd.Name)
+		return ctrl.Result{RequeueAfter: reconcileErrorInterval}, nil
+	}
+	progress.ArtifactAvailable = ksd.Status.DeploymentProgress.ArtifactAvailable
+	if progress.SequenceState == "" {
+		// the events of the triggered sequence have not been stored yet
+		progress.SequenceState = sequenceStateTriggered
+	}
+	switch {
+	case progress.EvaluationScore == "":
+	case ksd.Status.DeploymentProgress.SLO != nil:
+		// the SLO is resolved once when the evaluation result appears, later uploads have not been evaluated
+		progress.SLO = ksd.Status.DeploymentProgress.SLO
+	default:
+		progress.SLO, err = r.getEvaluatedSLO(ctx, ksd)
+		if err != nil {
+			r.ReqLogger.Error(err, "Could not get SLO of ksd "+ksd.Name)
+			return ctrl.Result{RequeueAfter: reconcileErrorInterval}, nil
+		}
+	}
+
+	if equality.Semantic.DeepEqual(progress, ksd.Status.DeploymentProgress) {
+		return ctrl.Result{RequeueAfter: reconcileProgressInterval}, nil
+	}
+	ksd.Status.DeploymentProgress = progress
+
+	result := ctrl.Result{RequeueAfter: reconcileProgressInterval}
+	switch {
+	case progress.SequenceState != sequenceStateFinished:
+		conditions.MarkReconciling(ksd, apiv1.ReasonProgressing, fmt.Sprintf("Sequence has been %s in stage %s", progress.SequenceState, ksd.Spec.Stage))
+	case progress.Result == resultFail:
+		r.Recorder.Event(ksd, "Warning", "DeploymentFailed", fmt.Sprintf("Deployment of %s:%s in stage %s failed (Keptn context: %s)", ksd.Spec.Service, ksd.Spec.Version, ksd.Spec.Stage, ksd.Status.KeptnContext))
+		conditions.MarkStalled(ksd, apiv1.ReasonDeploymentFailed, fmt.Sprintf("Sequence finished with result %s in stage %s", progress.Result, ksd.Spec.Stage))
+		result = ctrl.Result{RequeueAfter: reconcileSuccessInterval}
+	default:
+		ksd.Status.DeployedVersion = ksd.Spec.Version
+		ksd.Status.DeployedConfigVersion = getConfigVersion(ksd)
+		r.Recorder.Event(ksd, "Normal", "Deployed", fmt.Sprintf("Deployed %s:%s in stage %s (Result: %s)", ksd.Spec.Service, ksd.Spec.Version, ksd.Spec.Stage, progress.Result))
+		conditions.MarkReady(ksd, apiv1.ReasonReconciled, fmt.Sprintf("Sequence finished with result %s in stage %s", progress.Result, ksd.Spec.Stage))
+		result = ctrl.Result{RequeueAfter: reconcileSuccessInterval}
+	}
+
+	err = r.Client.Status().Update(ctx, ksd)
+	if err != nil {
+		r.ReqLogger.Error(err, "Could not update status of ksd "+ksd.Name)
+		return ctrl.Result{RequeueAfter: reconcileErrorInterval}, err
+	}
+	return result, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *KeptnServiceDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -209,44 +286,21 @@ func (r *KeptnServiceDeploymentReconciler) checkKeptnProject(ctx context.Context
 	return true
 }
 
-func (r *KeptnServiceDeploymentReconciler) checkIfServiceExists(ctx context.Context, req ctrl.Request, project string, service string) (kservice apiv1.KeptnService, stages []*models.Stage, exists bool) {
+func (r *KeptnServiceDeploymentReconciler) checkIfServiceExists(ctx context.Context, req ctrl.Request, project string, service string) (kservice apiv1.KeptnService, exists bool) {
 	serviceRes, err := r.servicesList(ctx, req, project, service)
 	if err != nil {
-		return serviceRes, nil, false
+		return serviceRes, false
 	}
 
-	projectsHandler := apiutils.NewAuthenticatedProjectHandler(r.KeptnInstance.Spec.APIUrl, r.KeptnAPIToken, r.KeptnInstance.Status.AuthHeader, nil, r.KeptnInstance.Status.Scheme)
-	servicesHandler := apiutils.NewAuthenticatedServiceHandler(r.KeptnInstance.Spec.APIUrl, r.KeptnAPIToken, r.KeptnInstance.Status.AuthHeader, nil, r.KeptnInstance.Status.Scheme)
-
-	projects, err := projectsHandler.GetAllProjects()
+	exists, err = r.KeptnCache.ServiceExists(ctx, keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnAPIToken), project, service)
 	if err != nil {
-		return serviceRes, nil, false
-	}
-
-	filteredProjects := utils.FilterProjects(projects, project)
-	if len(filteredProjects) == 0 {
-		if project != "" {
-			r.ReqLogger.Info(fmt.Sprintf("No project %s found", project))
-			return serviceRes, nil, false
-		}
-		return serviceRes, nil, false
+		r.ReqLogger.Error(err, fmt.Sprintf("Could not check if service %s exists in project %s", service, project))
+		return serviceRes, false
 	}
-
-	for _, proj := range filteredProjects {
-		for _, stage := range proj.Stages {
-			services, err := servicesHandler.GetAllServices(proj.ProjectName, stage.StageName)
-			if err != nil {
-				return serviceRes, nil, false
-			}
-			filteredServices := utils.FilterServices(services, service)
-			if len(filteredServices) == 0 {
-				r.ReqLogger.Info(fmt.Sprintf("No services %s found in project %s", service, project))
-				return serviceRes, nil, false
-			}
-			return serviceRes, proj.Stages, true
-		}
+	if !exists {
+		r.ReqLogger.Info(fmt.Sprintf("No services %s found in project %s", service, project))
 	}
-	return serviceRes, nil, false
+	return serviceRes, exists
 }
 
 func getKeptnContext(client client.Client, ctx context.Context, namespace string, project string, service string, version string) (*apiv1.KeptnDeploymentContext, bool, error) {
@@ -261,16 +315,10 @@ func getKeptnContext(client client.Client, ctx context.Context, namespace string
 	return found, false, nil
 }
 
-func (r *KeptnServiceDeploymentReconciler) triggerTask(deployment *apiv1.KeptnServiceDeployment, deploymentEvent string, shkeptncontext string) (string, error) {
-	configVersion := "0"
-
-	if deployment.Spec.ConfigVersion != "" {
-		configVersion = deployment.Spec.ConfigVersion
-	}
-
+func (r *KeptnServiceDeploymentReconciler) triggerTask(ctx context.Context, deployment *apiv1.KeptnServiceDeployment, deploymentEvent string, shkeptncontext string) (string, string, error) {
 	labels := map[string]string{
 		"version":       deployment.Spec.Version,
-		"configVersion": configVersion,
+		"configVersion": getConfigVersion(deployment),
 	}
 
 	if len(deployment.Spec.Labels) != 0 {
@@ -279,61 +327,36 @@ func (r *KeptnServiceDeploymentReconciler) triggerTask(deployment *apiv1.KeptnSe
 		}
 	}
 
-	httpclient := nethttp.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	event := KeptnTriggerEvent{
+	// the deployment event already contains the phase of the event, e.g. delivery.triggered
+	event := keptnclient.Event{
+		ID:          uuid.New().String(),
 		ContentType: "application/json",
-		Data: KeptnEventData{
+		Data: keptnclient.EventData{
 			Service: deployment.Spec.Service,
 			Project: deployment.Spec.Project,
 			Stage:   deployment.Spec.Stage,
 			Labels:  labels,
 			Image:   deployment.Spec.Service + ":" + deployment.Spec.Version,
 		},
-		Source:      "Keptn GitOps Operator",
+		Source:      keptnclient.EventSource,
 		SpecVersion: "1.0",
 		Type:        "sh.keptn.event." + deployment.Spec.Stage + "." + deploymentEvent,
+		Context:     shkeptncontext,
 	}
 
-	if shkeptncontext != "" {
-		event.Context = shkeptncontext
-	}
-
-	data, err := json.Marshal(event)
-	if err != nil {
-		r.ReqLogger.Info("Could not marshal Keptn Trigger Event")
-	}
-
-	r.ReqLogger.Info("Triggering Event sh.keptn.event." + deployment.Spec.Stage + "." + deploymentEvent + " for service " + deployment.Spec.Service)
-	request, err := nethttp.NewRequest("POST", r.KeptnInstance.Spec.APIUrl+"/v1/event", bytes.NewBuffer(data))
-	if err != nil {
-		r.ReqLogger.Error(err, "Could not trigger event "+deployment.Spec.Stage+"."+deploymentEvent+" for service "+deployment.Spec.Service)
-		return "", err
-	}
-
-	request.Header.Set("content-type", "application/cloudevents+json")
-	request.Header.Set(r.KeptnInstance.Status.AuthHeader, r.KeptnAPIToken)
-
-	response, err := httpclient.Do(request)
+	r.ReqLogger.Info("Triggering Event " + event.Type + " for service " + deployment.Spec.Service)
+	kcontext, err := keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnAPIToken).SendEvent(ctx, event)
 	if err != nil {
-		return "", err
+		return "", "", fmt.Errorf("could not trigger event %s for service %s: %w", event.Type, deployment.Spec.Service, err)
 	}
+	return kcontext, event.ID, nil
+}
 
-	err = utils.CheckResponseCode(response, nethttp.StatusOK)
-	if err != nil {
-		return "", err
-	}
-
-	respBody, err := io.ReadAll(response.Body)
-	kcontext := &CreateEventResponse{}
-
-	err = json.Unmarshal(respBody, kcontext)
-	if err != nil {
-		return "", err
+func getConfigVersion(deployment *apiv1.KeptnServiceDeployment) string {
+	if deployment.Spec.ConfigVersion != "" {
+		return deployment.Spec.ConfigVersion
 	}
-	return kcontext.KeptnContext, err
+	return "0"
 }
 
 func (r *KeptnServiceDeploymentReconciler) servicesList(ctx context.Context, req ctrl.Request, project string, service string) (apiv1.KeptnService, error) {
@@ -0,0 +1,127 @@
+package keptnservicedeploymentcontroller
+
+import (
+	"context"
+	"fmt"
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
+	"github.com/keptn/go-utils/pkg/api/models"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	eventTypePrefix = "sh.keptn.event."
+
+	sequenceStateTriggered = "triggered"
+	sequenceStateStarted   = "started"
+	sequenceStateFinished  = "finished"
+
+	resultFail = "fail"
+)
+
+// progressEventData contains the fields of a Keptn event which are needed to track the progress of a sequence
+type progressEventData struct {
+	Stage      string `json:"stage"`
+	Result     string `json:"result,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Evaluation struct {
+		Score float64 `json:"score"`
+	} `json:"evaluation,omitempty"`
+}
+
+//getSequenceProgress fetches the events of a Keptn context and computes the progress of the sequence in the given stage
+//which has been triggered by the event with the given id
+func (r *KeptnServiceDeploymentReconciler) getSequenceProgress(ctx context.Context, project string, stage string, keptnContext string, triggeredEventID string) (apiv1.KeptnServiceDeploymentProgress, error) {
+	events, err := keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnAPIToken).GetEvents(ctx, keptnclient.EventFilter{
+		Project:      project,
+		KeptnContext: keptnContext,
+	})
+	if err != nil {
+		return apiv1.KeptnServiceDeploymentProgress{}, fmt.Errorf("could not get events of keptn context %s: %w", keptnContext, err)
+	}
+	return sequenceProgress(events, stage, triggeredEventID), nil
+}
+
+//sequenceProgress computes the state of the sequence and its tasks in a stage from the events of a Keptn context. A Keptn
+//context is reused by further deployments of the same version, so only the events since the event with triggeredEventID
+//are taken into account, all events are used if it is empty
+func sequenceProgress(events []*models.KeptnContextExtendedCE, stage string, triggeredEventID string) apiv1.KeptnServiceDeploymentProgress {
+	sorted := make([]*models.KeptnContextExtendedCE, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	progress := apiv1.KeptnServiceDeploymentProgress{DeploymentTriggered: true}
+	if triggeredEventID != "" {
+		start := -1
+		for i, event := range sorted {
+			if event.ID == triggeredEventID {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			// the triggering event has not been stored yet
+			return progress
+		}
+		sorted = sorted[start:]
+	}
+
+	for _, event := range sorted {
+		if event.Type == nil {
+			continue
+		}
+		name, phase := splitEventType(*event.Type)
+		if phase != sequenceStateTriggered && phase != sequenceStateStarted && phase != sequenceStateFinished {
+			continue
+		}
+
+		data := progressEventData{}
+		if err := event.DataAs(&data); err != nil || data.Stage != stage {
+			continue
+		}
+
+		// sequence events are named <stage>.<sequence>, task events only <task>
+		if strings.HasPrefix(name, stage+".") {
+			progress.SequenceState = phase
+			if phase == sequenceStateFinished {
+				progress.Result = data.Result
+			}
+			continue
+		}
+
+		task := findTask(&progress, name)
+		task.State = phase
+		if phase == sequenceStateFinished {
+			task.Result = data.Result
+			task.Status = data.Status
+			if name == "evaluation" {
+				progress.EvaluationScore = strconv.FormatFloat(data.Evaluation.Score, 'f', -1, 64)
+			}
+		}
+	}
+	return progress
+}
+
+//splitEventType splits a Keptn event type into its name and phase, e.g. sh.keptn.event.deployment.finished into deployment and finished
+func splitEventType(eventType string) (string, string) {
+	name := strings.TrimPrefix(eventType, eventTypePrefix)
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return name, ""
+	}
+	return name[:i], name[i+1:]
+}
+
+func findTask(progress *apiv1.KeptnServiceDeploymentProgress, name string) *apiv1.KeptnTaskProgress {
+	for i := range progress.Tasks {
+		if progress.Tasks[i].Name == name {
+			return &progress.Tasks[i]
+		}
+	}
+	progress.Tasks = append(progress.Tasks, apiv1.KeptnTaskProgress{Name: name})
+	return &progress.Tasks[len(progress.Tasks)-1]
+}
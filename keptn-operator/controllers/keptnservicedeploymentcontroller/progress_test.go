@@ -0,0 +1,112 @@
+package keptnservicedeploymentcontroller
+
+import (
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newEvent(eventType string, minute int, data map[string]interface{}) *models.KeptnContextExtendedCE {
+	return &models.KeptnContextExtendedCE{
+		Type: &eventType,
+		Time: time.Date(2022, 1, 1, 10, minute, 0, 0, time.UTC),
+		Data: data,
+	}
+}
+
+func TestSequenceProgress(t *testing.T) {
+	dev := func(fields map[string]interface{}) map[string]interface{} {
+		data := map[string]interface{}{"stage": "dev"}
+		for k, v := range fields {
+			data[k] = v
+		}
+		return data
+	}
+
+	tests := []struct {
+		name   string
+		events []*models.KeptnContextExtendedCE
+		want   apiv1.KeptnServiceDeploymentProgress
+	}{
+		{
+			name:   "no events",
+			events: nil,
+			want:   apiv1.KeptnServiceDeploymentProgress{DeploymentTriggered: true},
+		},
+		{
+			name: "running",
+			events: []*models.KeptnContextExtendedCE{
+				newEvent("sh.keptn.event.deployment.started", 2, dev(nil)),
+				newEvent("sh.keptn.event.dev.delivery.triggered", 0, dev(nil)),
+				newEvent("sh.keptn.event.deployment.triggered", 1, dev(nil)),
+			},
+			want: apiv1.KeptnServiceDeploymentProgress{
+				DeploymentTriggered: true,
+				SequenceState:       "triggered",
+				Tasks:               []apiv1.KeptnTaskProgress{{Name: "deployment", State: "started"}},
+			},
+		},
+		{
+			name: "finished",
+			events: []*models.KeptnContextExtendedCE{
+				newEvent("sh.keptn.event.dev.delivery.triggered", 0, dev(nil)),
+				newEvent("sh.keptn.event.deployment.triggered", 1, dev(nil)),
+				newEvent("sh.keptn.event.deployment.finished", 2, dev(map[string]interface{}{"result": "pass", "status": "succeeded"})),
+				newEvent("sh.keptn.event.evaluation.triggered", 3, dev(nil)),
+				newEvent("sh.keptn.event.evaluation.finished", 4, dev(map[string]interface{}{"result": "warning", "status": "succeeded", "evaluation": map[string]interface{}{"score": 75.5}})),
+				newEvent("sh.keptn.event.dev.delivery.finished", 5, dev(map[string]interface{}{"result": "warning", "status": "succeeded"})),
+				// events of other stages of the same context are ignored
+				newEvent("sh.keptn.event.production.delivery.triggered", 6, map[string]interface{}{"stage": "production"}),
+				newEvent("sh.keptn.event.deployment.triggered", 7, map[string]interface{}{"stage": "production"}),
+			},
+			want: apiv1.KeptnServiceDeploymentProgress{
+				DeploymentTriggered: true,
+				SequenceState:       "finished",
+				Result:              "warning",
+				EvaluationScore:     "75.5",
+				Tasks: []apiv1.KeptnTaskProgress{
+					{Name: "deployment", State: "finished", Result: "pass", Status: "succeeded"},
+					{Name: "evaluation", State: "finished", Result: "warning", Status: "succeeded"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, sequenceProgress(tt.events, "dev", ""))
+		})
+	}
+}
+
+func TestSequenceProgressOfSecondRun(t *testing.T) {
+	dev := map[string]interface{}{"stage": "dev"}
+	passed := map[string]interface{}{"stage": "dev", "result": "pass", "status": "succeeded"}
+	withID := func(event *models.KeptnContextExtendedCE, id string) *models.KeptnContextExtendedCE {
+		event.ID = id
+		return event
+	}
+
+	// both runs share the Keptn context
+	firstRun := []*models.KeptnContextExtendedCE{
+		withID(newEvent("sh.keptn.event.dev.delivery.triggered", 0, dev), "first"),
+		newEvent("sh.keptn.event.deployment.triggered", 1, dev),
+		newEvent("sh.keptn.event.deployment.finished", 2, passed),
+		newEvent("sh.keptn.event.evaluation.finished", 3, map[string]interface{}{"stage": "dev", "result": "pass", "evaluation": map[string]interface{}{"score": 100}}),
+		newEvent("sh.keptn.event.dev.delivery.finished", 4, passed),
+	}
+
+	// the event triggering the second run has not been stored yet
+	require.Equal(t, apiv1.KeptnServiceDeploymentProgress{DeploymentTriggered: true}, sequenceProgress(firstRun, "dev", "second"))
+
+	secondRun := append(firstRun,
+		withID(newEvent("sh.keptn.event.dev.delivery.triggered", 10, dev), "second"),
+		newEvent("sh.keptn.event.deployment.triggered", 11, dev),
+	)
+	require.Equal(t, apiv1.KeptnServiceDeploymentProgress{
+		DeploymentTriggered: true,
+		SequenceState:       "triggered",
+		Tasks:               []apiv1.KeptnTaskProgress{{Name: "deployment", State: "triggered"}},
+	}, sequenceProgress(secondRun, "dev", "second"))
+}
@@ -0,0 +1,33 @@
+package keptnservicedeploymentcontroller
+
+import (
+	"context"
+	"fmt"
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//getEvaluatedSLO returns the KeptnSLO which has been uploaded to the stage of the KeptnServiceDeployment, an SLO of the
+//stage takes precedence over an SLO of all stages. Nil is returned if the SLO of the service is not managed by a KeptnSLO
+func (r *KeptnServiceDeploymentReconciler) getEvaluatedSLO(ctx context.Context, ksd *apiv1.KeptnServiceDeployment) (*apiv1.KeptnSLOReference, error) {
+	slos := &apiv1.KeptnSLOList{}
+	if err := r.Client.List(ctx, slos, client.InNamespace(ksd.Namespace)); err != nil {
+		return nil, fmt.Errorf("could not list KeptnSLOs: %w", err)
+	}
+
+	var result *apiv1.KeptnSLOReference
+	for _, slo := range slos.Items {
+		if slo.Spec.Project != ksd.Spec.Project || slo.Spec.Service != ksd.Spec.Service || !utils.ContainsString(slo.Status.Stages, ksd.Spec.Stage) {
+			continue
+		}
+		ref := &apiv1.KeptnSLOReference{Name: slo.Name, Generation: slo.Status.UploadedGeneration}
+		if slo.Spec.Stage == ksd.Spec.Stage {
+			return ref, nil
+		}
+		if result == nil {
+			result = ref
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,117 @@
+package keptnservicedeploymentcontroller
+
+import (
+	"context"
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	keptnfake "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient/fake"
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestGetEvaluatedSLO(t *testing.T) {
+	slo := func(name, service, stage string, generation int64, stages ...string) client.Object {
+		return &apiv1.KeptnSLO{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "keptn"},
+			Spec:       apiv1.KeptnSLOSpec{Project: "podtato", Service: service, Stage: stage},
+			Status:     apiv1.KeptnSLOStatus{Stages: stages, UploadedGeneration: generation, ObservedGeneration: generation + 1},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		stage string
+		want  *apiv1.KeptnSLOReference
+	}{
+		{name: "stage_slo", stage: "hardening", want: &apiv1.KeptnSLOReference{Name: "hello-hardening", Generation: 3}},
+		{name: "all_stages_slo", stage: "dev", want: &apiv1.KeptnSLOReference{Name: "hello", Generation: 1}},
+		{name: "not_uploaded", stage: "production"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, apiv1.AddToScheme(scheme))
+			r := &KeptnServiceDeploymentReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+					slo("hello", "hello", "", 1, "dev", "hardening"),
+					slo("hello-hardening", "hello", "hardening", 3, "hardening"),
+					slo("other", "other", "", 1, "dev", "hardening", "production"),
+				).Build(),
+				Scheme: scheme,
+			}
+
+			got, err := r.getEvaluatedSLO(context.TODO(), &apiv1.KeptnServiceDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: "keptn"},
+				Spec:       apiv1.KeptnServiceDeploymentSpec{Project: "podtato", Service: "hello", Stage: tt.stage},
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUpdateProgressKeepsEvaluatedSLO(t *testing.T) {
+	tests := []struct {
+		name      string
+		evaluated *apiv1.KeptnSLOReference
+		want      *apiv1.KeptnSLOReference
+	}{
+		{name: "first_evaluation_result", want: &apiv1.KeptnSLOReference{Name: "hello", Generation: 2}},
+		// the SLO has been uploaded again after the evaluation
+		{name: "later_upload", evaluated: &apiv1.KeptnSLOReference{Name: "hello", Generation: 1}, want: &apiv1.KeptnSLOReference{Name: "hello", Generation: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := keptnfake.NewServer()
+			defer server.Close()
+			data := map[string]interface{}{"project": "podtato", "stage": "dev", "result": "pass", "evaluation": map[string]interface{}{"score": 100}}
+			for _, event := range []*models.KeptnContextExtendedCE{
+				newEvent("sh.keptn.event.dev.delivery.triggered", 0, map[string]interface{}{"project": "podtato", "stage": "dev"}),
+				newEvent("sh.keptn.event.evaluation.finished", 1, data),
+			} {
+				event.Shkeptncontext = "context"
+				server.AddEvent(event)
+			}
+
+			scheme := runtime.NewScheme()
+			require.NoError(t, apiv1.AddToScheme(scheme))
+			ksd := &apiv1.KeptnServiceDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: "keptn"},
+				Spec:       apiv1.KeptnServiceDeploymentSpec{Project: "podtato", Service: "hello", Stage: "dev"},
+				Status: apiv1.KeptnServiceDeploymentStatus{
+					KeptnContext:       "context",
+					DeploymentProgress: apiv1.KeptnServiceDeploymentProgress{SLO: tt.evaluated},
+				},
+			}
+			r := &KeptnServiceDeploymentReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+					ksd,
+					&apiv1.KeptnSLO{
+						ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: "keptn"},
+						Spec:       apiv1.KeptnSLOSpec{Project: "podtato", Service: "hello"},
+						Status:     apiv1.KeptnSLOStatus{Stages: []string{"dev"}, UploadedGeneration: 2, ObservedGeneration: 2},
+					},
+				).Build(),
+				Scheme:        scheme,
+				Recorder:      record.NewFakeRecorder(10),
+				ReqLogger:     ctrl.Log,
+				KeptnInstance: apiv1.KeptnInstance{Spec: apiv1.KeptnInstanceSpec{APIUrl: server.URL}},
+				KeptnAPIToken: server.Token,
+			}
+
+			_, err := r.updateProgress(context.TODO(), ksd)
+			require.NoError(t, err)
+
+			require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "hello", Namespace: "keptn"}, ksd))
+			require.Equal(t, "100", ksd.Status.DeploymentProgress.EvaluationScore)
+			require.Equal(t, tt.want, ksd.Status.DeploymentProgress.SLO)
+		})
+	}
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keptnserviceresourcecontroller
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"time"
+
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// KeptnServiceResourceReconciler reconciles a KeptnServiceResource object
+type KeptnServiceResourceReconciler struct {
+	client.Client
+
+	// Scheme contains the scheme of this controller
+	Scheme *runtime.Scheme
+	// Recorder contains the Recorder of this controller
+	Recorder record.EventRecorder
+	// ReqLogger contains the Logger of this controller
+	ReqLogger logr.Logger
+	// KeptnInstance contains the Information about the KeptnInstance of this controller
+	KeptnInstance apiv1.KeptnInstance
+	// KeptnAPIToken contains the API token used in this controller
+	KeptnAPIToken string
+	// KeptnCache contains the cached projects and services of the Keptn instances
+	KeptnCache *keptnclient.Cache
+}
+
+const reconcileErrorInterval = 10 * time.Second
+const reconcileSuccessInterval = 120 * time.Second
+
+const resourceFinalizerName = "keptnserviceresources.keptn.sh/finalizer"
+
+//appliedResource contains everything which is uploaded to Keptn, a change of its hash triggers a new upload
+type appliedResource struct {
+	Spec    apiv1.KeptnServiceResourceSpec
+	Content string
+	Stages  []string
+}
+
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptnserviceresources,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptnserviceresources/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptnserviceresources/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+
+// Reconcile uploads the content of a KeptnServiceResource to the service in Keptn whenever the content, the target or
+// the stages of the project change, and deletes the resource in Keptn when the KeptnServiceResource is deleted
+func (r *KeptnServiceResourceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.ReqLogger = ctrl.Log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	r.ReqLogger.Info("Reconciling KeptnServiceResource")
+
+	resource := &apiv1.KeptnServiceResource{}
+	if err := r.Client.Get(ctx, req.NamespacedName, resource); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		r.ReqLogger.Error(err, "Failed to get the KeptnServiceResource")
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
+
+	if !resource.DeletionTimestamp.IsZero() {
+		if !utils.ContainsString(resource.GetFinalizers(), resourceFinalizerName) {
+			return ctrl.Result{}, nil
+		}
+		if err := r.deletePreviousResource(ctx, resource, nil); err != nil {
+			r.ReqLogger.Error(err, "Could not delete resource "+resource.Status.ResourceURI)
+			return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+		}
+		controllerutil.RemoveFinalizer(resource, resourceFinalizerName)
+		if err := r.Update(ctx, resource); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !utils.ContainsString(resource.GetFinalizers(), resourceFinalizerName) {
+		controllerutil.AddFinalizer(resource, resourceFinalizerName)
+		if err := r.Update(ctx, resource); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	content, err := r.getContent(ctx, resource)
+	if err != nil {
+		r.ReqLogger.Error(err, "Could not get content of resource "+resource.Spec.ResourceURI)
+		r.setStalled(ctx, resource, apiv1.ReasonInvalidSpec, err.Error())
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
+
+	r.KeptnInstance, r.KeptnAPIToken, err = utils.GetKeptnInstanceForProject(ctx, r.Client, resource.Spec.Project, resource.Namespace)
+	if err != nil {
+		r.ReqLogger.Error(err, "Could not get Keptn Instance")
+		r.setStalled(ctx, resource, apiv1.ReasonKeptnInstanceNotFound, err.Error())
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
+	keptnClient := keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnAPIToken)
+
+	serviceExists, err := r.KeptnCache.ServiceExists(ctx, keptnClient, resource.Spec.Project, resource.Spec.Service)
+	if err != nil {
+		r.setStalled(ctx, resource, apiv1.ReasonKeptnAPIError, err.Error())
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
+	if !serviceExists {
+		message := fmt.Sprintf("Waiting for service %s in Keptn project %s", resource.Spec.Service, resource.Spec.Project)
+		if err := conditions.Reconciling(ctx, r.Client, resource, apiv1.ReasonServiceNotFound, message); err != nil {
+			r.ReqLogger.Error(err, "Could not update status of resource "+resource.Name)
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	stages, err := r.getStages(ctx, keptnClient, resource)
+	if err != nil {
+		r.setStalled(ctx, resource, apiv1.ReasonKeptnAPIError, err.Error())
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
+
+	hash := utils.GetHashStructure(appliedResource{Spec: resource.Spec, Content: content, Stages: stages})
+	if hash == resource.Status.LastAppliedHash && conditions.IsReady(resource) {
+		return ctrl.Result{RequeueAfter: reconcileSuccessInterval}, nil
+	}
+
+	for _, stage := range stages {
+		err := keptnClient.UploadServiceResource(ctx, resource.Spec.Project, stage, resource.Spec.Service, resource.Spec.ResourceURI, []byte(content))
+		if err != nil {
+			r.ReqLogger.Error(err, "Could not upload resource "+resource.Spec.ResourceURI)
+			r.Recorder.Event(resource, "Warning", "ResourceUploadFailed", fmt.Sprintf("Could not upload %s to stage %s: %v", resource.Spec.ResourceURI, stage, err))
+			r.setStalled(ctx, resource, apiv1.ReasonKeptnAPIError, err.Error())
+			return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+		}
+	}
+
+	// the resource is removed from stages which are no longer targeted and from its previous location
+	if err := r.deletePreviousResource(ctx, resource, stages); err != nil {
+		r.ReqLogger.Error(err, "Could not delete previous resource "+resource.Status.ResourceURI)
+		r.setStalled(ctx, resource, apiv1.ReasonKeptnAPIError, err.Error())
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
+
+	r.Recorder.Event(resource, "Normal", "ResourceUploaded", fmt.Sprintf("Uploaded %s of service %s to stages %v", resource.Spec.ResourceURI, resource.Spec.Service, stages))
+	resource.Status.LastAppliedHash = hash
+	resource.Status.Instance = r.KeptnInstance.Reference()
+	resource.Status.Project = resource.Spec.Project
+	resource.Status.Service = resource.Spec.Service
+	resource.Status.ResourceURI = resource.Spec.ResourceURI
+	resource.Status.Stages = stages
+	conditions.MarkReady(resource, apiv1.ReasonReconciled, fmt.Sprintf("Resource has been uploaded to %d stage(s)", len(stages)))
+	if err := r.Client.Status().Update(ctx, resource); err != nil {
+		r.ReqLogger.Error(err, "Could not update status of resource "+resource.Name)
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
+	}
+
+	r.ReqLogger.Info("Finished Reconciling KeptnServiceResource")
+	return ctrl.Result{RequeueAfter: reconcileSuccessInterval}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KeptnServiceResourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&apiv1.KeptnServiceResource{}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.resourcesOfConfigMap)).
+		Complete(r)
+}
+
+//resourcesOfConfigMap maps a ConfigMap to the KeptnServiceResources in its namespace which take their content from it,
+//so changed content is uploaded right away
+func (r *KeptnServiceResourceReconciler) resourcesOfConfigMap(obj client.Object) []reconcile.Request {
+	resources := &apiv1.KeptnServiceResourceList{}
+	if err := r.Client.List(context.TODO(), resources, client.InNamespace(obj.GetNamespace())); err != nil {
+		ctrl.Log.Error(err, "Could not list the KeptnServiceResources referencing ConfigMap "+obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, resource := range resources.Items {
+		if resource.Spec.ContentRef != nil && resource.Spec.ContentRef.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: resource.Name, Namespace: resource.Namespace}})
+		}
+	}
+	return requests
+}
+
+//getContent returns the inline content of the resource or reads it from the referenced ConfigMap
+func (r *KeptnServiceResourceReconciler) getContent(ctx context.Context, resource *apiv1.KeptnServiceResource) (string, error) {
+	if resource.Spec.Content != "" {
+		return resource.Spec.Content, nil
+	}
+
+	ref := resource.Spec.ContentRef
+	if ref == nil {
+		return "", fmt.Errorf("neither content nor contentRef is set")
+	}
+	configMap := &corev1.ConfigMap{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: resource.Namespace}, configMap)
+	if err != nil {
+		return "", fmt.Errorf("could not get ConfigMap %s containing the resource: %w", ref.Name, err)
+	}
+	content, ok := configMap.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("ConfigMap %s does not contain the key %s", ref.Name, ref.Key)
+	}
+	return content, nil
+}
+
+//getStages returns the stage of the resource, or all stages of the project if none is set
+func (r *KeptnServiceResourceReconciler) getStages(ctx context.Context, keptnClient *keptnclient.Client, resource *apiv1.KeptnServiceResource) ([]string, error) {
+	if resource.Spec.Stage != "" {
+		return []string{resource.Spec.Stage}, nil
+	}
+
+	project, err := r.KeptnCache.Project(ctx, keptnClient, resource.Spec.Project)
+	if err != nil {
+		return nil, fmt.Errorf("could not get stages of project %s: %w", resource.Spec.Project, err)
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project %s does not exist", resource.Spec.Project)
+	}
+	stages := []string{}
+	for _, stage := range project.Stages {
+		stages = append(stages, stage.StageName)
+	}
+	return stages, nil
+}
+
+//deletePreviousResource deletes the resource from the Keptn instance, project, service and stages it has been uploaded to.
+//The stages in keep are skipped if the resource is still uploaded to the same location of the current Keptn instance.
+//Nothing is deleted if the recorded Keptn instance can't be resolved or the project doesn't exist anymore, resources which
+//don't exist anymore are ignored
+func (r *KeptnServiceResourceReconciler) deletePreviousResource(ctx context.Context, resource *apiv1.KeptnServiceResource, keep []string) error {
+	// the status of resources uploaded by previous versions does not contain the project and service
+	project, service := resource.Status.Project, resource.Status.Service
+	if project == "" {
+		project, service = resource.Spec.Project, resource.Spec.Service
+	}
+	sameInstance := resource.Status.Instance == nil || *resource.Status.Instance == *r.KeptnInstance.Reference()
+	if !sameInstance || project != resource.Spec.Project || service != resource.Spec.Service || resource.Status.ResourceURI != resource.Spec.ResourceURI {
+		keep = nil
+	}
+
+	var stages []string
+	for _, stage := range resource.Status.Stages {
+		if !utils.ContainsString(keep, stage) {
+			stages = append(stages, stage)
+		}
+	}
+	if len(stages) == 0 {
+		return nil
+	}
+
+	instance, token, err := utils.GetRecordedKeptnInstance(ctx, r.Client, resource.Status.Instance, resource.Namespace)
+	if utils.IsKeptnInstanceUnavailable(err) {
+		r.Recorder.Event(resource, "Warning", "CleanupSkipped", fmt.Sprintf("%s has not been deleted in Keptn: %s", resource.Status.ResourceURI, err.Error()))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	keptnClient := keptnclient.NewClientForInstance(instance, token)
+
+	projectExists, err := r.KeptnCache.ProjectExists(ctx, keptnClient, project)
+	if err != nil {
+		return fmt.Errorf("could not check if project %s exists: %w", project, err)
+	}
+	if !projectExists {
+		r.ReqLogger.Info("Project " + project + " does not exist anymore, skipping deletion of " + resource.Status.ResourceURI)
+		return nil
+	}
+
+	for _, stage := range stages {
+		err := keptnClient.DeleteServiceResource(ctx, project, stage, service, resource.Status.ResourceURI)
+		if err != nil && !keptnclient.IsNotFound(err) {
+			return fmt.Errorf("could not delete %s from stage %s: %w", resource.Status.ResourceURI, stage, err)
+		}
+	}
+	return nil
+}
+
+func (r *KeptnServiceResourceReconciler) setStalled(ctx context.Context, resource *apiv1.KeptnServiceResource, reason, message string) {
+	if err := conditions.Stalled(ctx, r.Client, resource, reason, message); err != nil {
+		r.ReqLogger.Error(err, "Could not update status of resource "+resource.Name)
+	}
+}
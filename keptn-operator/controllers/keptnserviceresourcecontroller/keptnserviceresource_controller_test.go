@@ -0,0 +1,285 @@
+package keptnserviceresourcecontroller
+
+import (
+	"context"
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	keptnfake "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient/fake"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"strings"
+	"testing"
+)
+
+func newReconciler(t *testing.T, server *keptnfake.Server, objects ...client.Object) *KeptnServiceResourceReconciler {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	objects = append(objects,
+		&apiv1.KeptnInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "keptn"},
+			Spec:       apiv1.KeptnInstanceSpec{APIUrl: server.URL},
+			Status:     apiv1.KeptnInstanceStatus{CurrentToken: server.Token},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "quality-gates", Namespace: "keptn"},
+			Data:       map[string]string{"slo.yaml": "spec_version: \"1.0\""},
+		},
+	)
+
+	return &KeptnServiceResourceReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(100),
+	}
+}
+
+func TestReconcileUploadsResource(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       apiv1.KeptnServiceResourceSpec
+		wantStages []string
+		wantReason string
+	}{
+		{
+			name:       "inline_stage",
+			spec:       apiv1.KeptnServiceResourceSpec{Stage: "prod", Content: "spec_version: \"0.1.1\""},
+			wantStages: []string{"prod"},
+			wantReason: apiv1.ReasonReconciled,
+		},
+		{
+			name:       "config_map_all_stages",
+			spec:       apiv1.KeptnServiceResourceSpec{ContentRef: &apiv1.ConfigMapKeyReference{Name: "quality-gates", Key: "slo.yaml"}},
+			wantStages: []string{"dev", "prod"},
+			wantReason: apiv1.ReasonReconciled,
+		},
+		{
+			name:       "missing_key",
+			spec:       apiv1.KeptnServiceResourceSpec{ContentRef: &apiv1.ConfigMapKeyReference{Name: "quality-gates", Key: "sli.yaml"}},
+			wantReason: apiv1.ReasonInvalidSpec,
+		},
+		{
+			name:       "missing_content",
+			wantReason: apiv1.ReasonInvalidSpec,
+		},
+		{
+			name:       "missing_service",
+			spec:       apiv1.KeptnServiceResourceSpec{Service: "other", Content: "spec_version: \"0.1.1\""},
+			wantReason: apiv1.ReasonServiceNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := keptnfake.NewServer()
+			defer server.Close()
+			server.AddProject("podtato", "dev", "prod")
+			server.AddService("podtato", "hello")
+
+			spec := tt.spec
+			spec.Project = "podtato"
+			spec.ResourceURI = "slo.yaml"
+			if spec.Service == "" {
+				spec.Service = "hello"
+			}
+			r := newReconciler(t, server, &apiv1.KeptnServiceResource{
+				ObjectMeta: metav1.ObjectMeta{Name: "hello-slo", Namespace: "keptn"},
+				Spec:       spec,
+			})
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "hello-slo", Namespace: "keptn"}}
+
+			_, err := r.Reconcile(context.TODO(), req)
+			require.NoError(t, err)
+
+			resource := &apiv1.KeptnServiceResource{}
+			require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, resource))
+			require.Equal(t, tt.wantReason, meta.FindStatusCondition(resource.Status.Conditions, apiv1.ConditionReady).Reason)
+			require.Equal(t, tt.wantStages, resource.Status.Stages)
+			for _, stage := range []string{"dev", "prod"} {
+				_, uploaded := server.ServiceResource("podtato", stage, "hello", "slo.yaml")
+				require.Equal(t, tt.wantStages != nil && (spec.Stage == "" || spec.Stage == stage), uploaded)
+			}
+		})
+	}
+}
+
+func TestReconcileChangedResource(t *testing.T) {
+	server := keptnfake.NewServer()
+	defer server.Close()
+	server.AddProject("podtato", "dev", "prod")
+	server.AddService("podtato", "hello")
+
+	r := newReconciler(t, server, &apiv1.KeptnServiceResource{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-slo", Namespace: "keptn"},
+		Spec:       apiv1.KeptnServiceResourceSpec{Project: "podtato", Service: "hello", ResourceURI: "slo.yaml", Content: "first"},
+	})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "hello-slo", Namespace: "keptn"}}
+	_, err := r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	// an unchanged resource is not uploaded again
+	uploads := countUploads(server)
+	_, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+	require.Equal(t, uploads, countUploads(server))
+
+	// moving the resource to a single stage and another path removes it from its previous location
+	resource := &apiv1.KeptnServiceResource{}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, resource))
+	resource.Spec.Stage = "prod"
+	resource.Spec.ResourceURI = "keptn/slo.yaml"
+	resource.Spec.Content = "second"
+	require.NoError(t, r.Client.Update(context.TODO(), resource))
+	_, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	content, ok := server.ServiceResource("podtato", "prod", "hello", "keptn/slo.yaml")
+	require.True(t, ok)
+	require.Equal(t, "second", content)
+	_, ok = server.ServiceResource("podtato", "prod", "hello", "slo.yaml")
+	require.False(t, ok)
+	_, ok = server.ServiceResource("podtato", "dev", "hello", "slo.yaml")
+	require.False(t, ok)
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, resource))
+	require.True(t, conditions.IsReady(resource))
+	require.Equal(t, []string{"prod"}, resource.Status.Stages)
+
+	// deleting the KeptnServiceResource deletes the resource in Keptn
+	require.NoError(t, r.Client.Delete(context.TODO(), resource))
+	_, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+	_, ok = server.ServiceResource("podtato", "prod", "hello", "keptn/slo.yaml")
+	require.False(t, ok)
+	require.True(t, errors.IsNotFound(r.Client.Get(context.TODO(), req.NamespacedName, &apiv1.KeptnServiceResource{})))
+}
+
+func TestReconcileMovedResource(t *testing.T) {
+	server := keptnfake.NewServer()
+	defer server.Close()
+	server.AddProject("podtato", "dev", "prod")
+	server.AddService("podtato", "hello")
+	server.AddService("podtato", "world")
+
+	r := newReconciler(t, server, &apiv1.KeptnServiceResource{
+		ObjectMeta: metav1.ObjectMeta{Name: "slo", Namespace: "keptn"},
+		Spec:       apiv1.KeptnServiceResourceSpec{Project: "podtato", Service: "hello", ResourceURI: "slo.yaml", Content: "first"},
+	})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "slo", Namespace: "keptn"}}
+	_, err := r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	// moving the resource to another service removes it from the previous service
+	resource := &apiv1.KeptnServiceResource{}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, resource))
+	resource.Spec.Service = "world"
+	require.NoError(t, r.Client.Update(context.TODO(), resource))
+	_, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	for _, stage := range []string{"dev", "prod"} {
+		_, ok := server.ServiceResource("podtato", stage, "hello", "slo.yaml")
+		require.False(t, ok)
+		_, ok = server.ServiceResource("podtato", stage, "world", "slo.yaml")
+		require.True(t, ok)
+	}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, resource))
+	require.Equal(t, "podtato", resource.Status.Project)
+	require.Equal(t, "world", resource.Status.Service)
+}
+
+func TestReconcileDeletionWithoutProject(t *testing.T) {
+	defaultInstance := &apiv1.KeptnInstanceReference{Name: "default", Namespace: "keptn"}
+	tests := []struct {
+		name         string
+		project      string
+		instance     *apiv1.KeptnInstanceReference
+		keepDefault  bool
+		wantRequests bool
+	}{
+		{name: "missing_project", project: "gone", instance: defaultInstance, keepDefault: true, wantRequests: true},
+		{name: "missing_instance", project: "podtato", instance: defaultInstance},
+		// the resource is never deleted in another instance than the one it has been uploaded to
+		{name: "unrecorded_instance", project: "podtato", keepDefault: true},
+		{name: "other_instance", project: "podtato", instance: &apiv1.KeptnInstanceReference{Name: "other", Namespace: "keptn"}, keepDefault: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := keptnfake.NewServer()
+			defer server.Close()
+			server.AddProject("podtato", "dev", "prod")
+			server.AddService("podtato", "hello")
+
+			now := metav1.Now()
+			r := newReconciler(t, server, &apiv1.KeptnServiceResource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "slo",
+					Namespace:         "keptn",
+					DeletionTimestamp: &now,
+					Finalizers:        []string{resourceFinalizerName},
+				},
+				Spec: apiv1.KeptnServiceResourceSpec{Project: tt.project, Service: "hello", ResourceURI: "slo.yaml", Content: "first"},
+				Status: apiv1.KeptnServiceResourceStatus{
+					Project:     tt.project,
+					Service:     "hello",
+					ResourceURI: "slo.yaml",
+					Stages:      []string{"dev", "prod"},
+					Instance:    tt.instance,
+				},
+			})
+			if !tt.keepDefault {
+				require.NoError(t, r.Client.Delete(context.TODO(), &apiv1.KeptnInstance{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "keptn"}}))
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "slo", Namespace: "keptn"}}
+
+			_, err := r.Reconcile(context.TODO(), req)
+			require.NoError(t, err)
+			require.True(t, errors.IsNotFound(r.Client.Get(context.TODO(), req.NamespacedName, &apiv1.KeptnServiceResource{})))
+			require.Equal(t, tt.wantRequests, len(server.Requests()) > 0)
+		})
+	}
+}
+
+func countUploads(server *keptnfake.Server) int {
+	count := 0
+	for _, request := range server.Requests() {
+		if strings.HasPrefix(request, "PUT ") && strings.HasSuffix(request, "/service/hello/resource") {
+			count++
+		}
+	}
+	return count
+}
+
+func TestResourcesOfConfigMap(t *testing.T) {
+	server := keptnfake.NewServer()
+	defer server.Close()
+
+	contentRef := &apiv1.ConfigMapKeyReference{Name: "quality-gates", Key: "slo.yaml"}
+	r := newReconciler(t, server,
+		&apiv1.KeptnServiceResource{
+			ObjectMeta: metav1.ObjectMeta{Name: "hello-slo", Namespace: "keptn"},
+			Spec:       apiv1.KeptnServiceResourceSpec{Project: "podtato", Service: "hello", ResourceURI: "slo.yaml", ContentRef: contentRef},
+		},
+		&apiv1.KeptnServiceResource{
+			ObjectMeta: metav1.ObjectMeta{Name: "inline-slo", Namespace: "keptn"},
+			Spec:       apiv1.KeptnServiceResourceSpec{Project: "podtato", Service: "hello", ResourceURI: "slo.yaml", Content: "first"},
+		},
+		&apiv1.KeptnServiceResource{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-slo", Namespace: "other"},
+			Spec:       apiv1.KeptnServiceResourceSpec{Project: "podtato", Service: "hello", ResourceURI: "slo.yaml", ContentRef: contentRef},
+		},
+	)
+
+	// only resources in the namespace of the ConfigMap refer to it
+	requests := r.resourcesOfConfigMap(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "quality-gates", Namespace: "keptn"}})
+	require.Equal(t, []ctrl.Request{{NamespacedName: types.NamespacedName{Name: "hello-slo", Namespace: "keptn"}}}, requests)
+	require.Empty(t, r.resourcesOfConfigMap(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "keptn"}}))
+}
@@ -22,8 +22,10 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-logr/logr"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/gitauth"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
 	"gopkg.in/yaml.v3"
 	"io/ioutil"
@@ -32,6 +34,8 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"os"
+	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"path/filepath"
@@ -57,6 +61,8 @@ type KeptnShipyardReconciler struct {
 	KeptnInstance apiv1.KeptnInstance
 	// KeptnAPIToken contains the API token used in this controller
 	KeptnAPIToken string
+	// KeptnCache contains the cached projects and services of the Keptn instances
+	KeptnCache *keptnclient.Cache
 }
 
 const reconcileErrorInterval = 10 * time.Second
@@ -82,15 +88,8 @@ func (r *KeptnShipyardReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	r.ReqLogger = ctrl.Log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
 	r.ReqLogger.Info("Reconciling KeptnShipyard")
 
-	var err error
-	r.KeptnInstance, r.KeptnAPIToken, err = utils.GetKeptnInstance(ctx, r.Client, req.Namespace)
-	if err != nil {
-		r.ReqLogger.Error(err, "Could not get Keptn Instance")
-		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
-	}
-
 	shipyardInstance := &apiv1.KeptnShipyard{}
-	err = r.Get(ctx, req.NamespacedName, shipyardInstance)
+	err := r.Get(ctx, req.NamespacedName, shipyardInstance)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
@@ -103,6 +102,13 @@ func (r *KeptnShipyardReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return reconcile.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
 	}
 
+	r.KeptnInstance, r.KeptnAPIToken, err = utils.GetKeptnInstanceForProject(ctx, r.Client, shipyardInstance.Spec.Project, req.Namespace)
+	if err != nil {
+		r.ReqLogger.Error(err, "Could not get Keptn Instance")
+		r.setStalled(ctx, shipyardInstance, apiv1.ReasonKeptnInstanceNotFound, err.Error())
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
+	}
+
 	shipyardSpecVersion := &v1.ConfigMap{}
 	err = r.Client.Get(ctx, types.NamespacedName{Name: "shipyard-" + shipyardInstance.Spec.Project, Namespace: req.Namespace}, shipyardSpecVersion)
 	if err != nil {
@@ -128,16 +134,17 @@ func (r *KeptnShipyardReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	specHash := utils.GetHashStructure(shipyardInstance.Spec)
 	if specHash == shipyardSpecVersion.Data["Hash"] {
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		return r.checkShipyardDrift(ctx, req, shipyardInstance)
 	}
 
-	projectExists, err := utils.CheckKeptnProjectExists(ctx, req, r.Client, shipyardInstance.Spec.Project)
+	projectExists, err := utils.CheckKeptnProjectExists(ctx, r.KeptnCache, r.KeptnInstance, r.KeptnAPIToken, shipyardInstance.Spec.Project)
 	if err != nil {
 		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
 	}
 	if !projectExists {
 		r.Recorder.Event(shipyardInstance, "Warning", "KeptnProjectNotFound", fmt.Sprintf("Keptn project %s does not exist", shipyardInstance.Spec.Project))
 		shipyardInstance.Status.ProjectExists = false
+		conditions.MarkReconciling(shipyardInstance, apiv1.ReasonProjectNotFound, fmt.Sprintf("Waiting for Keptn project %s", shipyardInstance.Spec.Project))
 		err := r.Client.Status().Update(ctx, shipyardInstance)
 		if err != nil {
 			r.ReqLogger.Error(err, "Could not update status of shipyard "+shipyardInstance.Spec.Project)
@@ -159,14 +166,18 @@ func (r *KeptnShipyardReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	shipyardString, err := yaml.Marshal(keptnShipyard)
 	if err != nil {
 		r.ReqLogger.Error(err, "Could not marshal shipyard")
+		r.setStalled(ctx, shipyardInstance, apiv1.ReasonInvalidSpec, "Could not marshal shipyard: "+err.Error())
 		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
 	}
 
-	err = r.updateShipyard(ctx, req.Namespace, shipyardInstance.Spec.Project, shipyardString)
+	commit, err := r.updateShipyard(ctx, req.Namespace, shipyardInstance.Spec.Project, shipyardString)
 	if err != nil {
 		r.ReqLogger.Error(err, "Could not update shipyard")
+		r.setStalled(ctx, shipyardInstance, apiv1.ReasonUpstreamError, err.Error())
 		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
 	}
+	// the stages of the project change with the shipyard
+	r.KeptnCache.Invalidate(keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnAPIToken))
 
 	shipyardSpecVersion.Data["Hash"] = specHash
 	err = r.Client.Update(ctx, shipyardSpecVersion)
@@ -177,10 +188,189 @@ func (r *KeptnShipyardReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		r.ReqLogger.Info("Updated status", "status", shipyardInstance.Status)
 	}
 
+	shipyardInstance.Status.LastUpstreamCommit = commit
+	shipyardInstance.Status.Drifted = false
+	conditions.MarkReady(shipyardInstance, apiv1.ReasonReconciled, "Shipyard has been updated in the project")
+	if err := r.Client.Status().Update(ctx, shipyardInstance); err != nil {
+		r.ReqLogger.Error(err, "Could not update status of shipyard "+shipyardInstance.Spec.Project)
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
+	}
+
 	r.ReqLogger.Info("Finished Reconciling KeptnShipyard")
 	return ctrl.Result{RequeueAfter: reconcileSuccessInterval}, nil
 }
 
+//checkShipyardDrift compares the shipyard.yaml of the project with the KeptnShipyard and either reports or overwrites a
+//changed shipyard depending on the policy of the project. Shipyards pushed by the operator are only checked if the upstream
+//repository has a new commit, uploaded shipyards are fetched from the Keptn API on every run
+func (r *KeptnShipyardReconciler) checkShipyardDrift(ctx context.Context, req ctrl.Request, shipyardInstance *apiv1.KeptnShipyard) (ctrl.Result, error) {
+	project := &apiv1.KeptnProject{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: shipyardInstance.Spec.Project, Namespace: req.Namespace}, project)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// without a KeptnProject there is no upstream repository known to the operator
+			return r.shipyardUpToDate(ctx, shipyardInstance)
+		}
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
+	}
+	policy := project.Spec.GetShipyardDriftPolicy()
+
+	shipyardString, err := yaml.Marshal(shipyardInstance.Spec.Shipyard)
+	if err != nil {
+		r.setStalled(ctx, shipyardInstance, apiv1.ReasonInvalidSpec, "Could not marshal shipyard: "+err.Error())
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
+
+	var commit string
+	var drifted bool
+	if project.Spec.GetShipyardUploadMode() == apiv1.ShipyardUploadModeKeptnAPI {
+		drifted, err = r.uploadedShipyardDiffers(ctx, shipyardInstance.Spec.Project, shipyardString)
+		if err != nil {
+			r.ReqLogger.Error(err, "Could not check uploaded shipyard")
+			r.setStalled(ctx, shipyardInstance, apiv1.ReasonKeptnAPIError, err.Error())
+			return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+		}
+		// there is no commit to compare with, so only changes of the drift are handled
+		if !drifted && !shipyardInstance.Status.Drifted {
+			return r.shipyardUpToDate(ctx, shipyardInstance)
+		}
+		if drifted && shipyardInstance.Status.Drifted && policy == apiv1.ShipyardDriftPolicyReport {
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+		return r.handleShipyardDrift(ctx, req, shipyardInstance, policy, drifted, "", shipyardString)
+	}
+
+	upstreamRepo, err := utils.GetUpstreamCredentials(ctx, r.Client, shipyardInstance.Spec.Project, req.Namespace)
+	if err != nil {
+		r.setStalled(ctx, shipyardInstance, apiv1.ReasonUpstreamError, err.Error())
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
+
+	// the shipyard is only checked out if the upstream repository changed since the last check
+	commit, err = utils.GetUpstreamBranchHash(upstreamRepo)
+	if err != nil {
+		r.ReqLogger.Error(err, "Could not get upstream commit")
+		r.setStalled(ctx, shipyardInstance, apiv1.ReasonUpstreamError, err.Error())
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
+	unchanged := commit == shipyardInstance.Status.LastUpstreamCommit
+	if unchanged && !shipyardInstance.Status.Drifted {
+		return r.shipyardUpToDate(ctx, shipyardInstance)
+	}
+	if unchanged && policy == apiv1.ShipyardDriftPolicyReport {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	drifted = shipyardInstance.Status.Drifted
+	if !unchanged {
+		drifted, err = upstreamShipyardDiffers(upstreamRepo, shipyardString)
+		if err != nil {
+			r.ReqLogger.Error(err, "Could not check upstream shipyard")
+			r.setStalled(ctx, shipyardInstance, apiv1.ReasonUpstreamError, err.Error())
+			return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+		}
+	}
+	return r.handleShipyardDrift(ctx, req, shipyardInstance, policy, drifted, commit, shipyardString)
+}
+
+//handleShipyardDrift applies the drift policy of the project and records the result in the status of the KeptnShipyard,
+//commit is the current upstream commit and empty for uploaded shipyards
+func (r *KeptnShipyardReconciler) handleShipyardDrift(ctx context.Context, req ctrl.Request, shipyardInstance *apiv1.KeptnShipyard, policy apiv1.ShipyardDriftPolicy, drifted bool, commit string, shipyardString []byte) (ctrl.Result, error) {
+	var err error
+	switch {
+	case !drifted:
+		conditions.MarkReady(shipyardInstance, apiv1.ReasonReconciled, "Shipyard is up to date")
+	case policy == apiv1.ShipyardDriftPolicyOverwrite:
+		commit, err = r.updateShipyard(ctx, req.Namespace, shipyardInstance.Spec.Project, shipyardString)
+		if err != nil {
+			r.ReqLogger.Error(err, "Could not overwrite shipyard")
+			r.setStalled(ctx, shipyardInstance, apiv1.ReasonUpstreamError, err.Error())
+			return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+		}
+		r.KeptnCache.Invalidate(keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnAPIToken))
+		r.Recorder.Event(shipyardInstance, "Normal", "ShipyardDriftCorrected", fmt.Sprintf("Overwrote the changed shipyard of project %s", shipyardInstance.Spec.Project))
+		drifted = false
+		conditions.MarkReady(shipyardInstance, apiv1.ReasonReconciled, "Changed shipyard has been overwritten")
+	default:
+		message := fmt.Sprintf("The shipyard of project %s has been changed outside of the operator", shipyardInstance.Spec.Project)
+		if commit != "" {
+			message = fmt.Sprintf("The shipyard of project %s has been changed in the upstream repository (commit %s)", shipyardInstance.Spec.Project, commit)
+		}
+		r.Recorder.Event(shipyardInstance, "Warning", "ShipyardDrift", message)
+		conditions.MarkStalled(shipyardInstance, apiv1.ReasonShipyardDrift, message)
+	}
+
+	shipyardInstance.Status.LastUpstreamCommit = commit
+	shipyardInstance.Status.Drifted = drifted
+	if err := r.Client.Status().Update(ctx, shipyardInstance); err != nil {
+		r.ReqLogger.Error(err, "Could not update status of shipyard "+shipyardInstance.Spec.Project)
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
+	}
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+}
+
+func (r *KeptnShipyardReconciler) shipyardUpToDate(ctx context.Context, shipyardInstance *apiv1.KeptnShipyard) (ctrl.Result, error) {
+	if err := conditions.Ready(ctx, r.Client, shipyardInstance, apiv1.ReasonReconciled, "Shipyard is up to date"); err != nil {
+		r.ReqLogger.Error(err, "Could not update status of shipyard "+shipyardInstance.Spec.Project)
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
+	}
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+}
+
+//upstreamShipyardDiffers checks out the upstream repository and compares its shipyard.yaml with the given shipyard
+func upstreamShipyardDiffers(upstreamRepo *utils.GitRepositoryConfig, shipyard []byte) (bool, error) {
+	upstreamDir, err := ioutil.TempDir("", "upstream_tmp_dir")
+	if err != nil {
+		return false, fmt.Errorf("could not create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(upstreamDir)
+
+	_, _, err = utils.CheckOutGitRepo(upstreamRepo, upstreamDir)
+	if err != nil {
+		return false, err
+	}
+
+	upstreamShipyard, err := ioutil.ReadFile(filepath.Join(upstreamDir, "shipyard.yaml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("could not read shipyard: %w", err)
+	}
+	return shipyardDiffers(upstreamShipyard, shipyard)
+}
+
+//uploadedShipyardDiffers fetches the shipyard.yaml of the project from the Keptn API and compares it with the given shipyard
+func (r *KeptnShipyardReconciler) uploadedShipyardDiffers(ctx context.Context, project string, shipyard []byte) (bool, error) {
+	uploadedShipyard, err := keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnAPIToken).GetProjectResource(ctx, project, "shipyard.yaml")
+	if err != nil {
+		if keptnclient.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return shipyardDiffers(uploadedShipyard, shipyard)
+}
+
+//shipyardDiffers compares two shipyards ignoring formatting and the order of keys
+func shipyardDiffers(upstreamShipyard []byte, shipyard []byte) (bool, error) {
+	var upstream, desired interface{}
+	if err := yaml.Unmarshal(upstreamShipyard, &upstream); err != nil {
+		// a shipyard which can't be parsed has been changed as well
+		return true, nil
+	}
+	if err := yaml.Unmarshal(shipyard, &desired); err != nil {
+		return false, fmt.Errorf("could not unmarshal shipyard: %w", err)
+	}
+	return !reflect.DeepEqual(upstream, desired), nil
+}
+
+func (r *KeptnShipyardReconciler) setStalled(ctx context.Context, shipyard *apiv1.KeptnShipyard, reason, message string) {
+	if err := conditions.Stalled(ctx, r.Client, shipyard, reason, message); err != nil {
+		r.ReqLogger.Error(err, "Could not update status of shipyard "+shipyard.Spec.Project)
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *KeptnShipyardReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -188,22 +378,42 @@ func (r *KeptnShipyardReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-func (r *KeptnShipyardReconciler) updateShipyard(ctx context.Context, namespace string, project string, shipyard []byte) error {
+//updateShipyard writes the shipyard to the project according to its upload mode and returns the hash of the new commit if
+//it has been pushed to the upstream repository
+func (r *KeptnShipyardReconciler) updateShipyard(ctx context.Context, namespace string, project string, shipyard []byte) (string, error) {
+	keptnProject := &apiv1.KeptnProject{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: project, Namespace: namespace}, keptnProject)
+	if err != nil && !errors.IsNotFound(err) {
+		return "", err
+	}
+	if err == nil && keptnProject.Spec.GetShipyardUploadMode() == apiv1.ShipyardUploadModeKeptnAPI {
+		err = keptnclient.NewClientForInstance(r.KeptnInstance, r.KeptnAPIToken).UploadProjectResource(ctx, project, "shipyard.yaml", shipyard)
+		if err != nil {
+			return "", fmt.Errorf("could not upload shipyard: %w", err)
+		}
+		return "", nil
+	}
+	return r.pushShipyard(ctx, namespace, project, shipyard)
+}
+
+//pushShipyard pushes the shipyard to the configured branch of the upstream repository and returns the hash of the new commit
+func (r *KeptnShipyardReconciler) pushShipyard(ctx context.Context, namespace string, project string, shipyard []byte) (string, error) {
 	upstreamDir, _ := ioutil.TempDir("", "upstream_tmp_dir")
+	defer os.RemoveAll(upstreamDir)
 
 	upstreamRepo, err := utils.GetUpstreamCredentials(ctx, r.Client, project, namespace)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	gitrepo, _, err := utils.CheckOutGitRepo(upstreamRepo, upstreamDir)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	authentication := &githttp.BasicAuth{
-		Username: upstreamRepo.User,
-		Password: upstreamRepo.Token,
+	authentication, err := gitauth.NewAuthMethod(upstreamRepo.RemoteURI, upstreamRepo.User, upstreamRepo.Token, upstreamRepo.SSH)
+	if err != nil {
+		return "", err
 	}
 
 	commitOptions := git.CommitOptions{
@@ -216,34 +426,39 @@ func (r *KeptnShipyardReconciler) updateShipyard(ctx context.Context, namespace
 
 	err = ioutil.WriteFile(filepath.Join(upstreamDir, "shipyard.yaml"), shipyard, 0444)
 	if err != nil {
-		return fmt.Errorf("could not write shipyard: %w", err)
+		return "", fmt.Errorf("could not write shipyard: %w", err)
 	}
 
 	w, err := gitrepo.Worktree()
 	if err != nil {
-		return fmt.Errorf("could not set worktree: %w", err)
+		return "", fmt.Errorf("could not set worktree: %w", err)
 	}
 
 	// go-git can't stage deleted files https://github.com/src-d/go-git/issues/1268
 	err = utils.AddGit(w)
 	if err != nil {
-		return fmt.Errorf("could not add files: %w", err)
+		return "", fmt.Errorf("could not add files: %w", err)
 	}
 
-	_, err = w.Commit("Push new version", &commitOptions)
+	commit, err := w.Commit("Push new version", &commitOptions)
 	if err != nil {
-		return fmt.Errorf("could not commit: %w", err)
+		return "", fmt.Errorf("could not commit: %w", err)
 	}
 
+	// only the checked out branch is pushed, which is the default branch of the project
+	head, err := gitrepo.Head()
+	if err != nil {
+		return "", fmt.Errorf("could not get checked out branch: %w", err)
+	}
 	err = gitrepo.Push(&git.PushOptions{
 		RemoteName: "origin",
 		Auth:       authentication,
 		RefSpecs: []config.RefSpec{
-			"refs/heads/*:refs/heads/*",
+			config.RefSpec(head.Name().String() + ":" + head.Name().String()),
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("could not push commit: %w", err)
+		return "", fmt.Errorf("could not push commit: %w", err)
 	}
-	return nil
+	return commit.String(), nil
 }
@@ -0,0 +1,261 @@
+package keptnshipyardcontroller
+
+import (
+	"context"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
+	keptnfake "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient/fake"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"os"
+	"path/filepath"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+	"time"
+)
+
+var testShipyard = apiv1.KeptnShipyardSpec{
+	Project: "podtato",
+	Shipyard: apiv1.Shipyard{
+		ApiVersion: "spec.keptn.sh/0.2.2",
+		Kind:       "Shipyard",
+		Spec: apiv1.ShipyardSpec{Stages: []apiv1.Stage{{
+			Name:      "dev",
+			Sequences: []apiv1.Sequence{{Name: "delivery", Tasks: []apiv1.Task{{Name: "deployment"}}}},
+		}}},
+	},
+}
+
+//commitShipyard writes the shipyard.yaml to the upstream repository and returns the hash of the commit
+func commitShipyard(t *testing.T, dir string, shipyard string) string {
+	repo, err := git.PlainOpen(dir)
+	if err == git.ErrRepositoryNotExists {
+		repo, err = git.PlainInit(dir, false)
+	}
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shipyard.yaml"), []byte(shipyard), 0644))
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add("shipyard.yaml")
+	require.NoError(t, err)
+	hash, err := worktree.Commit("change shipyard", &git.CommitOptions{
+		Author: &object.Signature{Name: "someone", Email: "someone@keptn.sh", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return hash.String()
+}
+
+func readShipyard(t *testing.T, remote string) string {
+	dir := t.TempDir()
+	config, err := utils.GetGitCredentials(context.TODO(), remote, "", "", "", "")
+	require.NoError(t, err)
+	_, _, err = utils.CheckOutGitRepo(config, dir)
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, "shipyard.yaml"))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestReconcileShipyardDrift(t *testing.T) {
+	rendered, err := yaml.Marshal(testShipyard.Shipyard)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		policy       apiv1.ShipyardDriftPolicy
+		change       bool
+		wantDrifted  bool
+		wantReason   string
+		wantUpstream string
+		wantEvent    string
+	}{
+		{name: "unchanged", policy: apiv1.ShipyardDriftPolicyReport, wantReason: apiv1.ReasonReconciled, wantUpstream: string(rendered)},
+		{name: "report", policy: apiv1.ShipyardDriftPolicyReport, change: true, wantDrifted: true, wantReason: apiv1.ReasonShipyardDrift, wantUpstream: "changed", wantEvent: "ShipyardDrift"},
+		{name: "default_policy", change: true, wantDrifted: true, wantReason: apiv1.ReasonShipyardDrift, wantUpstream: "changed", wantEvent: "ShipyardDrift"},
+		{name: "overwrite", policy: apiv1.ShipyardDriftPolicyOverwrite, change: true, wantReason: apiv1.ReasonReconciled, wantUpstream: string(rendered), wantEvent: "ShipyardDriftCorrected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := keptnfake.NewServer()
+			defer server.Close()
+			server.AddProject("podtato", "dev")
+
+			// the shipyard has been pushed by the operator before
+			upstream := t.TempDir()
+			pushed := commitShipyard(t, upstream, string(rendered))
+			if tt.change {
+				commitShipyard(t, upstream, "apiVersion: spec.keptn.sh/0.2.2\nkind: Shipyard\nspec:\n  stages:\n    - name: changed\n")
+			}
+
+			scheme := runtime.NewScheme()
+			require.NoError(t, apiv1.AddToScheme(scheme))
+			require.NoError(t, v1.AddToScheme(scheme))
+			recorder := record.NewFakeRecorder(10)
+			r := &KeptnShipyardReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+					&apiv1.KeptnInstance{
+						ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "keptn"},
+						Spec:       apiv1.KeptnInstanceSpec{APIUrl: server.URL},
+						Status:     apiv1.KeptnInstanceStatus{CurrentToken: server.Token},
+					},
+					&apiv1.KeptnProject{
+						ObjectMeta: metav1.ObjectMeta{Name: "podtato", Namespace: "keptn"},
+						Spec:       apiv1.KeptnProjectSpec{Repository: upstream, ShipyardDriftPolicy: tt.policy},
+					},
+					&apiv1.KeptnShipyard{
+						ObjectMeta: metav1.ObjectMeta{Name: "podtato", Namespace: "keptn"},
+						Spec:       testShipyard,
+						Status:     apiv1.KeptnShipyardStatus{LastUpstreamCommit: pushed},
+					},
+					&v1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Name: "shipyard-podtato", Namespace: "keptn"},
+						Data:       map[string]string{"Hash": utils.GetHashStructure(testShipyard)},
+					},
+				).Build(),
+				Scheme:   scheme,
+				Recorder: recorder,
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "podtato", Namespace: "keptn"}}
+
+			// the second run must keep the result of the first one without checking out the repository again
+			for i := 0; i < 2; i++ {
+				_, err = r.Reconcile(context.TODO(), req)
+				require.NoError(t, err)
+			}
+
+			shipyard := &apiv1.KeptnShipyard{}
+			require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, shipyard))
+			require.Equal(t, tt.wantDrifted, shipyard.Status.Drifted)
+			require.Equal(t, !tt.wantDrifted, conditions.IsReady(shipyard))
+			require.Equal(t, tt.wantReason, meta.FindStatusCondition(shipyard.Status.Conditions, apiv1.ConditionReady).Reason)
+			require.Contains(t, readShipyard(t, upstream), tt.wantUpstream)
+
+			head, err := utils.GetUpstreamBranchHash(&utils.GitRepositoryConfig{RemoteURI: upstream})
+			require.NoError(t, err)
+			require.Equal(t, head, shipyard.Status.LastUpstreamCommit)
+
+			close(recorder.Events)
+			var events []string
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			if tt.wantEvent == "" {
+				require.Empty(t, events)
+			} else {
+				require.Len(t, events, 1)
+				require.Contains(t, events[0], tt.wantEvent)
+			}
+		})
+	}
+}
+
+func newReconciler(t *testing.T, server *keptnfake.Server, project apiv1.KeptnProjectSpec) *KeptnShipyardReconciler {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+	require.NoError(t, v1.AddToScheme(scheme))
+	return &KeptnShipyardReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&apiv1.KeptnInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "keptn"},
+				Spec:       apiv1.KeptnInstanceSpec{APIUrl: server.URL},
+				Status:     apiv1.KeptnInstanceStatus{CurrentToken: server.Token},
+			},
+			&apiv1.KeptnProject{
+				ObjectMeta: metav1.ObjectMeta{Name: "podtato", Namespace: "keptn"},
+				Spec:       project,
+			},
+			&apiv1.KeptnShipyard{
+				ObjectMeta: metav1.ObjectMeta{Name: "podtato", Namespace: "keptn"},
+				Spec:       testShipyard,
+				Status:     apiv1.KeptnShipyardStatus{ProjectExists: true},
+			},
+			&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "shipyard-podtato", Namespace: "keptn"},
+				Data:       map[string]string{"Hash": "none"},
+			},
+		).Build(),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+}
+
+func TestReconcilePushesToDefaultBranch(t *testing.T) {
+	server := keptnfake.NewServer()
+	defer server.Close()
+	server.AddProject("podtato", "dev")
+
+	upstream := t.TempDir()
+	initial := commitShipyard(t, upstream, "kind: Shipyard")
+	repo, err := git.PlainOpen(upstream)
+	require.NoError(t, err)
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("develop"), plumbing.NewHash(initial))))
+
+	r := newReconciler(t, server, apiv1.KeptnProjectSpec{Repository: upstream, DefaultBranch: "develop"})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "podtato", Namespace: "keptn"}}
+	_, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	shipyard := &apiv1.KeptnShipyard{}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, shipyard))
+	require.True(t, conditions.IsReady(shipyard))
+
+	// only the default branch of the project has been updated
+	develop, err := utils.GetUpstreamBranchHash(&utils.GitRepositoryConfig{RemoteURI: upstream, Branch: "develop"})
+	require.NoError(t, err)
+	require.Equal(t, develop, shipyard.Status.LastUpstreamCommit)
+	require.NotEqual(t, initial, develop)
+	master, err := utils.GetUpstreamBranchHash(&utils.GitRepositoryConfig{RemoteURI: upstream, Branch: "master"})
+	require.NoError(t, err)
+	require.Equal(t, initial, master)
+}
+
+func TestReconcileUploadsShipyard(t *testing.T) {
+	rendered, err := yaml.Marshal(testShipyard.Shipyard)
+	require.NoError(t, err)
+
+	server := keptnfake.NewServer()
+	defer server.Close()
+	server.AddProject("podtato", "dev")
+
+	// the repository is not accessible by the operator
+	r := newReconciler(t, server, apiv1.KeptnProjectSpec{
+		Repository:          filepath.Join(t.TempDir(), "missing"),
+		ShipyardUploadMode:  apiv1.ShipyardUploadModeKeptnAPI,
+		ShipyardDriftPolicy: apiv1.ShipyardDriftPolicyReport,
+	})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "podtato", Namespace: "keptn"}}
+	_, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	uploaded, ok := server.Resource("podtato", "shipyard.yaml")
+	require.True(t, ok)
+	require.Equal(t, string(rendered), uploaded)
+
+	shipyard := &apiv1.KeptnShipyard{}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, shipyard))
+	require.True(t, conditions.IsReady(shipyard))
+	require.Empty(t, shipyard.Status.LastUpstreamCommit)
+
+	// a shipyard changed via the Keptn API is reported once
+	require.NoError(t, keptnclient.NewClient(server.URL, "x-token", server.Token).UploadProjectResource(context.TODO(), "podtato", "shipyard.yaml", []byte("kind: Shipyard")))
+	for i := 0; i < 2; i++ {
+		_, err = r.Reconcile(context.TODO(), req)
+		require.NoError(t, err)
+	}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, shipyard))
+	require.True(t, shipyard.Status.Drifted)
+	require.Equal(t, apiv1.ReasonShipyardDrift, meta.FindStatusCondition(shipyard.Status.Conditions, apiv1.ConditionReady).Reason)
+	require.Len(t, r.Recorder.(*record.FakeRecorder).Events, 1)
+}
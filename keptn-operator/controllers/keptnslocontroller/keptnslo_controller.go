@@ -0,0 +1,179 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keptnslocontroller
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"time"
+
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// KeptnSLOReconciler reconciles a KeptnSLO object
+type KeptnSLOReconciler struct {
+	client.Client
+
+	// Scheme contains the scheme of this controller
+	Scheme *runtime.Scheme
+	// Recorder contains the Recorder of this controller
+	Recorder record.EventRecorder
+	// ReqLogger contains the Logger of this controller
+	ReqLogger logr.Logger
+}
+
+const reconcileErrorInterval = 10 * time.Second
+
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptnslos,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptnslos/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptnslos/finalizers,verbs=update
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptnserviceresources,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile validates a KeptnSLO and renders it into the slo.yaml of a KeptnServiceResource owned by the SLO, which
+// uploads it to Keptn. Deleting the KeptnSLO deletes the KeptnServiceResource and thereby the slo.yaml in Keptn
+func (r *KeptnSLOReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.ReqLogger = ctrl.Log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	r.ReqLogger.Info("Reconciling KeptnSLO")
+
+	slo := &apiv1.KeptnSLO{}
+	if err := r.Client.Get(ctx, req.NamespacedName, slo); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		r.ReqLogger.Error(err, "Failed to get the KeptnSLO")
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, nil
+	}
+	if !slo.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	status := *slo.Status.DeepCopy()
+
+	content, err := utils.RenderSLO(slo.Spec)
+	if err != nil {
+		r.Recorder.Event(slo, "Warning", "InvalidSLO", err.Error())
+		return r.updateStatus(ctx, slo, status, func() { conditions.MarkStalled(slo, apiv1.ReasonInvalidSpec, err.Error()) })
+	}
+
+	conflict, err := r.findConflictingSLO(ctx, slo)
+	if err != nil {
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
+	}
+	if conflict != "" {
+		message := fmt.Sprintf("KeptnSLO %s already defines the SLO of service %s in the stages of this SLO", conflict, slo.Spec.Service)
+		if result, err := r.updateStatus(ctx, slo, status, func() { conditions.MarkStalled(slo, apiv1.ReasonSLOConflict, message) }); err != nil {
+			return result, err
+		}
+		// the conflict is resolved by deleting or changing the other SLO
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	resource := &apiv1.KeptnServiceResource{ObjectMeta: metav1.ObjectMeta{Name: "slo-" + slo.Name, Namespace: slo.Namespace}}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, resource, func() error {
+		resource.Spec = apiv1.KeptnServiceResourceSpec{
+			Project:     slo.Spec.Project,
+			Service:     slo.Spec.Service,
+			Stage:       slo.Spec.Stage,
+			ResourceURI: utils.SLOResourceURI,
+			Content:     string(content),
+		}
+		return controllerutil.SetControllerReference(slo, resource, r.Scheme)
+	})
+	if err != nil {
+		r.ReqLogger.Error(err, "Could not create or update KeptnServiceResource "+resource.Name)
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
+	}
+
+	slo.Status.ResourceName = resource.Name
+	slo.Status.Stages = resource.Status.Stages
+	ready := meta.FindStatusCondition(resource.Status.Conditions, apiv1.ConditionReady)
+	return r.updateStatus(ctx, slo, status, func() {
+		switch {
+		case ready == nil || resource.Status.ObservedGeneration != resource.Generation:
+			conditions.MarkReconciling(slo, apiv1.ReasonProgressing, "Waiting for the upload of "+utils.SLOResourceURI)
+		case ready.Status == metav1.ConditionTrue:
+			// the resource has been updated with the content of this generation before, otherwise its generation would not be observed yet
+			slo.Status.UploadedGeneration = slo.Generation
+			conditions.MarkReady(slo, apiv1.ReasonReconciled, fmt.Sprintf("SLO has been uploaded to %d stage(s)", len(resource.Status.Stages)))
+		case meta.IsStatusConditionTrue(resource.Status.Conditions, apiv1.ConditionStalled):
+			conditions.MarkStalled(slo, ready.Reason, ready.Message)
+		default:
+			conditions.MarkReconciling(slo, ready.Reason, ready.Message)
+		}
+	})
+}
+
+//updateStatus applies the conditions and updates the status of the SLO if it differs from the previous status, the SLO
+//is watched including its status, so unchanged states must not be written
+func (r *KeptnSLOReconciler) updateStatus(ctx context.Context, slo *apiv1.KeptnSLO, previous apiv1.KeptnSLOStatus, mark func()) (ctrl.Result, error) {
+	mark()
+	if equality.Semantic.DeepEqual(previous, slo.Status) {
+		return ctrl.Result{}, nil
+	}
+	if err := r.Client.Status().Update(ctx, slo); err != nil {
+		r.ReqLogger.Error(err, "Could not update status of SLO "+slo.Name)
+		return ctrl.Result{Requeue: true, RequeueAfter: reconcileErrorInterval}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+//findConflictingSLO returns the name of an older KeptnSLO of the same service which is used in one of the stages of the
+//SLO, both would be written to the same slo.yaml
+func (r *KeptnSLOReconciler) findConflictingSLO(ctx context.Context, slo *apiv1.KeptnSLO) (string, error) {
+	slos := &apiv1.KeptnSLOList{}
+	if err := r.Client.List(ctx, slos, client.InNamespace(slo.Namespace)); err != nil {
+		return "", fmt.Errorf("could not list KeptnSLOs: %w", err)
+	}
+	for _, other := range slos.Items {
+		if other.Name == slo.Name || other.Spec.Project != slo.Spec.Project || other.Spec.Service != slo.Spec.Service {
+			continue
+		}
+		if other.Spec.Stage != "" && slo.Spec.Stage != "" && other.Spec.Stage != slo.Spec.Stage {
+			continue
+		}
+		if isOlder(other.ObjectMeta, slo.ObjectMeta) {
+			return other.Name, nil
+		}
+	}
+	return "", nil
+}
+
+func isOlder(a, b metav1.ObjectMeta) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KeptnSLOReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&apiv1.KeptnSLO{}).
+		Owns(&apiv1.KeptnServiceResource{}).
+		Complete(r)
+}
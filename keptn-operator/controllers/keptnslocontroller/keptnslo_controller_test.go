@@ -0,0 +1,136 @@
+package keptnslocontroller
+
+import (
+	"context"
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+	"time"
+)
+
+func newSLO(name string, stage string, created time.Time, objectives ...apiv1.SLOObjective) *apiv1.KeptnSLO {
+	return &apiv1.KeptnSLO{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "keptn", CreationTimestamp: metav1.NewTime(created)},
+		Spec:       apiv1.KeptnSLOSpec{Project: "podtato", Service: "hello", Stage: stage, Objectives: objectives},
+	}
+}
+
+func newReconciler(t *testing.T, objects ...client.Object) *KeptnSLOReconciler {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+	return &KeptnSLOReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(100),
+	}
+}
+
+func TestReconcileRendersSLO(t *testing.T) {
+	objective := apiv1.SLOObjective{SLI: "response_time_p95", Pass: []apiv1.SLOCriteria{{Criteria: []string{"<600"}}}}
+	slo := newSLO("hello", "hardening", time.Now(), objective)
+	slo.Generation = 2
+	r := newReconciler(t, slo)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "hello", Namespace: "keptn"}}
+
+	_, err := r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	resource := &apiv1.KeptnServiceResource{}
+	require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "slo-hello", Namespace: "keptn"}, resource))
+	require.Equal(t, "slo.yaml", resource.Spec.ResourceURI)
+	require.Equal(t, "hardening", resource.Spec.Stage)
+	require.Contains(t, resource.Spec.Content, "sli: response_time_p95")
+	require.Equal(t, "hello", resource.OwnerReferences[0].Name)
+
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, slo))
+	require.Equal(t, "slo-hello", slo.Status.ResourceName)
+	require.Equal(t, apiv1.ReasonProgressing, meta.FindStatusCondition(slo.Status.Conditions, apiv1.ConditionReady).Reason)
+	require.Zero(t, slo.Status.UploadedGeneration)
+
+	// the SLO is ready as soon as the resource has been uploaded
+	resource.Status.Stages = []string{"hardening"}
+	conditions.MarkReady(resource, apiv1.ReasonReconciled, "uploaded")
+	require.NoError(t, r.Client.Status().Update(context.TODO(), resource))
+	_, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, slo))
+	require.True(t, conditions.IsReady(slo))
+	require.Equal(t, []string{"hardening"}, slo.Status.Stages)
+	require.Equal(t, int64(2), slo.Status.UploadedGeneration)
+}
+
+func TestReconcileInvalidSLO(t *testing.T) {
+	tests := []struct {
+		name       string
+		slos       []client.Object
+		wantReason string
+	}{
+		{
+			name:       "invalid_criteria",
+			slos:       []client.Object{newSLO("hello", "", time.Now(), apiv1.SLOObjective{SLI: "response_time_p95", Pass: []apiv1.SLOCriteria{{Criteria: []string{"fast"}}}})},
+			wantReason: apiv1.ReasonInvalidSpec,
+		},
+		{
+			name: "conflict_with_all_stages",
+			slos: []client.Object{
+				newSLO("hello", "hardening", time.Now(), apiv1.SLOObjective{SLI: "throughput"}),
+				newSLO("all", "", time.Now().Add(-time.Hour), apiv1.SLOObjective{SLI: "throughput"}),
+			},
+			wantReason: apiv1.ReasonSLOConflict,
+		},
+		{
+			name: "conflict_in_stage",
+			slos: []client.Object{
+				newSLO("hello", "hardening", time.Now(), apiv1.SLOObjective{SLI: "throughput"}),
+				newSLO("other", "hardening", time.Now().Add(-time.Hour), apiv1.SLOObjective{SLI: "throughput"}),
+			},
+			wantReason: apiv1.ReasonSLOConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReconciler(t, tt.slos...)
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "hello", Namespace: "keptn"}}
+
+			_, err := r.Reconcile(context.TODO(), req)
+			require.NoError(t, err)
+
+			slo := &apiv1.KeptnSLO{}
+			require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, slo))
+			stalled := meta.FindStatusCondition(slo.Status.Conditions, apiv1.ConditionStalled)
+			require.NotNil(t, stalled)
+			require.Equal(t, tt.wantReason, stalled.Reason)
+
+			err = r.Client.Get(context.TODO(), types.NamespacedName{Name: "slo-hello", Namespace: "keptn"}, &apiv1.KeptnServiceResource{})
+			require.True(t, errors.IsNotFound(err))
+		})
+	}
+}
+
+func TestReconcileSLOInOtherStage(t *testing.T) {
+	objective := apiv1.SLOObjective{SLI: "throughput"}
+	r := newReconciler(t,
+		newSLO("hello", "hardening", time.Now(), objective),
+		newSLO("other", "production", time.Now().Add(-time.Hour), objective),
+	)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "hello", Namespace: "keptn"}}
+
+	_, err := r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	resource := &apiv1.KeptnServiceResource{}
+	require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "slo-hello", Namespace: "keptn"}, resource))
+	require.Equal(t, utils.SLOResourceURI, resource.Spec.ResourceURI)
+}
@@ -18,13 +18,18 @@ package keptnstagecontroller
 
 import (
 	"context"
+	"fmt"
 	"github.com/go-logr/logr"
 	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/utils"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"strings"
 	"time"
 
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -44,14 +49,20 @@ type KeptnStageReconciler struct {
 	KeptnInstance apiv1.KeptnInstance
 	// KeptnToken contains the API token used in this controller
 	KeptnAPIToken string
+	// KeptnCache contains the cached state of the Keptn instances, it is invalidated after a stage has been pruned
+	KeptnCache *keptnclient.Cache
 }
 
 const reconcileErrorInterval = 10 * time.Second
 const reconcileSuccessInterval = 120 * time.Second
 
+// name of our custom finalizer
+const stageFinalizerName = "keptnstages.keptn.sh/finalizer"
+
 //+kubebuilder:rbac:groups=keptn.sh,resources=keptnstages,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=keptn.sh,resources=keptnstages/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=keptn.sh,resources=keptnstages/finalizers,verbs=update
+//+kubebuilder:rbac:groups=keptn.sh,resources=keptnservicedeployments,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -78,27 +89,170 @@ func (r *KeptnStageReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	shipyard, err := utils.CreateShipyard(ctx, r.Client, keptnstage.Spec.Project)
+	// examine DeletionTimestamp to determine if object is under deletion
+	if keptnstage.ObjectMeta.DeletionTimestamp.IsZero() {
+		// the finalizer ensures that the stage is only removed from the shipyard once it is not used anymore
+		if !utils.ContainsString(keptnstage.GetFinalizers(), stageFinalizerName) {
+			controllerutil.AddFinalizer(keptnstage, stageFinalizerName)
+			if err := r.Update(ctx, keptnstage); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	} else {
+		if utils.ContainsString(keptnstage.GetFinalizers(), stageFinalizerName) {
+			return r.removeStage(ctx, req, keptnstage)
+		}
+
+		// Stop reconciliation as the item is being deleted
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.recordInstance(ctx, keptnstage); err != nil {
+		r.ReqLogger.Error(err, "Could not update status")
+		return ctrl.Result{RequeueAfter: reconcileErrorInterval}, err
+	}
+
+	shipyard, err := utils.CreateShipyard(ctx, r.Client, keptnstage.Spec.Project, keptnstage.Namespace)
 	if err != nil {
 		r.ReqLogger.Error(err, "Could not create shipyard")
+		if utils.IsInvalidStageOrder(err) {
+			// the order has to be fixed in the spec of the stages, an update of a stage triggers a new reconciliation
+			r.setStalled(ctx, keptnstage, apiv1.ReasonInvalidSpec, err.Error())
+			return ctrl.Result{RequeueAfter: reconcileErrorInterval}, nil
+		}
+		r.setStalled(ctx, keptnstage, apiv1.ReasonFailed, "Could not create shipyard: "+err.Error())
 		return ctrl.Result{RequeueAfter: reconcileErrorInterval}, err
 	}
 
 	shipyardPresent, shipyardHash := utils.CheckKeptnShipyard(ctx, req, r.Client, keptnstage.Spec.Project)
 	if !shipyardPresent {
+		if err := conditions.Reconciling(ctx, r.Client, keptnstage, apiv1.ReasonShipyardNotFound, "Waiting for the KeptnShipyard of project "+keptnstage.Spec.Project); err != nil {
+			r.ReqLogger.Error(err, "Could not update status")
+		}
 		return ctrl.Result{RequeueAfter: reconcileErrorInterval, Requeue: true}, nil
 	}
 
 	err = utils.UpdateShipyard(ctx, r.Client, shipyard, shipyardHash, req.Namespace)
 	if err != nil {
 		r.ReqLogger.Error(err, "Could not update shipyard")
+		r.setStalled(ctx, keptnstage, apiv1.ReasonFailed, "Could not update shipyard: "+err.Error())
 		return ctrl.Result{RequeueAfter: reconcileErrorInterval, Requeue: true}, nil
 	}
 
+	if err := conditions.Ready(ctx, r.Client, keptnstage, apiv1.ReasonReconciled, "Stage has been added to the shipyard"); err != nil {
+		r.ReqLogger.Error(err, "Could not update status")
+		return ctrl.Result{RequeueAfter: reconcileErrorInterval}, err
+	}
+
 	r.ReqLogger.Info("Finished Reconciling KeptnStage")
 	return ctrl.Result{RequeueAfter: reconcileSuccessInterval}, nil
 }
 
+//removeStage removes a deleted stage from the shipyard as soon as no service deployment targets it anymore, and prunes it if requested
+func (r *KeptnStageReconciler) removeStage(ctx context.Context, req ctrl.Request, keptnstage *apiv1.KeptnStage) (ctrl.Result, error) {
+	deployments, err := utils.GetServiceDeploymentsForStage(ctx, r.Client, keptnstage.Namespace, keptnstage.Spec.Project, keptnstage.Name)
+	if err != nil {
+		r.ReqLogger.Error(err, "Could not get service deployments of stage "+keptnstage.Name)
+		return ctrl.Result{RequeueAfter: reconcileErrorInterval}, nil
+	}
+	if len(deployments) > 0 {
+		message := fmt.Sprintf("Stage %s is still targeted by the KeptnServiceDeployments %s", keptnstage.Name, strings.Join(deployments, ", "))
+		r.Recorder.Event(keptnstage, "Warning", "StageInUse", message)
+		r.setStalled(ctx, keptnstage, apiv1.ReasonStageInUse, message)
+		return ctrl.Result{RequeueAfter: reconcileErrorInterval}, nil
+	}
+
+	// the stage being deleted is not part of the composed shipyard anymore
+	shipyardPresent, shipyardHash := utils.CheckKeptnShipyard(ctx, req, r.Client, keptnstage.Spec.Project)
+	if shipyardPresent {
+		shipyard, err := utils.CreateShipyard(ctx, r.Client, keptnstage.Spec.Project, keptnstage.Namespace)
+		if err != nil {
+			r.ReqLogger.Error(err, "Could not create shipyard")
+			reason := apiv1.ReasonFailed
+			if utils.IsInvalidStageOrder(err) {
+				reason = apiv1.ReasonInvalidSpec
+			}
+			r.setStalled(ctx, keptnstage, reason, "Could not remove stage from shipyard: "+err.Error())
+			return ctrl.Result{RequeueAfter: reconcileErrorInterval}, nil
+		}
+		if err := utils.UpdateShipyard(ctx, r.Client, shipyard, shipyardHash, req.Namespace); err != nil {
+			r.ReqLogger.Error(err, "Could not update shipyard")
+			r.setStalled(ctx, keptnstage, apiv1.ReasonFailed, "Could not update shipyard: "+err.Error())
+			return ctrl.Result{RequeueAfter: reconcileErrorInterval}, nil
+		}
+	}
+
+	if keptnstage.Spec.Prune {
+		if err := r.pruneStage(ctx, keptnstage); err != nil {
+			r.ReqLogger.Error(err, "Could not prune stage "+keptnstage.Name)
+			r.Recorder.Event(keptnstage, "Warning", "StagePruneFailed", fmt.Sprintf("Could not prune stage %s: %s", keptnstage.Name, err.Error()))
+			r.setStalled(ctx, keptnstage, apiv1.ReasonKeptnAPIError, "Could not prune stage: "+err.Error())
+			return ctrl.Result{RequeueAfter: reconcileErrorInterval}, nil
+		}
+		r.Recorder.Event(keptnstage, "Normal", "StagePruned", fmt.Sprintf("Stage %s has been deleted in Keptn and in the upstream repository", keptnstage.Name))
+	}
+
+	controllerutil.RemoveFinalizer(keptnstage, stageFinalizerName)
+	if err := r.Update(ctx, keptnstage); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+//recordInstance records the KeptnInstance of the project in the status, the stage is pruned in this instance. Stages of
+//projects whose instance can't be resolved yet keep the recorded instance
+func (r *KeptnStageReconciler) recordInstance(ctx context.Context, keptnstage *apiv1.KeptnStage) error {
+	instance, _, err := utils.GetKeptnInstanceForProject(ctx, r.Client, keptnstage.Spec.Project, keptnstage.Namespace)
+	if err != nil {
+		r.ReqLogger.Info("Could not get Keptn instance of project " + keptnstage.Spec.Project + ": " + err.Error())
+		return nil
+	}
+	if ref := instance.Reference(); keptnstage.Status.Instance == nil || *keptnstage.Status.Instance != *ref {
+		keptnstage.Status.Instance = ref
+		return r.Client.Status().Update(ctx, keptnstage)
+	}
+	return nil
+}
+
+//pruneStage deletes the stage in the recorded Keptn instance and its branch in the upstream repository of the project
+func (r *KeptnStageReconciler) pruneStage(ctx context.Context, keptnstage *apiv1.KeptnStage) error {
+	instance, token, err := utils.GetRecordedKeptnInstance(ctx, r.Client, keptnstage.Status.Instance, keptnstage.Namespace)
+	if utils.IsKeptnInstanceUnavailable(err) {
+		r.Recorder.Event(keptnstage, "Warning", "CleanupSkipped", fmt.Sprintf("Stage %s has not been deleted in Keptn: %s", keptnstage.Name, err.Error()))
+	} else if err != nil {
+		return err
+	} else {
+		keptnClient := keptnclient.NewClientForInstance(instance, token)
+		err = keptnClient.DeleteStage(ctx, keptnstage.Spec.Project, keptnstage.Name)
+		if err != nil && !keptnclient.IsNotFound(err) {
+			return err
+		}
+		r.KeptnCache.Invalidate(keptnClient)
+	}
+
+	repositoryConfig, err := utils.GetUpstreamCredentials(ctx, r.Client, keptnstage.Spec.Project, keptnstage.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// without a KeptnProject there is no upstream repository known to the operator
+			return nil
+		}
+		return err
+	}
+	err = utils.DeleteUpstreamBranch(repositoryConfig, keptnstage.Name)
+	if utils.IsProtectedBranch(err) {
+		// the stage is deleted nevertheless, only its branch is kept
+		r.Recorder.Event(keptnstage, "Warning", "StageBranchRetained", fmt.Sprintf("Branch of stage %s has not been deleted: %s", keptnstage.Name, err.Error()))
+		return nil
+	}
+	return err
+}
+
+func (r *KeptnStageReconciler) setStalled(ctx context.Context, keptnstage *apiv1.KeptnStage, reason, message string) {
+	if err := conditions.Stalled(ctx, r.Client, keptnstage, reason, message); err != nil {
+		r.ReqLogger.Error(err, "Could not update status")
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *KeptnStageReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -0,0 +1,271 @@
+package keptnstagecontroller
+
+import (
+	"context"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/conditions"
+	keptnfake "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient/fake"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"os"
+	"path/filepath"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+	"time"
+)
+
+func newReconciler(t *testing.T, server *keptnfake.Server, objects ...client.Object) *KeptnStageReconciler {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+
+	objects = append(objects,
+		&apiv1.KeptnInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "keptn"},
+			Spec:       apiv1.KeptnInstanceSpec{APIUrl: server.URL},
+			Status:     apiv1.KeptnInstanceStatus{CurrentToken: server.Token},
+		},
+		&apiv1.KeptnShipyard{
+			ObjectMeta: metav1.ObjectMeta{Name: "podtato", Namespace: "keptn"},
+			Spec:       apiv1.KeptnShipyardSpec{Project: "podtato"},
+		},
+		&apiv1.KeptnStage{
+			ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "keptn"},
+			Spec:       apiv1.KeptnStageSpec{Project: "podtato"},
+		},
+	)
+
+	return &KeptnStageReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(100),
+	}
+}
+
+//newUpstreamRepository creates a local repository with a branch for each stage
+func newUpstreamRepository(t *testing.T, branches ...string) string {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shipyard.yaml"), []byte("kind: Shipyard"), 0644))
+	_, err = worktree.Add("shipyard.yaml")
+	require.NoError(t, err)
+	hash, err := worktree.Commit("initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "keptn", Email: "keptn@keptn.sh", When: time.Now()},
+	})
+	require.NoError(t, err)
+	for _, branch := range branches {
+		require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), hash)))
+	}
+	return dir
+}
+
+func hasBranch(t *testing.T, dir string, branch string) bool {
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(branch), false)
+	return err == nil
+}
+
+func stageNames(shipyard *apiv1.KeptnShipyard) []string {
+	names := []string{}
+	for _, stage := range shipyard.Spec.Shipyard.Spec.Stages {
+		names = append(names, stage.Name)
+	}
+	return names
+}
+
+func TestReconcileAddsFinalizer(t *testing.T) {
+	server := keptnfake.NewServer()
+	defer server.Close()
+	r := newReconciler(t, server)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "dev", Namespace: "keptn"}}
+
+	_, err := r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	stage := &apiv1.KeptnStage{}
+	require.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, stage))
+	require.Contains(t, stage.Finalizers, stageFinalizerName)
+	require.True(t, conditions.IsReady(stage))
+}
+
+func TestReconcileDeletion(t *testing.T) {
+	tests := []struct {
+		name          string
+		prune         bool
+		inUse         bool
+		instance      *apiv1.KeptnInstanceReference
+		wantStages    []string
+		wantShipyard  []string
+		wantBranch    bool
+		wantFinalizer bool
+	}{
+		{name: "remove", wantStages: []string{"dev", "prod"}, wantShipyard: []string{"dev"}, wantBranch: true},
+		{name: "prune", prune: true, wantStages: []string{"dev"}, wantShipyard: []string{"dev"}, wantBranch: false},
+		{name: "in_use", prune: true, inUse: true, wantStages: []string{"dev", "prod"}, wantBranch: true, wantFinalizer: true},
+		// the stage is never deleted in another instance than the one it has been created in
+		{name: "deleted_instance", prune: true, instance: &apiv1.KeptnInstanceReference{Name: "other", Namespace: "keptn"}, wantStages: []string{"dev", "prod"}, wantShipyard: []string{"dev"}, wantBranch: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := keptnfake.NewServer()
+			defer server.Close()
+			server.AddProject("podtato", "dev", "prod")
+			upstream := newUpstreamRepository(t, "dev", "prod")
+
+			instance := &apiv1.KeptnInstanceReference{Name: "default", Namespace: "keptn"}
+			if tt.instance != nil {
+				instance = tt.instance
+			}
+
+			now := metav1.Now()
+			objects := []client.Object{
+				&apiv1.KeptnProject{
+					ObjectMeta: metav1.ObjectMeta{Name: "podtato", Namespace: "keptn"},
+					Spec:       apiv1.KeptnProjectSpec{Repository: upstream},
+				},
+				&apiv1.KeptnStage{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "prod",
+						Namespace:         "keptn",
+						DeletionTimestamp: &now,
+						Finalizers:        []string{stageFinalizerName},
+					},
+					Spec:   apiv1.KeptnStageSpec{Project: "podtato", Prune: tt.prune},
+					Status: apiv1.KeptnStageStatus{Instance: instance},
+				},
+			}
+			if tt.inUse {
+				objects = append(objects, &apiv1.KeptnServiceDeployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "hello-prod", Namespace: "keptn"},
+					Spec:       apiv1.KeptnServiceDeploymentSpec{Project: "podtato", Service: "hello", Stage: "prod"},
+				})
+			}
+			r := newReconciler(t, server, objects...)
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "prod", Namespace: "keptn"}}
+
+			_, err := r.Reconcile(context.TODO(), req)
+			require.NoError(t, err)
+
+			require.ElementsMatch(t, tt.wantStages, server.Stages("podtato"))
+			require.Equal(t, tt.wantBranch, hasBranch(t, upstream, "prod"))
+			require.True(t, hasBranch(t, upstream, "dev"))
+
+			shipyard := &apiv1.KeptnShipyard{}
+			require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "podtato", Namespace: "keptn"}, shipyard))
+			require.ElementsMatch(t, tt.wantShipyard, stageNames(shipyard))
+
+			stage := &apiv1.KeptnStage{}
+			err = r.Client.Get(context.TODO(), req.NamespacedName, stage)
+			if tt.wantFinalizer {
+				require.NoError(t, err)
+				stalled := meta.FindStatusCondition(stage.Status.Conditions, apiv1.ConditionStalled)
+				require.NotNil(t, stalled)
+				require.Equal(t, apiv1.ReasonStageInUse, stalled.Reason)
+			} else {
+				require.True(t, errors.IsNotFound(err))
+			}
+		})
+	}
+}
+
+func TestReconcileDeletionOfMiddleStage(t *testing.T) {
+	server := keptnfake.NewServer()
+	defer server.Close()
+	server.AddProject("podtato", "dev", "hardening", "prod")
+
+	now := metav1.Now()
+	r := newReconciler(t, server,
+		&apiv1.KeptnProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "podtato", Namespace: "keptn"},
+			Spec:       apiv1.KeptnProjectSpec{Repository: newUpstreamRepository(t, "dev", "hardening", "prod")},
+		},
+		&apiv1.KeptnStage{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "hardening",
+				Namespace:         "keptn",
+				DeletionTimestamp: &now,
+				Finalizers:        []string{stageFinalizerName},
+			},
+			Spec: apiv1.KeptnStageSpec{Project: "podtato", After: "dev"},
+		},
+		&apiv1.KeptnStage{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "keptn"},
+			Spec:       apiv1.KeptnStageSpec{Project: "podtato", After: "hardening"},
+		},
+	)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "hardening", Namespace: "keptn"}}
+
+	_, err := r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+
+	// prod follows dev now
+	shipyard := &apiv1.KeptnShipyard{}
+	require.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "podtato", Namespace: "keptn"}, shipyard))
+	require.Equal(t, []string{"dev", "prod"}, stageNames(shipyard))
+
+	err = r.Client.Get(context.TODO(), req.NamespacedName, &apiv1.KeptnStage{})
+	require.True(t, errors.IsNotFound(err))
+}
+
+func TestReconcileDeletionKeepsDefaultBranch(t *testing.T) {
+	tests := []struct {
+		name          string
+		defaultBranch string
+	}{
+		// PlainInit points HEAD to master
+		{name: "remote_head", defaultBranch: ""},
+		{name: "configured_default_branch", defaultBranch: "prod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := keptnfake.NewServer()
+			defer server.Close()
+			server.AddProject("podtato", "dev", "master", "prod")
+			upstream := newUpstreamRepository(t, "dev", "master", "prod")
+
+			stage := "master"
+			if tt.defaultBranch != "" {
+				stage = tt.defaultBranch
+			}
+
+			now := metav1.Now()
+			r := newReconciler(t, server,
+				&apiv1.KeptnProject{
+					ObjectMeta: metav1.ObjectMeta{Name: "podtato", Namespace: "keptn"},
+					Spec:       apiv1.KeptnProjectSpec{Repository: upstream, DefaultBranch: tt.defaultBranch},
+				},
+				&apiv1.KeptnStage{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              stage,
+						Namespace:         "keptn",
+						DeletionTimestamp: &now,
+						Finalizers:        []string{stageFinalizerName},
+					},
+					Spec:   apiv1.KeptnStageSpec{Project: "podtato", Prune: true},
+					Status: apiv1.KeptnStageStatus{Instance: &apiv1.KeptnInstanceReference{Name: "default", Namespace: "keptn"}},
+				},
+			)
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: stage, Namespace: "keptn"}}
+
+			_, err := r.Reconcile(context.TODO(), req)
+			require.NoError(t, err)
+
+			require.NotContains(t, server.Stages("podtato"), stage)
+			require.True(t, hasBranch(t, upstream, stage))
+			require.True(t, errors.IsNotFound(r.Client.Get(context.TODO(), req.NamespacedName, &apiv1.KeptnStage{})))
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"flag"
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/controllers/keptninstancecontroller"
 	"os"
+	"time"
 
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/controllers/keptnprojectcontroller"
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/controllers/keptnscheduledexeccontroller"
@@ -31,8 +32,12 @@ import (
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/controllers/keptnsequenceexecutioncontroller"
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/controllers/keptnservicecontroller"
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/controllers/keptnservicedeploymentcontroller"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/controllers/keptnserviceresourcecontroller"
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/controllers/keptnshipyardcontroller"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/controllers/keptnslocontroller"
 	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/controllers/keptnstagecontroller"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/secrets"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -67,11 +72,17 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var keptnCacheTTL time.Duration
+	var allowLocalSecrets bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&keptnCacheTTL, "keptn-cache-ttl", keptnclient.DefaultCacheTTL,
+		"The time after which the cached projects and services of a Keptn instance are fetched again.")
+	flag.BoolVar(&allowLocalSecrets, "allow-local-secret-references", false,
+		"Allow env: and file: secrets in resources, these expose the environment and file system of the operator.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -93,34 +104,45 @@ func main() {
 		os.Exit(1)
 	}
 
+	// k8s: secret references are read directly from the API server, so Secrets don't need to be cached
+	secrets.Register(secrets.KubernetesPrefix, secrets.NewKubernetesResolver(mgr.GetAPIReader()))
+	secrets.AllowLocalReferences(allowLocalSecrets)
+
+	// all controllers share the cache, so that writes of one controller invalidate it for the others
+	keptnCache := keptnclient.NewCache(keptnCacheTTL)
+
 	if err = (&keptnshipyardcontroller.KeptnShipyardReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("keptnshipyard-controller"),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorderFor("keptnshipyard-controller"),
+		KeptnCache: keptnCache,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KeptnShipyard")
 		os.Exit(1)
 	}
 	if err = (&keptnprojectcontroller.KeptnProjectReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("keptnproject-controller"),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorderFor("keptnproject-controller"),
+		KeptnCache: keptnCache,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KeptnProject")
 		os.Exit(1)
 	}
 	if err = (&keptnsequenceexecutioncontroller.KeptnSequenceExecutionReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("keptnsequenceexecution-controller"),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorderFor("keptnsequenceexecution-controller"),
+		KeptnCache: keptnCache,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KeptnSequenceExecution")
 		os.Exit(1)
 	}
 	if err = (&keptnservicecontroller.KeptnServiceReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("keptnservice-controller"),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorderFor("keptnservice-controller"),
+		KeptnCache: keptnCache,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KeptnService")
 		os.Exit(1)
@@ -134,8 +156,10 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&keptnstagecontroller.KeptnStageReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorderFor("keptnstage-controller"),
+		KeptnCache: keptnCache,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KeptnStage")
 		os.Exit(1)
@@ -149,9 +173,10 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&keptnservicedeploymentcontroller.KeptnServiceDeploymentReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("keptnservicedeployment-controller"),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorderFor("keptnservicedeployment-controller"),
+		KeptnCache: keptnCache,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KeptnServiceDeployment")
 		os.Exit(1)
@@ -163,6 +188,23 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "KeptnInstance")
 		os.Exit(1)
 	}
+	if err = (&keptnserviceresourcecontroller.KeptnServiceResourceReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorderFor("keptnserviceresource-controller"),
+		KeptnCache: keptnCache,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KeptnServiceResource")
+		os.Exit(1)
+	}
+	if err = (&keptnslocontroller.KeptnSLOReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("keptnslo-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KeptnSLO")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
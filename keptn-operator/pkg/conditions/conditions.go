@@ -0,0 +1,94 @@
+package conditions
+
+import (
+	"context"
+	"fmt"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Object is a resource which reports its state using conditions and an observed generation
+type Object interface {
+	client.Object
+	GetConditions() []metav1.Condition
+	SetConditions([]metav1.Condition)
+	GetObservedGeneration() int64
+	SetObservedGeneration(int64)
+}
+
+// MarkReady marks the object as successfully reconciled
+func MarkReady(obj Object, reason, message string) {
+	set(obj, reason, message, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse)
+	obj.SetObservedGeneration(obj.GetGeneration())
+}
+
+// MarkReconciling marks the object as not ready yet, e.g. while it waits for a dependency
+func MarkReconciling(obj Object, reason, message string) {
+	set(obj, reason, message, metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse)
+}
+
+// MarkStalled marks the object as failed, the reconciliation will be retried
+func MarkStalled(obj Object, reason, message string) {
+	set(obj, reason, message, metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionTrue)
+	obj.SetObservedGeneration(obj.GetGeneration())
+}
+
+// Ready marks the object as successfully reconciled and updates its status if anything changed
+func Ready(ctx context.Context, c client.Client, obj Object, reason, message string) error {
+	return update(ctx, c, obj, func() { MarkReady(obj, reason, message) })
+}
+
+// Reconciling marks the object as not ready yet and updates its status if anything changed
+func Reconciling(ctx context.Context, c client.Client, obj Object, reason, message string) error {
+	return update(ctx, c, obj, func() { MarkReconciling(obj, reason, message) })
+}
+
+// Stalled marks the object as failed and updates its status if anything changed
+func Stalled(ctx context.Context, c client.Client, obj Object, reason, message string) error {
+	return update(ctx, c, obj, func() { MarkStalled(obj, reason, message) })
+}
+
+// IsReady returns true if the Ready condition of the object is true
+func IsReady(obj Object) bool {
+	return meta.IsStatusConditionTrue(obj.GetConditions(), keptnv1.ConditionReady)
+}
+
+func set(obj Object, reason, message string, ready, reconciling, stalled metav1.ConditionStatus) {
+	conditions := obj.GetConditions()
+	for _, condition := range []struct {
+		conditionType string
+		status        metav1.ConditionStatus
+	}{
+		{keptnv1.ConditionReady, ready},
+		{keptnv1.ConditionReconciling, reconciling},
+		{keptnv1.ConditionStalled, stalled},
+	} {
+		meta.SetStatusCondition(&conditions, metav1.Condition{
+			Type:               condition.conditionType,
+			Status:             condition.status,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+	obj.SetConditions(conditions)
+}
+
+func update(ctx context.Context, c client.Client, obj Object, mark func()) error {
+	conditions := make([]metav1.Condition, len(obj.GetConditions()))
+	copy(conditions, obj.GetConditions())
+	generation := obj.GetObservedGeneration()
+
+	mark()
+
+	if generation == obj.GetObservedGeneration() && equality.Semantic.DeepEqual(conditions, obj.GetConditions()) {
+		return nil
+	}
+	if err := c.Status().Update(ctx, obj); err != nil {
+		return fmt.Errorf("could not update status of %s: %w", obj.GetName(), err)
+	}
+	return nil
+}
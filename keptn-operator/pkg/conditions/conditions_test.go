@@ -0,0 +1,95 @@
+package conditions
+
+import (
+	"context"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestMark(t *testing.T) {
+	tests := []struct {
+		name                   string
+		mark                   func(obj Object, reason, message string)
+		wantReady              metav1.ConditionStatus
+		wantReconciling        metav1.ConditionStatus
+		wantStalled            metav1.ConditionStatus
+		wantObservedGeneration int64
+	}{
+		{
+			name:                   "ready",
+			mark:                   MarkReady,
+			wantReady:              metav1.ConditionTrue,
+			wantReconciling:        metav1.ConditionFalse,
+			wantStalled:            metav1.ConditionFalse,
+			wantObservedGeneration: 2,
+		},
+		{
+			name:                   "reconciling",
+			mark:                   MarkReconciling,
+			wantReady:              metav1.ConditionFalse,
+			wantReconciling:        metav1.ConditionTrue,
+			wantStalled:            metav1.ConditionFalse,
+			wantObservedGeneration: 1,
+		},
+		{
+			name:                   "stalled",
+			mark:                   MarkStalled,
+			wantReady:              metav1.ConditionFalse,
+			wantReconciling:        metav1.ConditionFalse,
+			wantStalled:            metav1.ConditionTrue,
+			wantObservedGeneration: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &keptnv1.KeptnStage{ObjectMeta: metav1.ObjectMeta{Name: "dev", Generation: 2}}
+			obj.Status.ObservedGeneration = 1
+
+			tt.mark(obj, "SomeReason", "some message")
+
+			require.Len(t, obj.Status.Conditions, 3)
+			for conditionType, want := range map[string]metav1.ConditionStatus{
+				keptnv1.ConditionReady:       tt.wantReady,
+				keptnv1.ConditionReconciling: tt.wantReconciling,
+				keptnv1.ConditionStalled:     tt.wantStalled,
+			} {
+				condition := meta.FindStatusCondition(obj.Status.Conditions, conditionType)
+				require.NotNil(t, condition)
+				require.Equal(t, want, condition.Status, conditionType)
+				require.Equal(t, "SomeReason", condition.Reason)
+				require.Equal(t, "some message", condition.Message)
+				require.Equal(t, int64(2), condition.ObservedGeneration)
+			}
+			require.Equal(t, tt.wantObservedGeneration, obj.Status.ObservedGeneration)
+			require.Equal(t, tt.wantReady == metav1.ConditionTrue, IsReady(obj))
+		})
+	}
+}
+
+func TestReady(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, keptnv1.AddToScheme(scheme))
+
+	obj := &keptnv1.KeptnStage{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "keptn", Generation: 1}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj).Build()
+
+	require.NoError(t, Reconciling(context.TODO(), c, obj, keptnv1.ReasonShipyardNotFound, "waiting"))
+	require.NoError(t, Ready(context.TODO(), c, obj, keptnv1.ReasonReconciled, "done"))
+	resourceVersion := obj.ResourceVersion
+
+	// no update is sent if nothing changed
+	require.NoError(t, Ready(context.TODO(), c, obj, keptnv1.ReasonReconciled, "done"))
+	require.Equal(t, resourceVersion, obj.ResourceVersion)
+
+	stored := &keptnv1.KeptnStage{}
+	require.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: "dev", Namespace: "keptn"}, stored))
+	require.True(t, IsReady(stored))
+	require.Equal(t, int64(1), stored.Status.ObservedGeneration)
+	require.Equal(t, "done", meta.FindStatusCondition(stored.Status.Conditions, keptnv1.ConditionReady).Message)
+}
@@ -0,0 +1,120 @@
+package gitauth
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/secrets"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"io/ioutil"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const defaultSSHUser = "git"
+
+//SSHCredentials contains the resolved SSH credentials of a git repository
+type SSHCredentials struct {
+	PrivateKey string
+	Passphrase string
+	KnownHosts string
+	// InsecureSkipHostKeyVerification accepts every host key if no KnownHosts are given
+	InsecureSkipHostKeyVerification bool
+}
+
+//ResolveSSHCredentials resolves the private key and passphrase of the given SSH credentials, returns empty credentials if none are given
+func ResolveSSHCredentials(ctx context.Context, credentials *apiv1.GitSSHCredentials, namespace string) (SSHCredentials, error) {
+	if credentials == nil {
+		return SSHCredentials{}, nil
+	}
+
+	privateKey, err := secrets.ResolveInNamespace(ctx, secrets.Reference(credentials.PrivateKeySecretRef, credentials.PrivateKey, namespace), namespace)
+	if err != nil {
+		return SSHCredentials{}, fmt.Errorf("could not resolve ssh private key: %w", err)
+	}
+
+	passphrase, err := secrets.ResolveInNamespace(ctx, credentials.Passphrase, namespace)
+	if err != nil {
+		return SSHCredentials{}, fmt.Errorf("could not resolve ssh passphrase: %w", err)
+	}
+
+	if credentials.KnownHosts == "" && credentials.InsecureSkipHostKeyVerification {
+		log.FromContext(ctx).Info("WARNING: host key verification is disabled, the identity of the git remote is not verified")
+	}
+
+	return SSHCredentials{
+		PrivateKey:                      privateKey,
+		Passphrase:                      passphrase,
+		KnownHosts:                      credentials.KnownHosts,
+		InsecureSkipHostKeyVerification: credentials.InsecureSkipHostKeyVerification,
+	}, nil
+}
+
+//NewAuthMethod returns the authentication method for a git remote, SSH is used if a private key is given, HTTP basic auth otherwise
+func NewAuthMethod(remoteURI, user, token string, sshCredentials SSHCredentials) (transport.AuthMethod, error) {
+	if sshCredentials.PrivateKey == "" {
+		return &githttp.BasicAuth{
+			Username: user,
+			Password: token,
+		}, nil
+	}
+
+	// the user of the remote (e.g. git@github.com:...) takes precedence, as it is the one which is authorized by the key
+	endpoint, err := transport.NewEndpoint(remoteURI)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse remote %s: %w", remoteURI, err)
+	}
+	if endpoint.User != "" {
+		user = endpoint.User
+	}
+	if user == "" {
+		user = defaultSSHUser
+	}
+
+	auth, err := gitssh.NewPublicKeys(user, []byte(sshCredentials.PrivateKey), sshCredentials.Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse ssh private key: %w", err)
+	}
+
+	auth.HostKeyCallback, err = newHostKeyCallback(sshCredentials.KnownHosts, sshCredentials.InsecureSkipHostKeyVerification)
+	if err != nil {
+		return nil, err
+	}
+	return auth, nil
+}
+
+//newHostKeyCallback creates a callback verifying host keys against the given known_hosts entries, every host key is only
+//accepted if there are none and verification has explicitly been disabled
+func newHostKeyCallback(knownHosts string, insecureSkipVerification bool) (ssh.HostKeyCallback, error) {
+	if knownHosts == "" {
+		if !insecureSkipVerification {
+			return nil, fmt.Errorf("no known_hosts given, host key verification has to be disabled explicitly")
+		}
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	// knownhosts can only read files, the content is read when the callback is created
+	file, err := ioutil.TempFile("", "known_hosts")
+	if err != nil {
+		return nil, fmt.Errorf("could not create known_hosts file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(knownHosts)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not write known_hosts file: %w", err)
+	}
+
+	callback, err := knownhosts.New(file.Name())
+	if err != nil {
+		return nil, fmt.Errorf("could not parse known_hosts: %w", err)
+	}
+	return callback, nil
+}
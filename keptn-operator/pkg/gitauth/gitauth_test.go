@@ -0,0 +1,240 @@
+package gitauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"net"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func generateKey(t *testing.T) (string, ssh.Signer) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return string(keyPem), signer
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(cmd.Env, "GIT_AUTHOR_NAME=keptn", "GIT_AUTHOR_EMAIL=keptn@keptn.sh", "GIT_COMMITTER_NAME=keptn", "GIT_COMMITTER_EMAIL=keptn@keptn.sh", "HOME="+dir)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+// startGitSSHServer starts a minimal ssh server which only serves git-upload-pack for clients using the authorized key
+func startGitSSHServer(t *testing.T, hostKey ssh.Signer, authorizedKey ssh.PublicKey) string {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "git" && string(key.Marshal()) == string(authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unauthorized key for %s", conn.User())
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveGitSSHConn(conn, config)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func serveGitSSHConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			for req := range channelRequests {
+				if req.Type != "exec" {
+					req.Reply(false, nil)
+					continue
+				}
+				var payload struct{ Command string }
+				if err := ssh.Unmarshal(req.Payload, &payload); err != nil || !strings.HasPrefix(payload.Command, "git-upload-pack ") {
+					req.Reply(false, nil)
+					continue
+				}
+				req.Reply(true, nil)
+
+				cmd := exec.Command("git-upload-pack", strings.Trim(strings.TrimPrefix(payload.Command, "git-upload-pack "), "'"))
+				cmd.Stdin = channel
+				cmd.Stdout = channel
+				cmd.Stderr = channel.Stderr()
+				status := struct{ Status uint32 }{}
+				if err := cmd.Run(); err != nil {
+					status.Status = 1
+				}
+				channel.SendRequest("exit-status", false, ssh.Marshal(&status))
+				channel.Close()
+			}
+		}()
+	}
+}
+
+func TestNewAuthMethodSSH(t *testing.T) {
+	repoDir := t.TempDir()
+	runGit(t, repoDir, "init", "-b", "main")
+	runGit(t, repoDir, "commit", "--allow-empty", "-m", "initial commit")
+
+	clientKey, clientSigner := generateKey(t)
+	otherKey, _ := generateKey(t)
+	_, hostSigner := generateKey(t)
+	_, otherHostSigner := generateKey(t)
+
+	addr := startGitSSHServer(t, hostSigner, clientSigner.PublicKey())
+	remoteURI := "ssh://git@" + addr + repoDir
+
+	tests := []struct {
+		name    string
+		ssh     SSHCredentials
+		wantErr bool
+	}{
+		{
+			name: "private key with disabled host key verification",
+			ssh:  SSHCredentials{PrivateKey: clientKey, InsecureSkipHostKeyVerification: true},
+		},
+		{
+			name: "private key with matching known_hosts",
+			ssh:  SSHCredentials{PrivateKey: clientKey, KnownHosts: knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostSigner.PublicKey())},
+		},
+		{
+			name:    "private key with other host key in known_hosts",
+			ssh:     SSHCredentials{PrivateKey: clientKey, KnownHosts: knownhosts.Line([]string{knownhosts.Normalize(addr)}, otherHostSigner.PublicKey())},
+			wantErr: true,
+		},
+		{
+			name:    "unauthorized private key",
+			ssh:     SSHCredentials{PrivateKey: otherKey, KnownHosts: knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostSigner.PublicKey())},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, err := NewAuthMethod(remoteURI, "keptn", "", tt.ssh)
+			require.NoError(t, err)
+
+			_, err = git.PlainClone(filepath.Join(t.TempDir(), "clone"), false, &git.CloneOptions{
+				URL:  remoteURI,
+				Auth: auth,
+			})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewAuthMethod(t *testing.T) {
+	privateKey, _ := generateKey(t)
+
+	tests := []struct {
+		name      string
+		remoteURI string
+		user      string
+		ssh       SSHCredentials
+		wantUser  string
+		wantErr   bool
+	}{
+		{
+			name:      "basic auth without private key",
+			remoteURI: "https://github.com/keptn/podtato-head",
+			user:      "keptn",
+		},
+		{
+			name:      "user of the remote",
+			remoteURI: "git@github.com:keptn/podtato-head.git",
+			user:      "keptn",
+			ssh:       SSHCredentials{PrivateKey: privateKey, InsecureSkipHostKeyVerification: true},
+			wantUser:  "git",
+		},
+		{
+			name:      "configured user",
+			remoteURI: "ssh://github.com/keptn/podtato-head.git",
+			user:      "keptn",
+			ssh:       SSHCredentials{PrivateKey: privateKey, InsecureSkipHostKeyVerification: true},
+			wantUser:  "keptn",
+		},
+		{
+			name:      "default user",
+			remoteURI: "ssh://github.com/keptn/podtato-head.git",
+			ssh:       SSHCredentials{PrivateKey: privateKey, InsecureSkipHostKeyVerification: true},
+			wantUser:  "git",
+		},
+		{
+			name:      "invalid private key",
+			remoteURI: "git@github.com:keptn/podtato-head.git",
+			ssh:       SSHCredentials{PrivateKey: "not a key"},
+			wantErr:   true,
+		},
+		{
+			name:      "missing known_hosts",
+			remoteURI: "git@github.com:keptn/podtato-head.git",
+			ssh:       SSHCredentials{PrivateKey: privateKey},
+			wantErr:   true,
+		},
+		{
+			name:      "invalid known_hosts",
+			remoteURI: "git@github.com:keptn/podtato-head.git",
+			ssh:       SSHCredentials{PrivateKey: privateKey, KnownHosts: "github.com ssh-rsa invalid"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, err := NewAuthMethod(tt.remoteURI, tt.user, "token", tt.ssh)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.wantUser == "" {
+				require.Equal(t, &githttp.BasicAuth{Username: tt.user, Password: "token"}, auth)
+				return
+			}
+			publicKeys, ok := auth.(*gitssh.PublicKeys)
+			require.True(t, ok)
+			require.Equal(t, tt.wantUser, publicKeys.User)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package keptnclient
+
+import (
+	"context"
+	"github.com/keptn/go-utils/pkg/api/models"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is the time after which the cached state of a Keptn instance is fetched again
+const DefaultCacheTTL = 30 * time.Second
+
+// Cache keeps the projects, stages and services of Keptn instances in memory, so that controllers don't have to
+// query all projects and services on every reconcile. The state of an instance is fetched with a single request and
+// refreshed once it is older than the TTL. A nil Cache is valid and queries the Keptn API on every call.
+type Cache struct {
+	// TTL is the time after which the state of an instance is fetched again
+	TTL time.Duration
+
+	mu        sync.Mutex
+	instances map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	mu       sync.Mutex
+	fetched  time.Time
+	projects map[string]*models.Project
+}
+
+// NewCache returns an empty Cache, DefaultCacheTTL is used if ttl is not positive
+func NewCache(ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Cache{TTL: ttl, instances: map[string]*cacheEntry{}}
+}
+
+// Project returns a project including its stages and services, nil is returned if the project does not exist
+func (c *Cache) Project(ctx context.Context, client *Client, project string) (*models.Project, error) {
+	if c == nil {
+		result, err := client.GetProject(ctx, project)
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return result, err
+	}
+
+	projects, err := c.projects(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return projects[project], nil
+}
+
+// ProjectExists returns true if the project exists in Keptn
+func (c *Cache) ProjectExists(ctx context.Context, client *Client, project string) (bool, error) {
+	result, err := c.Project(ctx, client, project)
+	return result != nil, err
+}
+
+// ServiceExists returns true if the service exists in one of the stages of the project
+func (c *Cache) ServiceExists(ctx context.Context, client *Client, project string, service string) (bool, error) {
+	if c == nil {
+		return client.ServiceExists(ctx, project, service)
+	}
+
+	result, err := c.Project(ctx, client, project)
+	if err != nil || result == nil {
+		return false, err
+	}
+	for _, stage := range result.Stages {
+		for _, s := range stage.Services {
+			if s.ServiceName == service {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// Invalidate drops the cached state of the Keptn instance of the client, it has to be called after projects or services have been changed
+func (c *Cache) Invalidate(client *Client) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.instances, client.BaseURL)
+}
+
+//projects returns the cached projects of the instance of the client and fetches them if they are missing or outdated
+func (c *Cache) projects(ctx context.Context, client *Client) (map[string]*models.Project, error) {
+	c.mu.Lock()
+	if c.instances == nil {
+		c.instances = map[string]*cacheEntry{}
+	}
+	entry, ok := c.instances[client.BaseURL]
+	if !ok {
+		entry = &cacheEntry{}
+		c.instances[client.BaseURL] = entry
+	}
+	c.mu.Unlock()
+
+	// concurrent reconciles of the same instance wait for a single refresh
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.projects != nil && time.Since(entry.fetched) < c.TTL {
+		return entry.projects, nil
+	}
+
+	projects, err := client.GetProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry.projects = make(map[string]*models.Project, len(projects))
+	for _, project := range projects {
+		entry.projects[project.ProjectName] = project
+	}
+	entry.fetched = time.Now()
+	return entry.projects, nil
+}
@@ -0,0 +1,95 @@
+package keptnclient_test
+
+import (
+	"context"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient/fake"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func countRequests(server *fake.Server, request string) int {
+	count := 0
+	for _, r := range server.Requests() {
+		if r == request {
+			count++
+		}
+	}
+	return count
+}
+
+func TestCache(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.Token = "my-token"
+	server.AddProject("podtato-head", "dev", "production")
+	server.AddService("podtato-head", "helloservice")
+	client := newTestClient(server)
+	cache := keptnclient.NewCache(time.Hour)
+	ctx := context.TODO()
+
+	for _, service := range []string{"helloservice", "helloservice", "other"} {
+		exists, err := cache.ServiceExists(ctx, client, "podtato-head", service)
+		require.NoError(t, err)
+		require.Equal(t, service == "helloservice", exists)
+	}
+	exists, err := cache.ProjectExists(ctx, client, "notexisting")
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.Equal(t, 1, countRequests(server, "GET /controlPlane/v1/project"))
+
+	// changes of others are not visible until the cache expires or is invalidated
+	require.NoError(t, client.CreateService(ctx, "podtato-head", "other"))
+	exists, err = cache.ServiceExists(ctx, client, "podtato-head", "other")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	cache.Invalidate(client)
+	exists, err = cache.ServiceExists(ctx, client, "podtato-head", "other")
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Equal(t, 2, countRequests(server, "GET /controlPlane/v1/project"))
+}
+
+func TestCacheExpires(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddProject("podtato-head", "dev")
+	client := newTestClient(server)
+	cache := keptnclient.NewCache(time.Millisecond)
+	ctx := context.TODO()
+
+	exists, err := cache.ProjectExists(ctx, client, "podtato-head")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	time.Sleep(5 * time.Millisecond)
+	_, err = cache.ProjectExists(ctx, client, "podtato-head")
+	require.NoError(t, err)
+	require.Equal(t, 2, countRequests(server, "GET /controlPlane/v1/project"))
+}
+
+func TestNilCache(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddProject("podtato-head", "dev")
+	server.AddService("podtato-head", "helloservice")
+	client := newTestClient(server)
+	var cache *keptnclient.Cache
+	ctx := context.TODO()
+
+	exists, err := cache.ProjectExists(ctx, client, "podtato-head")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	exists, err = cache.ProjectExists(ctx, client, "notexisting")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	exists, err = cache.ServiceExists(ctx, client, "podtato-head", "helloservice")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	cache.Invalidate(client)
+}
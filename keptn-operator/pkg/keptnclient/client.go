@@ -0,0 +1,192 @@
+package keptnclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"io"
+	nethttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout is the timeout of a single request to the Keptn API
+	DefaultTimeout = 30 * time.Second
+	// DefaultMaxRetries is the number of times a failed request is retried
+	DefaultMaxRetries = 3
+	// DefaultInitialBackoff is the time to wait before the first retry, it is doubled for every further retry
+	DefaultInitialBackoff = 500 * time.Millisecond
+
+	controlPlanePath         = "/controlPlane/v1"
+	datastorePath            = "/mongodb-datastore"
+	configurationServicePath = "/configuration-service/v1"
+)
+
+// Client is a client for the Keptn API
+type Client struct {
+	// BaseURL is the URL of the Keptn API, e.g. http://keptn.example.com/api
+	BaseURL string
+	// AuthHeader is the name of the header the token is sent in
+	AuthHeader string
+	// Token is the Keptn API token
+	Token string
+	// HTTPClient is used to send the requests
+	HTTPClient *nethttp.Client
+	// Timeout is the timeout of a single request
+	Timeout time.Duration
+	// MaxRetries is the number of times a request is retried if the Keptn API is not available
+	MaxRetries int
+	// InitialBackoff is the time to wait before the first retry
+	InitialBackoff time.Duration
+}
+
+// APIError is returned if the Keptn API responds with an unexpected status code
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Message is the error message returned by the Keptn API
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("keptn api returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound returns true if the error is an APIError with status code 404
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == nethttp.StatusNotFound
+}
+
+// NewClient returns a new Client for the Keptn API
+func NewClient(baseURL string, authHeader string, token string) *Client {
+	if authHeader == "" {
+		authHeader = "x-token"
+	}
+	return &Client{
+		BaseURL:        strings.TrimRight(baseURL, "/"),
+		AuthHeader:     authHeader,
+		Token:          token,
+		HTTPClient:     &nethttp.Client{},
+		Timeout:        DefaultTimeout,
+		MaxRetries:     DefaultMaxRetries,
+		InitialBackoff: DefaultInitialBackoff,
+	}
+}
+
+// NewClientForInstance returns a new Client for the Keptn API of a KeptnInstance
+func NewClientForInstance(instance keptnv1.KeptnInstance, token string) *Client {
+	return NewClient(instance.Spec.APIUrl, instance.Status.AuthHeader, token)
+}
+
+//do sends a request to the Keptn API and decodes the response into out, if it is not nil
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("could not marshal request: %w", err)
+		}
+	}
+
+	requestURL := c.BaseURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	backoff := c.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		respBody, retryAfter, err := c.send(ctx, method, requestURL, payload)
+		if err == nil {
+			if out == nil || len(respBody) == 0 {
+				return nil
+			}
+			if err := json.Unmarshal(respBody, out); err != nil {
+				return fmt.Errorf("could not decode response of %s %s: %w", method, path, err)
+			}
+			return nil
+		}
+
+		if attempt >= c.MaxRetries || !c.retryable(method, err) {
+			return fmt.Errorf("%s %s failed: %w", method, path, err)
+		}
+
+		wait := backoff
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s %s failed: %w", method, path, ctx.Err())
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+//send executes a single request, the Retry-After header is returned for rate limited requests
+func (c *Client) send(ctx context.Context, method string, requestURL string, payload []byte) ([]byte, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	request, err := nethttp.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, 0, err
+	}
+	request.Header.Set("content-type", "application/json")
+	request.Header.Set(c.AuthHeader, c.Token)
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer response.Body.Close()
+
+	respBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		retryAfter := time.Duration(0)
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, retryAfter, &APIError{StatusCode: response.StatusCode, Message: errorMessage(respBody)}
+	}
+	return respBody, 0, nil
+}
+
+//retryable returns true for errors caused by an unavailable or overloaded Keptn API
+func (c *Client) retryable(method string, err error) bool {
+	idempotent := method == nethttp.MethodGet || method == nethttp.MethodDelete
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		// rejected requests have not been processed, other server errors may occur after the request has been processed
+		if apiErr.StatusCode == nethttp.StatusTooManyRequests || apiErr.StatusCode == nethttp.StatusServiceUnavailable {
+			return true
+		}
+		return apiErr.StatusCode >= 500 && idempotent
+	}
+	// the request may have been processed if the connection failed, only repeat idempotent requests
+	return idempotent
+}
+
+//errorMessage extracts the message of an error response of the Keptn API
+func errorMessage(body []byte) string {
+	response := struct {
+		Message string `json:"message"`
+	}{}
+	if err := json.Unmarshal(body, &response); err == nil && response.Message != "" {
+		return response.Message
+	}
+	return strings.TrimSpace(string(body))
+}
@@ -0,0 +1,188 @@
+package keptnclient_test
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient/fake"
+	"github.com/stretchr/testify/require"
+	nethttp "net/http"
+	"testing"
+	"time"
+)
+
+func newTestClient(server *fake.Server) *keptnclient.Client {
+	client := keptnclient.NewClient(server.URL, "x-token", "my-token")
+	client.InitialBackoff = time.Millisecond
+	return client
+}
+
+func TestProjectsAndServices(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.Token = "my-token"
+	client := newTestClient(server)
+	ctx := context.TODO()
+
+	exists, err := client.ProjectExists(ctx, "podtato-head")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	shipyard := base64.StdEncoding.EncodeToString([]byte("apiVersion: spec.keptn.sh/0.2.0\nkind: Shipyard\nspec:\n  stages:\n    - name: dev\n    - name: production\n"))
+	require.NoError(t, client.CreateProject(ctx, keptnclient.CreateProjectRequest{Name: "podtato-head", Shipyard: shipyard, GitRemoteURL: "https://git.example.com/upstream"}))
+
+	exists, err = client.ProjectExists(ctx, "podtato-head")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	require.NoError(t, client.UpdateProject(ctx, keptnclient.UpdateProjectRequest{Name: "podtato-head", GitRemoteURL: "https://git.example.com/moved", GitUser: "keptn", GitToken: "rotated"}))
+	project, ok := server.Project("podtato-head")
+	require.True(t, ok)
+	require.Equal(t, "https://git.example.com/moved", project.GitRemoteURL)
+	require.Equal(t, "rotated", project.GitToken)
+
+	err = client.UpdateProject(ctx, keptnclient.UpdateProjectRequest{Name: "notexisting"})
+	require.True(t, keptnclient.IsNotFound(err))
+
+	stages, err := client.GetStages(ctx, "podtato-head")
+	require.NoError(t, err)
+	require.Len(t, stages, 2)
+	require.Equal(t, "dev", stages[0].StageName)
+
+	require.NoError(t, client.CreateService(ctx, "podtato-head", "helloservice"))
+	exists, err = client.ServiceExists(ctx, "podtato-head", "helloservice")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	require.NoError(t, client.DeleteService(ctx, "podtato-head", "helloservice"))
+	require.Empty(t, server.Services("podtato-head"))
+
+	err = client.DeleteService(ctx, "podtato-head", "helloservice")
+	require.True(t, keptnclient.IsNotFound(err))
+
+	require.NoError(t, client.DeleteProject(ctx, "podtato-head"))
+	require.Empty(t, server.Projects())
+}
+
+func TestEvents(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	client := newTestClient(server)
+	ctx := context.TODO()
+
+	keptnContext, err := client.SendEvent(ctx, keptnclient.NewTriggeredEvent("dev.delivery", keptnclient.EventData{Project: "podtato-head", Stage: "dev", Service: "helloservice"}))
+	require.NoError(t, err)
+	require.NotEmpty(t, keptnContext)
+
+	_, err = client.SendEvent(ctx, keptnclient.NewTriggeredEvent("dev.delivery", keptnclient.EventData{Project: "other", Stage: "dev", Service: "helloservice"}))
+	require.NoError(t, err)
+
+	events, err := client.GetEvents(ctx, keptnclient.EventFilter{Project: "podtato-head", KeptnContext: keptnContext})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "sh.keptn.event.dev.delivery.triggered", *events[0].Type)
+	require.Equal(t, keptnclient.EventSource, server.SentEvents()[0].Source)
+}
+
+func TestSequenceState(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	client := newTestClient(server)
+
+	server.AddSequenceState(keptnclient.SequenceState{Name: "delivery", Project: "podtato-head", Shkeptncontext: "ctx-1", State: "finished"})
+
+	state, err := client.GetSequenceState(context.TODO(), "podtato-head", "ctx-1")
+	require.NoError(t, err)
+	require.Equal(t, "finished", state.State)
+
+	state, err = client.GetSequenceState(context.TODO(), "podtato-head", "ctx-2")
+	require.NoError(t, err)
+	require.Nil(t, state)
+}
+
+func TestProjectResources(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddProject("podtato-head", "dev")
+	client := newTestClient(server)
+	ctx := context.TODO()
+
+	_, err := client.GetProjectResource(ctx, "podtato-head", "shipyard.yaml")
+	require.True(t, keptnclient.IsNotFound(err))
+
+	shipyard := "apiVersion: spec.keptn.sh/0.2.2\nkind: Shipyard\n"
+	require.NoError(t, client.UploadProjectResource(ctx, "podtato-head", "shipyard.yaml", []byte(shipyard)))
+
+	content, err := client.GetProjectResource(ctx, "podtato-head", "shipyard.yaml")
+	require.NoError(t, err)
+	require.Equal(t, shipyard, string(content))
+	uploaded, ok := server.Resource("podtato-head", "shipyard.yaml")
+	require.True(t, ok)
+	require.Equal(t, shipyard, uploaded)
+
+	require.True(t, keptnclient.IsNotFound(client.UploadProjectResource(ctx, "sockshop", "shipyard.yaml", []byte(shipyard))))
+
+	server.AddService("podtato-head", "hello")
+	require.NoError(t, client.UploadServiceResource(ctx, "podtato-head", "dev", "hello", "jmeter/load.jmx", []byte("<jmeterTestPlan/>")))
+	script, ok := server.ServiceResource("podtato-head", "dev", "hello", "jmeter/load.jmx")
+	require.True(t, ok)
+	require.Equal(t, "<jmeterTestPlan/>", script)
+	require.NoError(t, client.DeleteServiceResource(ctx, "podtato-head", "dev", "hello", "jmeter/load.jmx"))
+	require.True(t, keptnclient.IsNotFound(client.DeleteServiceResource(ctx, "podtato-head", "dev", "hello", "jmeter/load.jmx")))
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		statusCode   int
+		failures     int
+		wantErr      bool
+		wantRequests int
+	}{
+		{name: "retry_get_on_server_error", method: nethttp.MethodGet, statusCode: nethttp.StatusBadGateway, failures: 2, wantRequests: 3},
+		{name: "no_retry_post_on_server_error", method: nethttp.MethodPost, statusCode: nethttp.StatusBadGateway, failures: 1, wantErr: true, wantRequests: 1},
+		{name: "retry_post_on_rate_limit", method: nethttp.MethodPost, statusCode: nethttp.StatusTooManyRequests, failures: 1, wantRequests: 2},
+		{name: "retry_post_on_unavailable", method: nethttp.MethodPost, statusCode: nethttp.StatusServiceUnavailable, failures: 2, wantRequests: 3},
+		{name: "give_up_after_max_retries", method: nethttp.MethodPost, statusCode: nethttp.StatusServiceUnavailable, failures: 10, wantErr: true, wantRequests: 4},
+		{name: "no_retry_on_client_error", method: nethttp.MethodGet, statusCode: nethttp.StatusBadRequest, failures: 1, wantErr: true, wantRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fake.NewServer()
+			defer server.Close()
+			client := newTestClient(server)
+			server.AddProject("podtato-head", "dev")
+
+			var err error
+			if tt.method == nethttp.MethodGet {
+				server.FailRequests(nethttp.MethodGet, "/controlPlane/v1/project/podtato-head", tt.statusCode, tt.failures)
+				_, err = client.GetProject(context.TODO(), "podtato-head")
+			} else {
+				server.FailRequests(nethttp.MethodPost, "/controlPlane/v1/project/podtato-head/service", tt.statusCode, tt.failures)
+				err = client.CreateService(context.TODO(), "podtato-head", "helloservice")
+			}
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, server.Requests(), tt.wantRequests)
+		})
+	}
+}
+
+func TestRetriesRespectContext(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	client := newTestClient(server)
+	client.InitialBackoff = time.Hour
+
+	server.FailRequests(nethttp.MethodGet, "/controlPlane/v1/project/podtato-head", nethttp.StatusServiceUnavailable, 1)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetProject(ctx, "podtato-head")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,100 @@
+package keptnclient
+
+import (
+	"context"
+	"github.com/keptn/go-utils/pkg/api/models"
+	nethttp "net/http"
+	"net/url"
+)
+
+// EventSource is the source of all events sent by the operator
+const EventSource = "Keptn GitOps Operator"
+
+// Event describes a Keptn Event which should be sent
+type Event struct {
+	ID          string    `json:"id,omitempty"`
+	ContentType string    `json:"contenttype,omitempty"`
+	Data        EventData `json:"data,omitempty"`
+	Source      string    `json:"source,omitempty"`
+	SpecVersion string    `json:"specversion,omitempty"`
+	Type        string    `json:"type,omitempty"`
+	Context     string    `json:"shkeptncontext,omitempty"`
+}
+
+// EventData describes the data of an Event
+type EventData struct {
+	Project             string                  `json:"project,omitempty"`
+	Service             string                  `json:"service,omitempty"`
+	Stage               string                  `json:"stage,omitempty"`
+	Image               string                  `json:"image,omitempty"`
+	Labels              map[string]string       `json:"labels,omitempty"`
+	ConfigurationChange ConfigurationChangeData `json:"configurationChange,omitempty"`
+}
+
+// ConfigurationChangeData describes the configuration change block of an EventData
+type ConfigurationChangeData struct {
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// EventFilter restricts the events returned by GetEvents
+type EventFilter struct {
+	Project      string
+	Stage        string
+	Service      string
+	Type         string
+	KeptnContext string
+}
+
+// NewTriggeredEvent returns a .triggered event of the given type
+func NewTriggeredEvent(eventType string, data EventData) Event {
+	return Event{
+		ContentType: "application/json",
+		Data:        data,
+		Source:      EventSource,
+		SpecVersion: "1.0",
+		Type:        "sh.keptn.event." + eventType + ".triggered",
+	}
+}
+
+// SendEvent sends an event to Keptn and returns the Keptn context of the event
+func (c *Client) SendEvent(ctx context.Context, event Event) (string, error) {
+	response := struct {
+		KeptnContext string `json:"keptnContext"`
+	}{}
+	err := c.do(ctx, nethttp.MethodPost, "/v1/event", nil, event, &response)
+	if err != nil {
+		return "", err
+	}
+	return response.KeptnContext, nil
+}
+
+// GetEvents returns all events matching the filter
+func (c *Client) GetEvents(ctx context.Context, filter EventFilter) ([]*models.KeptnContextExtendedCE, error) {
+	query := url.Values{}
+	for key, value := range map[string]string{
+		"project":      filter.Project,
+		"stage":        filter.Stage,
+		"service":      filter.Service,
+		"type":         filter.Type,
+		"keptnContext": filter.KeptnContext,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	events := []*models.KeptnContextExtendedCE{}
+	for {
+		page := &models.Events{}
+		err := c.do(ctx, nethttp.MethodGet, datastorePath+"/event", query, nil, page)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page.Events...)
+
+		if page.NextPageKey == "" || page.NextPageKey == "0" {
+			return events, nil
+		}
+		query.Set("nextPageKey", page.NextPageKey)
+	}
+}
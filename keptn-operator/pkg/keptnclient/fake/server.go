@@ -0,0 +1,575 @@
+package fake
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
+	"github.com/keptn/go-utils/pkg/api/models"
+	"gopkg.in/yaml.v3"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is an in-process fake of the Keptn API which keeps projects, services and events in memory
+type Server struct {
+	*httptest.Server
+
+	// Token is the expected API token, requests with another token are rejected if it is set
+	Token string
+
+	mu             sync.Mutex
+	projects       map[string]*project
+	events         []*models.KeptnContextExtendedCE
+	sentEvents     []keptnclient.Event
+	sequenceStates []keptnclient.SequenceState
+	failures       []*failure
+	requests       []string
+	contextCounter int
+}
+
+type project struct {
+	request   keptnclient.CreateProjectRequest
+	stages    []string
+	services  []string
+	resources map[string]string
+	// serviceResources are keyed by stage, service and resourceURI separated by slashes
+	serviceResources map[string]string
+}
+
+type failure struct {
+	method     string
+	path       string
+	statusCode int
+	remaining  int
+}
+
+// NewServer starts a new fake Keptn API, it has to be closed after usage
+func NewServer() *Server {
+	s := &Server{projects: map[string]*project{}}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// AddProject adds a project with the given stages
+func (s *Server) AddProject(name string, stages ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projects[name] = &project{request: keptnclient.CreateProjectRequest{Name: name}, stages: stages}
+}
+
+// AddService adds a service to an existing project
+func (s *Server) AddService(projectName string, service string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.projects[projectName]; ok {
+		p.services = append(p.services, service)
+	}
+}
+
+// AddEvent adds an event which is returned by the event API
+func (s *Server) AddEvent(event *models.KeptnContextExtendedCE) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+// AddSequenceState adds a state which is returned by the sequence state API
+func (s *Server) AddSequenceState(state keptnclient.SequenceState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sequenceStates = append(s.sequenceStates, state)
+}
+
+// FailRequests lets the next requests with the given method and path fail with the status code
+func (s *Server) FailRequests(method string, path string, statusCode int, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &failure{method: method, path: path, statusCode: statusCode, remaining: times})
+}
+
+// Projects returns the names of all projects
+func (s *Server) Projects() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := []string{}
+	for name := range s.projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Project returns the request a project has been created with
+func (s *Server) Project(name string) (keptnclient.CreateProjectRequest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.projects[name]
+	if !ok {
+		return keptnclient.CreateProjectRequest{}, false
+	}
+	return p.request, true
+}
+
+// Services returns the services of a project
+func (s *Server) Services(projectName string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.projects[projectName]; ok {
+		return append([]string{}, p.services...)
+	}
+	return nil
+}
+
+// Stages returns the stages of a project
+func (s *Server) Stages(projectName string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.projects[projectName]; ok {
+		return append([]string{}, p.stages...)
+	}
+	return nil
+}
+
+// Resource returns the decoded content of a resource of a project
+func (s *Server) Resource(projectName string, resourceURI string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.projects[projectName]
+	if !ok {
+		return "", false
+	}
+	content, ok := p.resources[resourceURI]
+	if !ok {
+		return "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return "", false
+	}
+	return string(decoded), true
+}
+
+// ServiceResource returns the decoded content of a resource of a service in a stage
+func (s *Server) ServiceResource(projectName string, stage string, service string, resourceURI string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.projects[projectName]
+	if !ok {
+		return "", false
+	}
+	content, ok := p.serviceResources[stage+"/"+service+"/"+resourceURI]
+	if !ok {
+		return "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return "", false
+	}
+	return string(decoded), true
+}
+
+// SentEvents returns all events which have been sent to the fake
+func (s *Server) SentEvents() []keptnclient.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]keptnclient.Event{}, s.sentEvents...)
+}
+
+// Requests returns all received requests as "METHOD path"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+// ServeHTTP handles the requests to the fake
+func (s *Server) ServeHTTP(w nethttp.ResponseWriter, r *nethttp.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if s.Token != "" && r.Header.Get("x-token") != s.Token {
+		writeError(w, nethttp.StatusUnauthorized, "invalid token")
+		return
+	}
+
+	for _, f := range s.failures {
+		if f.remaining > 0 && f.method == r.Method && f.path == r.URL.Path {
+			f.remaining--
+			writeError(w, f.statusCode, "injected failure")
+			return
+		}
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	segments := strings.Split(path, "/")
+
+	switch {
+	case path == "v1/event" && r.Method == nethttp.MethodPost:
+		s.sendEvent(w, r)
+	case path == "mongodb-datastore/event" && r.Method == nethttp.MethodGet:
+		s.getEvents(w, r)
+	case strings.HasPrefix(path, "controlPlane/v1/project"):
+		s.serveProjects(w, r, segments[2:])
+	case strings.HasPrefix(path, "controlPlane/v1/sequence/"):
+		s.serveSequences(w, r, segments[3:])
+	case len(segments) == 6 && strings.HasPrefix(path, "configuration-service/v1/project/") && segments[4] == "stage" && r.Method == nethttp.MethodDelete:
+		s.deleteStage(w, segments[3], segments[5])
+	case len(segments) >= 9 && strings.HasPrefix(path, "configuration-service/v1/project/") && segments[4] == "stage" && segments[6] == "service" && segments[8] == "resource":
+		s.serveServiceResources(w, r, segments[3], segments[5], segments[7], strings.Join(segments[9:], "/"))
+	case len(segments) >= 5 && strings.HasPrefix(path, "configuration-service/v1/project/") && segments[4] == "resource":
+		s.serveResources(w, r, segments[3], strings.Join(segments[5:], "/"))
+	default:
+		writeError(w, nethttp.StatusNotFound, "unknown path "+r.URL.Path)
+	}
+}
+
+//serveProjects handles the paths below /controlPlane/v1, segments starts with "project"
+func (s *Server) serveProjects(w nethttp.ResponseWriter, r *nethttp.Request, segments []string) {
+	switch {
+	case len(segments) == 1 && r.Method == nethttp.MethodGet:
+		projects := &models.Projects{Projects: []*models.Project{}}
+		for _, name := range sortedKeys(s.projects) {
+			projects.Projects = append(projects.Projects, s.projects[name].model(name))
+		}
+		writeJSON(w, projects)
+	case len(segments) == 1 && r.Method == nethttp.MethodPost:
+		s.createProject(w, r)
+	case len(segments) == 1 && r.Method == nethttp.MethodPut:
+		s.updateProject(w, r)
+	case len(segments) >= 2:
+		p, ok := s.projects[segments[1]]
+		if !ok {
+			writeError(w, nethttp.StatusNotFound, "project "+segments[1]+" not found")
+			return
+		}
+		s.serveProject(w, r, segments[1], p, segments[2:])
+	default:
+		writeError(w, nethttp.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) serveProject(w nethttp.ResponseWriter, r *nethttp.Request, name string, p *project, segments []string) {
+	switch {
+	case len(segments) == 0 && r.Method == nethttp.MethodGet:
+		writeJSON(w, p.model(name))
+	case len(segments) == 0 && r.Method == nethttp.MethodDelete:
+		delete(s.projects, name)
+		writeJSON(w, map[string]string{})
+	case len(segments) == 1 && segments[0] == "stage" && r.Method == nethttp.MethodGet:
+		writeJSON(w, &models.Stages{Stages: p.model(name).Stages})
+	case len(segments) == 3 && segments[0] == "stage" && segments[2] == "service" && r.Method == nethttp.MethodGet:
+		if !contains(p.stages, segments[1]) {
+			writeError(w, nethttp.StatusNotFound, "stage "+segments[1]+" not found")
+			return
+		}
+		services := &models.Services{Services: []*models.Service{}}
+		for _, service := range p.services {
+			services.Services = append(services.Services, &models.Service{ServiceName: service})
+		}
+		writeJSON(w, services)
+	case len(segments) == 1 && segments[0] == "service" && r.Method == nethttp.MethodPost:
+		body := struct {
+			ServiceName string `json:"serviceName"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.ServiceName == "" {
+			writeError(w, nethttp.StatusBadRequest, "invalid service")
+			return
+		}
+		if contains(p.services, body.ServiceName) {
+			writeError(w, nethttp.StatusConflict, "service "+body.ServiceName+" already exists")
+			return
+		}
+		p.services = append(p.services, body.ServiceName)
+		writeJSON(w, map[string]string{})
+	case len(segments) == 2 && segments[0] == "service" && r.Method == nethttp.MethodDelete:
+		if !contains(p.services, segments[1]) {
+			writeError(w, nethttp.StatusNotFound, "service "+segments[1]+" not found")
+			return
+		}
+		services := []string{}
+		for _, service := range p.services {
+			if service != segments[1] {
+				services = append(services, service)
+			}
+		}
+		p.services = services
+		writeJSON(w, map[string]string{})
+	default:
+		writeError(w, nethttp.StatusNotFound, "unknown path "+r.URL.Path)
+	}
+}
+
+func (s *Server) createProject(w nethttp.ResponseWriter, r *nethttp.Request) {
+	request := keptnclient.CreateProjectRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Name == "" {
+		writeError(w, nethttp.StatusBadRequest, "invalid project")
+		return
+	}
+	if _, ok := s.projects[request.Name]; ok {
+		writeError(w, nethttp.StatusConflict, "project "+request.Name+" already exists")
+		return
+	}
+
+	stages, err := shipyardStages(request.Shipyard)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	s.projects[request.Name] = &project{request: request, stages: stages}
+	writeJSON(w, map[string]string{})
+}
+
+func (s *Server) updateProject(w nethttp.ResponseWriter, r *nethttp.Request) {
+	request := keptnclient.UpdateProjectRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Name == "" {
+		writeError(w, nethttp.StatusBadRequest, "invalid project")
+		return
+	}
+	p, ok := s.projects[request.Name]
+	if !ok {
+		writeError(w, nethttp.StatusNotFound, "project "+request.Name+" not found")
+		return
+	}
+
+	p.request.GitRemoteURL = request.GitRemoteURL
+	p.request.GitUser = request.GitUser
+	p.request.GitToken = request.GitToken
+	writeJSON(w, map[string]string{})
+}
+
+func (s *Server) deleteStage(w nethttp.ResponseWriter, projectName string, stage string) {
+	p, ok := s.projects[projectName]
+	if !ok || !contains(p.stages, stage) {
+		writeError(w, nethttp.StatusNotFound, "stage "+stage+" not found")
+		return
+	}
+
+	stages := []string{}
+	for _, existing := range p.stages {
+		if existing != stage {
+			stages = append(stages, existing)
+		}
+	}
+	p.stages = stages
+	writeJSON(w, map[string]string{})
+}
+
+//serveResources handles the resources of a project, resourceURI is empty for requests to the resource collection
+func (s *Server) serveResources(w nethttp.ResponseWriter, r *nethttp.Request, projectName string, resourceURI string) {
+	p, ok := s.projects[projectName]
+	if !ok {
+		writeError(w, nethttp.StatusNotFound, "project "+projectName+" not found")
+		return
+	}
+
+	switch {
+	case resourceURI == "" && r.Method == nethttp.MethodPut:
+		resources := models.Resources{}
+		if err := json.NewDecoder(r.Body).Decode(&resources); err != nil {
+			writeError(w, nethttp.StatusBadRequest, "invalid resources")
+			return
+		}
+		if p.resources == nil {
+			p.resources = map[string]string{}
+		}
+		for _, resource := range resources.Resources {
+			if resource.ResourceURI == nil {
+				writeError(w, nethttp.StatusBadRequest, "missing resourceURI")
+				return
+			}
+			p.resources[*resource.ResourceURI] = resource.ResourceContent
+			if *resource.ResourceURI == "shipyard.yaml" {
+				p.request.Shipyard = resource.ResourceContent
+			}
+		}
+		writeJSON(w, map[string]string{})
+	case resourceURI != "" && r.Method == nethttp.MethodGet:
+		content, ok := p.resources[resourceURI]
+		if !ok {
+			writeError(w, nethttp.StatusNotFound, "resource "+resourceURI+" not found")
+			return
+		}
+		writeJSON(w, &models.Resource{ResourceURI: &resourceURI, ResourceContent: content})
+	default:
+		writeError(w, nethttp.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+//serveServiceResources handles the resources of a service in a stage, resourceURI is empty for requests to the resource collection
+func (s *Server) serveServiceResources(w nethttp.ResponseWriter, r *nethttp.Request, projectName string, stage string, service string, resourceURI string) {
+	p, ok := s.projects[projectName]
+	if !ok || !contains(p.stages, stage) || !contains(p.services, service) {
+		writeError(w, nethttp.StatusNotFound, "service "+service+" not found in stage "+stage+" of project "+projectName)
+		return
+	}
+
+	switch {
+	case resourceURI == "" && r.Method == nethttp.MethodPut:
+		resources := models.Resources{}
+		if err := json.NewDecoder(r.Body).Decode(&resources); err != nil {
+			writeError(w, nethttp.StatusBadRequest, "invalid resources")
+			return
+		}
+		if p.serviceResources == nil {
+			p.serviceResources = map[string]string{}
+		}
+		for _, resource := range resources.Resources {
+			if resource.ResourceURI == nil {
+				writeError(w, nethttp.StatusBadRequest, "missing resourceURI")
+				return
+			}
+			p.serviceResources[stage+"/"+service+"/"+*resource.ResourceURI] = resource.ResourceContent
+		}
+		writeJSON(w, map[string]string{})
+	case resourceURI != "" && r.Method == nethttp.MethodDelete:
+		key := stage + "/" + service + "/" + resourceURI
+		if _, ok := p.serviceResources[key]; !ok {
+			writeError(w, nethttp.StatusNotFound, "resource "+resourceURI+" not found")
+			return
+		}
+		delete(p.serviceResources, key)
+		writeJSON(w, map[string]string{})
+	default:
+		writeError(w, nethttp.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) sendEvent(w nethttp.ResponseWriter, r *nethttp.Request) {
+	event := keptnclient.Event{}
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		writeError(w, nethttp.StatusBadRequest, "invalid event")
+		return
+	}
+	if event.Context == "" {
+		s.contextCounter++
+		event.Context = fmt.Sprintf("keptn-context-%d", s.contextCounter)
+	}
+	s.sentEvents = append(s.sentEvents, event)
+
+	eventType := event.Type
+	source := event.Source
+	s.events = append(s.events, &models.KeptnContextExtendedCE{
+		ID:             event.ID,
+		Contenttype:    event.ContentType,
+		Data:           event.Data,
+		Shkeptncontext: event.Context,
+		Source:         &source,
+		Specversion:    event.SpecVersion,
+		Time:           time.Now(),
+		Type:           &eventType,
+	})
+	writeJSON(w, map[string]string{"keptnContext": event.Context})
+}
+
+func (s *Server) getEvents(w nethttp.ResponseWriter, r *nethttp.Request) {
+	query := r.URL.Query()
+	events := &models.Events{Events: []*models.KeptnContextExtendedCE{}}
+	for _, event := range s.events {
+		data := keptnclient.EventData{}
+		_ = event.DataAs(&data)
+		if !matches(query.Get("keptnContext"), event.Shkeptncontext) ||
+			!matches(query.Get("project"), data.Project) ||
+			!matches(query.Get("stage"), data.Stage) ||
+			!matches(query.Get("service"), data.Service) ||
+			(event.Type != nil && !matches(query.Get("type"), *event.Type)) {
+			continue
+		}
+		events.Events = append(events.Events, event)
+	}
+	writeJSON(w, events)
+}
+
+//serveSequences handles the paths below /controlPlane/v1/sequence
+func (s *Server) serveSequences(w nethttp.ResponseWriter, r *nethttp.Request, segments []string) {
+	switch {
+	case len(segments) == 1 && r.Method == nethttp.MethodGet:
+		states := &keptnclient.SequenceStates{States: []keptnclient.SequenceState{}}
+		for _, state := range s.sequenceStates {
+			if state.Project == segments[0] && matches(r.URL.Query().Get("keptnContext"), state.Shkeptncontext) {
+				states.States = append(states.States, state)
+			}
+		}
+		writeJSON(w, states)
+	case len(segments) == 3 && segments[2] == "control" && r.Method == nethttp.MethodPost:
+		writeJSON(w, map[string]string{})
+	default:
+		writeError(w, nethttp.StatusNotFound, "unknown path "+r.URL.Path)
+	}
+}
+
+func (p *project) model(name string) *models.Project {
+	result := &models.Project{ProjectName: name, GitRemoteURI: p.request.GitRemoteURL, GitUser: p.request.GitUser}
+	for _, stage := range p.stages {
+		modelStage := &models.Stage{StageName: stage}
+		for _, service := range p.services {
+			modelStage.Services = append(modelStage.Services, &models.Service{ServiceName: service})
+		}
+		result.Stages = append(result.Stages, modelStage)
+	}
+	return result
+}
+
+//shipyardStages returns the names of the stages of a base64 encoded shipyard
+func shipyardStages(encoded string) ([]string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode shipyard: %w", err)
+	}
+
+	shipyard := struct {
+		Spec struct {
+			Stages []struct {
+				Name string `yaml:"name"`
+			} `yaml:"stages"`
+		} `yaml:"spec"`
+	}{}
+	if err := yaml.Unmarshal(decoded, &shipyard); err != nil {
+		return nil, fmt.Errorf("could not parse shipyard: %w", err)
+	}
+
+	stages := []string{}
+	for _, stage := range shipyard.Spec.Stages {
+		stages = append(stages, stage.Name)
+	}
+	return stages, nil
+}
+
+func writeJSON(w nethttp.ResponseWriter, body interface{}) {
+	w.Header().Set("content-type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w nethttp.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": statusCode, "message": message})
+}
+
+func matches(filter string, value string) bool {
+	return filter == "" || filter == value
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(projects map[string]*project) []string {
+	keys := []string{}
+	for key := range projects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
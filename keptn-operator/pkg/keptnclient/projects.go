@@ -0,0 +1,92 @@
+package keptnclient
+
+import (
+	"context"
+	"github.com/keptn/go-utils/pkg/api/models"
+	nethttp "net/http"
+	"net/url"
+)
+
+// CreateProjectRequest describes a project which should be created in Keptn
+type CreateProjectRequest struct {
+	// Name is the name of the project
+	Name string `json:"name"`
+	// Shipyard is the base64 encoded shipyard of the project
+	Shipyard string `json:"shipyard"`
+	// GitRemoteURL is the URL of the upstream repository
+	GitRemoteURL string `json:"gitRemoteURL,omitempty"`
+	// GitUser is the user used to access the upstream repository
+	GitUser string `json:"gitUser,omitempty"`
+	// GitToken is the token used to access the upstream repository
+	GitToken string `json:"gitToken,omitempty"`
+}
+
+// UpdateProjectRequest describes the upstream repository of an existing project which should be updated in Keptn
+type UpdateProjectRequest struct {
+	// Name is the name of the project
+	Name string `json:"name"`
+	// GitRemoteURL is the URL of the upstream repository
+	GitRemoteURL string `json:"gitRemoteURL,omitempty"`
+	// GitUser is the user used to access the upstream repository
+	GitUser string `json:"gitUser,omitempty"`
+	// GitToken is the token used to access the upstream repository
+	GitToken string `json:"gitToken,omitempty"`
+}
+
+// CreateProject creates a project in Keptn
+func (c *Client) CreateProject(ctx context.Context, project CreateProjectRequest) error {
+	return c.do(ctx, nethttp.MethodPost, controlPlanePath+"/project", nil, project, nil)
+}
+
+// UpdateProject updates the upstream repository and credentials of a project in Keptn
+func (c *Client) UpdateProject(ctx context.Context, project UpdateProjectRequest) error {
+	return c.do(ctx, nethttp.MethodPut, controlPlanePath+"/project", nil, project, nil)
+}
+
+// DeleteProject deletes a project in Keptn
+func (c *Client) DeleteProject(ctx context.Context, project string) error {
+	return c.do(ctx, nethttp.MethodDelete, controlPlanePath+"/project/"+url.PathEscape(project), nil, nil, nil)
+}
+
+// GetProject returns a project, an error for which IsNotFound returns true is returned if it does not exist
+func (c *Client) GetProject(ctx context.Context, project string) (*models.Project, error) {
+	result := &models.Project{}
+	err := c.do(ctx, nethttp.MethodGet, controlPlanePath+"/project/"+url.PathEscape(project), nil, nil, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProjects returns all projects
+func (c *Client) GetProjects(ctx context.Context) ([]*models.Project, error) {
+	projects := []*models.Project{}
+	nextPageKey := ""
+	for {
+		query := url.Values{}
+		if nextPageKey != "" {
+			query.Set("nextPageKey", nextPageKey)
+		}
+
+		page := &models.Projects{}
+		err := c.do(ctx, nethttp.MethodGet, controlPlanePath+"/project", query, nil, page)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, page.Projects...)
+
+		if page.NextPageKey == "" || page.NextPageKey == "0" {
+			return projects, nil
+		}
+		nextPageKey = page.NextPageKey
+	}
+}
+
+// ProjectExists returns true if the project exists in Keptn
+func (c *Client) ProjectExists(ctx context.Context, project string) (bool, error) {
+	_, err := c.GetProject(ctx, project)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
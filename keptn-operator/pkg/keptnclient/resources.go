@@ -0,0 +1,53 @@
+package keptnclient
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/keptn/go-utils/pkg/api/models"
+	nethttp "net/http"
+	"net/url"
+)
+
+// UploadProjectResource creates or updates a resource on the default branch of a project in the configuration service of Keptn,
+// which commits it to the upstream repository of the project
+func (c *Client) UploadProjectResource(ctx context.Context, project string, resourceURI string, content []byte) error {
+	resources := models.Resources{Resources: []*models.Resource{{
+		ResourceURI:     &resourceURI,
+		ResourceContent: base64.StdEncoding.EncodeToString(content),
+	}}}
+	return c.do(ctx, nethttp.MethodPut, configurationServicePath+"/project/"+url.PathEscape(project)+"/resource", nil, resources, nil)
+}
+
+// GetProjectResource returns the content of a resource on the default branch of a project, an error for which IsNotFound
+// returns true is returned if it does not exist
+func (c *Client) GetProjectResource(ctx context.Context, project string, resourceURI string) ([]byte, error) {
+	resource := &models.Resource{}
+	err := c.do(ctx, nethttp.MethodGet, configurationServicePath+"/project/"+url.PathEscape(project)+"/resource/"+url.PathEscape(resourceURI), nil, nil, resource)
+	if err != nil {
+		return nil, err
+	}
+	content, err := base64.StdEncoding.DecodeString(resource.ResourceContent)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode resource %s of project %s: %w", resourceURI, project, err)
+	}
+	return content, nil
+}
+
+// UploadServiceResource creates or updates a resource of a service in a stage of a project
+func (c *Client) UploadServiceResource(ctx context.Context, project string, stage string, service string, resourceURI string, content []byte) error {
+	resources := models.Resources{Resources: []*models.Resource{{
+		ResourceURI:     &resourceURI,
+		ResourceContent: base64.StdEncoding.EncodeToString(content),
+	}}}
+	return c.do(ctx, nethttp.MethodPut, serviceResourcePath(project, stage, service), nil, resources, nil)
+}
+
+// DeleteServiceResource deletes a resource of a service in a stage of a project
+func (c *Client) DeleteServiceResource(ctx context.Context, project string, stage string, service string, resourceURI string) error {
+	return c.do(ctx, nethttp.MethodDelete, serviceResourcePath(project, stage, service)+"/"+url.PathEscape(resourceURI), nil, nil, nil)
+}
+
+func serviceResourcePath(project string, stage string, service string) string {
+	return configurationServicePath + "/project/" + url.PathEscape(project) + "/stage/" + url.PathEscape(stage) + "/service/" + url.PathEscape(service) + "/resource"
+}
@@ -0,0 +1,77 @@
+package keptnclient
+
+import (
+	"context"
+	nethttp "net/http"
+	"net/url"
+)
+
+const (
+	// SequenceControlAbort aborts a running sequence
+	SequenceControlAbort = "abort"
+	// SequenceControlPause pauses a running sequence
+	SequenceControlPause = "pause"
+	// SequenceControlResume resumes a paused sequence
+	SequenceControlResume = "resume"
+)
+
+// SequenceStates is the response of the sequence state API
+type SequenceStates struct {
+	States      []SequenceState `json:"states"`
+	NextPageKey string          `json:"nextPageKey,omitempty"`
+}
+
+// SequenceState describes the state of a sequence
+type SequenceState struct {
+	Name           string               `json:"name"`
+	Service        string               `json:"service"`
+	Project        string               `json:"project"`
+	Time           string               `json:"time"`
+	Shkeptncontext string               `json:"shkeptncontext"`
+	State          string               `json:"state"`
+	Stages         []SequenceStateStage `json:"stages"`
+}
+
+// SequenceStateStage describes the state of a sequence in a stage
+type SequenceStateStage struct {
+	Name              string                   `json:"name"`
+	Image             string                   `json:"image,omitempty"`
+	LatestEvaluation  *SequenceStateEvaluation `json:"latestEvaluation,omitempty"`
+	LatestEvent       *SequenceStateEvent      `json:"latestEvent,omitempty"`
+	LatestFailedEvent *SequenceStateEvent      `json:"latestFailedEvent,omitempty"`
+}
+
+// SequenceStateEvaluation describes the result of the latest evaluation of a sequence in a stage
+type SequenceStateEvaluation struct {
+	Result string  `json:"result"`
+	Score  float64 `json:"score"`
+}
+
+// SequenceStateEvent describes an event of a sequence in a stage
+type SequenceStateEvent struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Time string `json:"time"`
+}
+
+// GetSequenceState returns the state of the sequence with the given Keptn context, nil is returned if it does not exist
+func (c *Client) GetSequenceState(ctx context.Context, project string, keptnContext string) (*SequenceState, error) {
+	states := &SequenceStates{}
+	query := url.Values{"keptnContext": []string{keptnContext}}
+	err := c.do(ctx, nethttp.MethodGet, controlPlanePath+"/sequence/"+url.PathEscape(project), query, nil, states)
+	if err != nil {
+		return nil, err
+	}
+	for i := range states.States {
+		if states.States[i].Shkeptncontext == keptnContext {
+			return &states.States[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ControlSequence aborts, pauses or resumes a sequence, the whole sequence is controlled if stage is empty
+func (c *Client) ControlSequence(ctx context.Context, project string, keptnContext string, stage string, state string) error {
+	body := map[string]string{"stage": stage, "state": state}
+	return c.do(ctx, nethttp.MethodPost, controlPlanePath+"/sequence/"+url.PathEscape(project)+"/"+url.PathEscape(keptnContext)+"/control", nil, body, nil)
+}
@@ -0,0 +1,51 @@
+package keptnclient
+
+import (
+	"context"
+	"github.com/keptn/go-utils/pkg/api/models"
+	nethttp "net/http"
+	"net/url"
+)
+
+// CreateService creates a service in all stages of a project
+func (c *Client) CreateService(ctx context.Context, project string, service string) error {
+	body := map[string]string{"serviceName": service}
+	return c.do(ctx, nethttp.MethodPost, controlPlanePath+"/project/"+url.PathEscape(project)+"/service", nil, body, nil)
+}
+
+// DeleteService deletes a service from all stages of a project
+func (c *Client) DeleteService(ctx context.Context, project string, service string) error {
+	return c.do(ctx, nethttp.MethodDelete, controlPlanePath+"/project/"+url.PathEscape(project)+"/service/"+url.PathEscape(service), nil, nil, nil)
+}
+
+// GetServices returns the services in a stage of a project
+func (c *Client) GetServices(ctx context.Context, project string, stage string) ([]*models.Service, error) {
+	services := &models.Services{}
+	err := c.do(ctx, nethttp.MethodGet, controlPlanePath+"/project/"+url.PathEscape(project)+"/stage/"+url.PathEscape(stage)+"/service", nil, nil, services)
+	if err != nil {
+		return nil, err
+	}
+	return services.Services, nil
+}
+
+// ServiceExists returns true if the service exists in the project, services are always created in all stages of a project
+func (c *Client) ServiceExists(ctx context.Context, project string, service string) (bool, error) {
+	stages, err := c.GetStages(ctx, project)
+	if err != nil {
+		return false, err
+	}
+	if len(stages) == 0 {
+		return false, nil
+	}
+
+	services, err := c.GetServices(ctx, project, stages[0].StageName)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range services {
+		if s.ServiceName == service {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,24 @@
+package keptnclient
+
+import (
+	"context"
+	"github.com/keptn/go-utils/pkg/api/models"
+	nethttp "net/http"
+	"net/url"
+)
+
+// GetStages returns the stages of a project
+func (c *Client) GetStages(ctx context.Context, project string) ([]*models.Stage, error) {
+	stages := &models.Stages{}
+	err := c.do(ctx, nethttp.MethodGet, controlPlanePath+"/project/"+url.PathEscape(project)+"/stage", nil, nil, stages)
+	if err != nil {
+		return nil, err
+	}
+	return stages.Stages, nil
+}
+
+// DeleteStage deletes a stage of a project in the configuration service of Keptn, this requires a configuration service
+// which supports the deletion of stages
+func (c *Client) DeleteStage(ctx context.Context, project string, stage string) error {
+	return c.do(ctx, nethttp.MethodDelete, configurationServicePath+"/project/"+url.PathEscape(project)+"/stage/"+url.PathEscape(stage), nil, nil, nil)
+}
@@ -1,4 +1,4 @@
-package utils
+package secrets
 
 import (
 	"crypto/rand"
@@ -35,5 +35,5 @@ func EncryptPublicPEM(message string) (string, error) {
 
 	ct := RSA_OAEP_Encrypt(message, privateKey.PublicKey)
 
-	return "rsa:" + ct, err
+	return RSAPrefix + ":" + ct, err
 }
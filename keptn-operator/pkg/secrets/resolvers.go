@@ -0,0 +1,88 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+)
+
+// EnvResolver reads secrets from environment variables
+type EnvResolver struct{}
+
+// Resolve returns the value of the environment variable with the given name
+func (r *EnvResolver) Resolve(_ context.Context, reference string) (string, error) {
+	value, ok := os.LookupEnv(reference)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", reference)
+	}
+	return value, nil
+}
+
+// FileResolver reads secrets from files, e.g. mounted secrets
+type FileResolver struct{}
+
+// Resolve returns the content of the given file without trailing newlines
+func (r *FileResolver) Resolve(_ context.Context, reference string) (string, error) {
+	content, err := ioutil.ReadFile(reference)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// KubernetesResolver reads secrets from keys of Kubernetes Secrets
+type KubernetesResolver struct {
+	// Client is used to fetch the Secrets
+	Client client.Reader
+}
+
+// NewKubernetesResolver returns a resolver fetching Secrets with the given client
+func NewKubernetesResolver(client client.Reader) *KubernetesResolver {
+	return &KubernetesResolver{Client: client}
+}
+
+// Resolve returns the value of a key in a Secret referenced as <namespace>/<name>/<key>
+func (r *KubernetesResolver) Resolve(ctx context.Context, reference string) (string, error) {
+	secret, key, err := r.get(ctx, reference)
+	if err != nil {
+		return "", err
+	}
+	return string(secret.Data[key]), nil
+}
+
+// Version returns the resourceVersion of the Secret referenced as <namespace>/<name>/<key>
+func (r *KubernetesResolver) Version(ctx context.Context, reference string) (string, error) {
+	secret, _, err := r.get(ctx, reference)
+	if err != nil {
+		return "", err
+	}
+	return secret.ResourceVersion, nil
+}
+
+// get fetches the referenced Secret and returns it together with the referenced key, which is known to exist
+func (r *KubernetesResolver) get(ctx context.Context, reference string) (*corev1.Secret, string, error) {
+	if r.Client == nil {
+		return nil, "", fmt.Errorf("no kubernetes client configured")
+	}
+
+	data := strings.Split(reference, "/")
+	if len(data) != 3 {
+		return nil, "", fmt.Errorf("invalid secret reference %s, expected <namespace>/<name>/<key>", reference)
+	}
+
+	secret := &corev1.Secret{}
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: data[0], Name: data[1]}, secret)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not fetch secret %s/%s: %w", data[0], data[1], err)
+	}
+
+	if _, ok := secret.Data[data[2]]; !ok {
+		return nil, "", fmt.Errorf("secret %s/%s has no key %s", data[0], data[1], data[2])
+	}
+	return secret, data[2], nil
+}
@@ -1,6 +1,7 @@
-package common
+package secrets
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
@@ -9,28 +10,22 @@ import (
 	"encoding/pem"
 	"fmt"
 	"os"
-	"strings"
 )
 
-func decryptSecret(secret string) (string, error) {
-	data := strings.Split(secret, ":")
+// RSAResolver decrypts secrets with the private key stored in the RSA_PRIVATE_KEY environment variable
+type RSAResolver struct{}
 
-	if data[0] == "rsa" {
-		pemPrivate, ok := os.LookupEnv("RSA_PRIVATE_KEY")
-		if !ok {
-			return "", fmt.Errorf("environment variable RSA_PRIVATE_KEY is not set, will not be able to decrypt secrets")
-		}
-		if pemPrivate == "" {
-			return "", fmt.Errorf("RSA_PRIVATE_KEY is empty, will not be able to decrypt secrets")
-		}
-
-		secret, err := decryptPrivatePEM(data[1], pemPrivate)
-		if err != nil {
-			return "", err
-		}
-		return secret, nil
+// Resolve decrypts the given base64 encoded cipher text
+func (r *RSAResolver) Resolve(_ context.Context, reference string) (string, error) {
+	pemPrivate, ok := os.LookupEnv("RSA_PRIVATE_KEY")
+	if !ok {
+		return "", fmt.Errorf("environment variable RSA_PRIVATE_KEY is not set, will not be able to decrypt secrets")
+	}
+	if pemPrivate == "" {
+		return "", fmt.Errorf("RSA_PRIVATE_KEY is empty, will not be able to decrypt secrets")
 	}
-	return secret, nil
+
+	return decryptPrivatePEM(reference, pemPrivate)
 }
 
 func decryptPrivatePEM(message string, key string) (string, error) {
@@ -38,6 +33,9 @@ func decryptPrivatePEM(message string, key string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if block == nil {
+		return "", fmt.Errorf("could not decode private key")
+	}
 
 	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
 	if err != nil {
@@ -1,6 +1,7 @@
-package utils
+package secrets
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/pem"
 	"github.com/stretchr/testify/require"
@@ -39,12 +40,12 @@ pvVG1bkyaUTZD1u/GQpVSkF3BAPqPAJQKds9sORrSH3SC25v5g+Ad+S9NtWJNTBy
 -----END RSA PRIVATE KEY-----
 `
 
-func TestDecryptSecret(t *testing.T) {
+func TestRSAResolver(t *testing.T) {
 	expectedOutput := "This is my secret"
 
 	os.Setenv("RSA_PRIVATE_KEY", base64.StdEncoding.EncodeToString([]byte(privateKey)))
 
-	output, err := DecryptSecret("rsa:" + secret)
+	output, err := Resolve(context.TODO(), "rsa:"+secret)
 
 	require.NoError(t, err)
 	require.Equal(t, output, expectedOutput)
@@ -69,3 +70,12 @@ func TestDecryptPrivatePEMFail(t *testing.T) {
 
 	require.Error(t, err)
 }
+
+func TestRSAResolverEmptyKey(t *testing.T) {
+	os.Setenv("RSA_PRIVATE_KEY", "")
+	defer os.Unsetenv("RSA_PRIVATE_KEY")
+
+	_, err := Resolve(context.TODO(), "rsa:"+secret)
+
+	require.Error(t, err)
+}
@@ -0,0 +1,169 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"strings"
+	"sync"
+)
+
+const (
+	// RSAPrefix marks a secret which is encrypted with the operators public key
+	RSAPrefix = "rsa"
+	// KubernetesPrefix marks a reference to a key in a Kubernetes Secret (k8s:<namespace>/<name>/<key>)
+	KubernetesPrefix = "k8s"
+	// EnvPrefix marks a reference to an environment variable (env:<name>)
+	EnvPrefix = "env"
+	// FilePrefix marks a reference to a file (file:<path>)
+	FilePrefix = "file"
+)
+
+// Resolver resolves a secret reference (the part after the prefix) into its plaintext value
+type Resolver interface {
+	Resolve(ctx context.Context, reference string) (string, error)
+}
+
+// Versioner is implemented by resolvers which can determine a version of a reference, which changes whenever its value
+// changes, without resolving the value
+type Versioner interface {
+	Version(ctx context.Context, reference string) (string, error)
+}
+
+// Registry holds the resolvers for all known secret prefixes
+type Registry struct {
+	mu         sync.RWMutex
+	resolvers  map[string]Resolver
+	allowLocal bool
+}
+
+// NewRegistry returns a registry containing the rsa, env, file and (unconfigured) k8s resolvers
+func NewRegistry() *Registry {
+	return &Registry{
+		resolvers: map[string]Resolver{
+			RSAPrefix:        &RSAResolver{},
+			EnvPrefix:        &EnvResolver{},
+			FilePrefix:       &FileResolver{},
+			KubernetesPrefix: &KubernetesResolver{},
+		},
+	}
+}
+
+// Register adds a resolver for the given prefix, an existing resolver for the prefix is replaced
+func (r *Registry) Register(prefix string, resolver Resolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resolvers[prefix] = resolver
+}
+
+// AllowLocalReferences controls whether env: and file: secrets may be used in custom resources. These are resolved in the
+// context of the operator, allowing them would expose its environment and file system to everyone who can create resources
+func (r *Registry) AllowLocalReferences(allow bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.allowLocal = allow
+}
+
+// ResolveInNamespace resolves a secret taken from a custom resource in the given namespace. k8s: secrets have to reference
+// a Secret in this namespace, env: and file: secrets are rejected unless local references are allowed
+func (r *Registry) ResolveInNamespace(ctx context.Context, secret string, namespace string) (string, error) {
+	data := strings.SplitN(secret, ":", 2)
+	if len(data) == 2 {
+		r.mu.RLock()
+		allowLocal := r.allowLocal
+		r.mu.RUnlock()
+
+		switch data[0] {
+		case EnvPrefix, FilePrefix:
+			if !allowLocal {
+				return "", fmt.Errorf("%s secrets are not allowed in resources", data[0])
+			}
+		case KubernetesPrefix:
+			if !strings.HasPrefix(data[1], namespace+"/") {
+				return "", fmt.Errorf("secret reference %s is outside of namespace %s", secret, namespace)
+			}
+		}
+	}
+	return r.Resolve(ctx, secret)
+}
+
+// Resolve resolves a secret, returns the secret as it is if it has no known prefix. Secrets taken from custom resources
+// have to be resolved using ResolveInNamespace
+func (r *Registry) Resolve(ctx context.Context, secret string) (string, error) {
+	data := strings.SplitN(secret, ":", 2)
+	if len(data) != 2 {
+		return secret, nil
+	}
+
+	r.mu.RLock()
+	resolver, ok := r.resolvers[data[0]]
+	r.mu.RUnlock()
+	if !ok {
+		return secret, nil
+	}
+
+	value, err := resolver.Resolve(ctx, data[1])
+	if err != nil {
+		return "", fmt.Errorf("could not resolve %s secret: %w", data[0], err)
+	}
+	return value, nil
+}
+
+// Version returns a string which changes whenever the value of the secret changes, e.g. to detect rotated secrets without
+// storing a hash of their value. Secrets whose resolver is no Versioner are returned as they are, so changes of their
+// value are not detected
+func (r *Registry) Version(ctx context.Context, secret string) (string, error) {
+	data := strings.SplitN(secret, ":", 2)
+	if len(data) != 2 {
+		return secret, nil
+	}
+
+	r.mu.RLock()
+	resolver, ok := r.resolvers[data[0]]
+	r.mu.RUnlock()
+	versioner, isVersioner := resolver.(Versioner)
+	if !ok || !isVersioner {
+		return secret, nil
+	}
+
+	version, err := versioner.Version(ctx, data[1])
+	if err != nil {
+		return "", fmt.Errorf("could not determine version of %s secret: %w", data[0], err)
+	}
+	return secret + "@" + version, nil
+}
+
+var defaultRegistry = NewRegistry()
+
+// Register adds a resolver for the given prefix to the default registry
+func Register(prefix string, resolver Resolver) {
+	defaultRegistry.Register(prefix, resolver)
+}
+
+// Resolve resolves a secret using the default registry, returns the secret as it is if it has no known prefix
+func Resolve(ctx context.Context, secret string) (string, error) {
+	return defaultRegistry.Resolve(ctx, secret)
+}
+
+// AllowLocalReferences controls whether env: and file: secrets may be used in custom resources using the default registry
+func AllowLocalReferences(allow bool) {
+	defaultRegistry.AllowLocalReferences(allow)
+}
+
+// ResolveInNamespace resolves a secret taken from a custom resource in the given namespace using the default registry
+func ResolveInNamespace(ctx context.Context, secret string, namespace string) (string, error) {
+	return defaultRegistry.ResolveInNamespace(ctx, secret, namespace)
+}
+
+// Version returns the version of a secret using the default registry
+func Version(ctx context.Context, secret string) (string, error) {
+	return defaultRegistry.Version(ctx, secret)
+}
+
+// Reference returns the secret string for a secretRef in the namespace if one is given, otherwise the inline value is returned
+func Reference(ref *apiv1.SecretKeyReference, value string, namespace string) string {
+	if ref == nil {
+		return value
+	}
+	return KubernetesPrefix + ":" + namespace + "/" + ref.Name + "/" + ref.Key
+}
@@ -0,0 +1,176 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+
+	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+)
+
+type failingResolver struct{}
+
+func (r *failingResolver) Resolve(_ context.Context, _ string) (string, error) {
+	return "", errors.New("failed")
+}
+
+func TestRegistryResolve(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(secretFile, []byte("file-secret\n"), 0600))
+
+	os.Setenv("SECRETS_TEST_PASSWORD", "env-secret")
+	defer os.Unsetenv("SECRETS_TEST_PASSWORD")
+
+	registry := NewRegistry()
+	registry.Register(KubernetesPrefix, NewKubernetesResolver(fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "git-credentials", Namespace: "keptn"},
+		Data:       map[string][]byte{"password": []byte("k8s-secret")},
+	}).Build()))
+	registry.Register("fail", &failingResolver{})
+
+	tests := []struct {
+		name    string
+		secret  string
+		want    string
+		wantErr bool
+	}{
+		{name: "plaintext", secret: "my-secret", want: "my-secret"},
+		{name: "unknown prefix", secret: "ghp:my-secret", want: "ghp:my-secret"},
+		{name: "env", secret: "env:SECRETS_TEST_PASSWORD", want: "env-secret"},
+		{name: "env not set", secret: "env:SECRETS_TEST_UNSET", wantErr: true},
+		{name: "file", secret: "file:" + secretFile, want: "file-secret"},
+		{name: "file not found", secret: "file:" + filepath.Join(dir, "missing"), wantErr: true},
+		{name: "k8s", secret: "k8s:keptn/git-credentials/password", want: "k8s-secret"},
+		{name: "k8s missing key", secret: "k8s:keptn/git-credentials/token", wantErr: true},
+		{name: "k8s missing secret", secret: "k8s:keptn/other/password", wantErr: true},
+		{name: "k8s invalid reference", secret: "k8s:git-credentials", wantErr: true},
+		{name: "custom resolver", secret: "fail:anything", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.Resolve(context.TODO(), tt.secret)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestKubernetesResolverWithoutClient(t *testing.T) {
+	_, err := NewRegistry().Resolve(context.TODO(), "k8s:keptn/git-credentials/password")
+	require.Error(t, err)
+}
+
+func TestReference(t *testing.T) {
+	type args struct {
+		ref       *apiv1.SecretKeyReference
+		value     string
+		namespace string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "inline value",
+			args: args{value: "my-secret", namespace: "keptn"},
+			want: "my-secret",
+		},
+		{
+			name: "secretRef in same namespace",
+			args: args{ref: &apiv1.SecretKeyReference{Name: "git-credentials", Key: "password"}, value: "my-secret", namespace: "keptn"},
+			want: "k8s:keptn/git-credentials/password",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Reference(tt.args.ref, tt.args.value, tt.args.namespace))
+		})
+	}
+}
+
+func TestRegistryResolveInNamespace(t *testing.T) {
+	os.Setenv("SECRETS_TEST_PASSWORD", "env-secret")
+	defer os.Unsetenv("SECRETS_TEST_PASSWORD")
+
+	registry := NewRegistry()
+	registry.Register(KubernetesPrefix, NewKubernetesResolver(fake.NewClientBuilder().WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "git-credentials", Namespace: "keptn"},
+			Data:       map[string][]byte{"password": []byte("k8s-secret")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "git-credentials", Namespace: "other"},
+			Data:       map[string][]byte{"password": []byte("other-secret")},
+		},
+	).Build()))
+
+	tests := []struct {
+		name       string
+		secret     string
+		allowLocal bool
+		want       string
+		wantErr    bool
+	}{
+		{name: "plaintext", secret: "my-secret", want: "my-secret"},
+		{name: "k8s in same namespace", secret: "k8s:keptn/git-credentials/password", want: "k8s-secret"},
+		{name: "k8s in other namespace", secret: "k8s:other/git-credentials/password", wantErr: true},
+		{name: "k8s in namespace with same prefix", secret: "k8s:keptn-other/git-credentials/password", wantErr: true},
+		{name: "env not allowed", secret: "env:SECRETS_TEST_PASSWORD", wantErr: true},
+		{name: "file not allowed", secret: "file:/etc/hostname", wantErr: true},
+		{name: "env allowed", secret: "env:SECRETS_TEST_PASSWORD", allowLocal: true, want: "env-secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry.AllowLocalReferences(tt.allowLocal)
+			got, err := registry.ResolveInNamespace(context.TODO(), tt.secret, "keptn")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegistryVersion(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "git-credentials", Namespace: "keptn"},
+		Data:       map[string][]byte{"password": []byte("k8s-secret")},
+	}).Build()
+	registry := NewRegistry()
+	registry.Register(KubernetesPrefix, NewKubernetesResolver(fakeClient))
+
+	version, err := registry.Version(context.TODO(), "k8s:keptn/git-credentials/password")
+	require.NoError(t, err)
+	require.NotContains(t, version, "k8s-secret")
+
+	secret := &corev1.Secret{}
+	require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: "git-credentials", Namespace: "keptn"}, secret))
+	secret.Data["password"] = []byte("rotated")
+	require.NoError(t, fakeClient.Update(context.TODO(), secret))
+
+	rotated, err := registry.Version(context.TODO(), "k8s:keptn/git-credentials/password")
+	require.NoError(t, err)
+	require.NotEqual(t, version, rotated)
+
+	_, err = registry.Version(context.TODO(), "k8s:keptn/git-credentials/token")
+	require.Error(t, err)
+
+	version, err = registry.Version(context.TODO(), "rsa:ZW5jcnlwdGVk")
+	require.NoError(t, err)
+	require.Equal(t, "rsa:ZW5jcnlwdGVk", version)
+}
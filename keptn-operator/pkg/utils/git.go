@@ -2,37 +2,41 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
-	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/memory"
 	apiv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/gitauth"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/secrets"
 	"k8s.io/apimachinery/pkg/types"
 	"os/exec"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-//CheckOutGitRepo Checks out the git repo and returns the commit hash of it
+//CheckOutGitRepo Checks out the configured branch of the git repo and returns the commit hash of it, the default branch
+//of the remote is checked out if no branch is configured
 func CheckOutGitRepo(repositoryConfig *GitRepositoryConfig, dir string) (*git.Repository, string, error) {
-	authentication := &githttp.BasicAuth{
-		Username: repositoryConfig.User,
-		Password: repositoryConfig.Token,
+	authentication, err := gitauth.NewAuthMethod(repositoryConfig.RemoteURI, repositoryConfig.User, repositoryConfig.Token, repositoryConfig.SSH)
+	if err != nil {
+		return nil, "", err
 	}
 
 	cloneOptions := git.CloneOptions{
-		URL:           repositoryConfig.RemoteURI,
-		Auth:          authentication,
-		SingleBranch:  true,
-		ReferenceName: plumbing.ReferenceName("refs/heads/main"),
+		URL:          repositoryConfig.RemoteURI,
+		Auth:         authentication,
+		SingleBranch: true,
+	}
+	if repositoryConfig.Branch != "" {
+		cloneOptions.ReferenceName = plumbing.NewBranchReferenceName(repositoryConfig.Branch)
 	}
 
 	repo, err := git.PlainClone(dir, false, &cloneOptions)
 	if err != nil {
-		cloneOptions.ReferenceName = "refs/heads/master"
-		repo, err = git.PlainClone(dir, false, &cloneOptions)
-		if err != nil {
-			return nil, "", fmt.Errorf("Could not checkout "+repositoryConfig.RemoteURI+"/"+repositoryConfig.Branch, err)
-		}
+		return nil, "", fmt.Errorf("could not checkout %s/%s: %w", repositoryConfig.RemoteURI, repositoryConfig.Branch, err)
 	}
 
 	head, err := repo.Head()
@@ -50,18 +54,25 @@ func GetUpstreamCredentials(ctx context.Context, client client.Client, project s
 		return &GitRepositoryConfig{}, err
 	}
 
-	return GetGitCredentials(obj.Spec.Repository, obj.Spec.Username, obj.Spec.Password, obj.Spec.DefaultBranch)
-}
+	token := secrets.Reference(obj.Spec.SecretRef, obj.Spec.Password, namespace)
+	repositoryConfig, err := GetGitCredentials(ctx, obj.Spec.Repository, obj.Spec.Username, token, obj.Spec.DefaultBranch, namespace)
+	if err != nil {
+		return nil, err
+	}
 
-//GetGitCredentials creates a unified struct for git credentials
-func GetGitCredentials(remoteURI, user, token string, branch string) (*GitRepositoryConfig, error) {
-	secret, err := DecryptSecret(token)
+	repositoryConfig.SSH, err = gitauth.ResolveSSHCredentials(ctx, obj.Spec.SSH, namespace)
 	if err != nil {
 		return nil, err
 	}
+	return repositoryConfig, nil
+}
 
-	if branch == "" {
-		branch = "main"
+//GetGitCredentials creates a unified struct for git credentials, the token is resolved using the registered secret resolvers
+//in the namespace of the resource it has been taken from. An empty branch refers to the default branch of the remote
+func GetGitCredentials(ctx context.Context, remoteURI, user, token string, branch string, namespace string) (*GitRepositoryConfig, error) {
+	secret, err := secrets.ResolveInNamespace(ctx, token, namespace)
+	if err != nil {
+		return nil, err
 	}
 
 	return &GitRepositoryConfig{
@@ -72,6 +83,108 @@ func GetGitCredentials(remoteURI, user, token string, branch string) (*GitReposi
 	}, nil
 }
 
+//GetUpstreamBranchHash returns the hash of the commit the configured branch of the upstream repository points to, the
+//default branch of the remote is used if no branch is configured
+func GetUpstreamBranchHash(repositoryConfig *GitRepositoryConfig) (string, error) {
+	authentication, err := gitauth.NewAuthMethod(repositoryConfig.RemoteURI, repositoryConfig.User, repositoryConfig.Token, repositoryConfig.SSH)
+	if err != nil {
+		return "", err
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{repositoryConfig.RemoteURI},
+	})
+
+	refs, err := remote.List(&git.ListOptions{Auth: authentication})
+	if err != nil {
+		return "", fmt.Errorf("could not list branches of %s: %w", repositoryConfig.RemoteURI, err)
+	}
+	name := plumbing.HEAD
+	if repositoryConfig.Branch != "" {
+		name = plumbing.NewBranchReferenceName(repositoryConfig.Branch)
+	}
+	// HEAD may be advertised as symbolic reference to the default branch
+	references := memory.NewStorage()
+	for _, ref := range refs {
+		if err := references.SetReference(ref); err != nil {
+			return "", err
+		}
+	}
+	ref, err := storer.ResolveReference(references, name)
+	if err != nil {
+		return "", fmt.Errorf("branch %s does not exist in %s: %w", name.Short(), repositoryConfig.RemoteURI, err)
+	}
+	return ref.Hash().String(), nil
+}
+
+// ErrProtectedBranch is returned if the default branch of an upstream repository should be deleted
+var ErrProtectedBranch = errors.New("protected branch")
+
+//IsProtectedBranch returns true if the error has been caused by an attempt to delete the default branch of a repository
+func IsProtectedBranch(err error) bool {
+	return errors.Is(err, ErrProtectedBranch)
+}
+
+//DeleteUpstreamBranch deletes a branch in the upstream repository, it is not treated as error if the branch does not exist.
+//The configured branch and the branch HEAD of the remote points to are never deleted. If the remote does not advertise
+//HEAD as symbolic reference, branches pointing to the same commit as HEAD are kept as well, as is every branch of a
+//remote without HEAD
+func DeleteUpstreamBranch(repositoryConfig *GitRepositoryConfig, branch string) error {
+	if branch == repositoryConfig.Branch {
+		return fmt.Errorf("%w: %s is the default branch of the project", ErrProtectedBranch, branch)
+	}
+
+	authentication, err := gitauth.NewAuthMethod(repositoryConfig.RemoteURI, repositoryConfig.User, repositoryConfig.Token, repositoryConfig.SSH)
+	if err != nil {
+		return err
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{repositoryConfig.RemoteURI},
+	})
+
+	refs, err := remote.List(&git.ListOptions{Auth: authentication})
+	if err != nil {
+		return fmt.Errorf("could not list branches of %s: %w", repositoryConfig.RemoteURI, err)
+	}
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	var branchHead, head *plumbing.Reference
+	for _, ref := range refs {
+		switch ref.Name() {
+		case branchRef:
+			branchHead = ref
+		case plumbing.HEAD:
+			head = ref
+		}
+	}
+	if branchHead == nil {
+		return nil
+	}
+
+	switch {
+	case head == nil:
+		return fmt.Errorf("%w: the default branch of %s is unknown", ErrProtectedBranch, repositoryConfig.RemoteURI)
+	case head.Type() == plumbing.SymbolicReference:
+		if head.Target() == branchRef {
+			return fmt.Errorf("%w: %s is the default branch of %s", ErrProtectedBranch, branch, repositoryConfig.RemoteURI)
+		}
+	case head.Hash() == branchHead.Hash():
+		// without the symbolic reference, the default branch can't be told apart from branches on the same commit
+		return fmt.Errorf("%w: %s may be the default branch of %s", ErrProtectedBranch, branch, repositoryConfig.RemoteURI)
+	}
+
+	err = remote.Push(&git.PushOptions{
+		Auth:     authentication,
+		RefSpecs: []config.RefSpec{config.RefSpec(":" + branchRef.String())},
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("could not delete branch %s of %s: %w", branch, repositoryConfig.RemoteURI, err)
+	}
+	return nil
+}
+
 //AddGit adds a given worktree to git
 func AddGit(worktree *git.Worktree) error {
 	cmd := exec.Command("git", "add", ".")
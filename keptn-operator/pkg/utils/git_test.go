@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDeleteUpstreamBranch(t *testing.T) {
+	tests := []struct {
+		name          string
+		head          string
+		branch        string
+		wantProtected bool
+	}{
+		{name: "symbolic_head", head: "main", branch: "dev"},
+		{name: "symbolic_head_target", head: "main", branch: "main", wantProtected: true},
+		// HEAD only reveals the commit of the default branch, branches on other commits can be deleted
+		{name: "detached_head_other_commit", branch: "dev"},
+		{name: "detached_head_same_commit", branch: "main", wantProtected: true},
+		{name: "missing_branch", head: "main", branch: "prod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			repo, err := git.PlainInit(dir, false)
+			require.NoError(t, err)
+			worktree, err := repo.Worktree()
+			require.NoError(t, err)
+
+			commit := func(content string) plumbing.Hash {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "shipyard.yaml"), []byte(content), 0644))
+				_, err := worktree.Add("shipyard.yaml")
+				require.NoError(t, err)
+				hash, err := worktree.Commit(content, &git.CommitOptions{
+					Author: &object.Signature{Name: "keptn", Email: "keptn@keptn.sh", When: time.Now()},
+				})
+				require.NoError(t, err)
+				return hash
+			}
+			main := commit("kind: Shipyard")
+			dev := commit("kind: Shipyard\nmetadata: {}")
+			require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), main)))
+			require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("dev"), dev)))
+			if tt.head != "" {
+				require.NoError(t, repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName(tt.head))))
+			} else {
+				require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, main)))
+			}
+
+			err = DeleteUpstreamBranch(&GitRepositoryConfig{RemoteURI: dir}, tt.branch)
+			require.Equal(t, tt.wantProtected, IsProtectedBranch(err))
+			if !tt.wantProtected {
+				require.NoError(t, err)
+			}
+
+			_, err = repo.Reference(plumbing.NewBranchReferenceName(tt.branch), false)
+			require.Equal(t, tt.wantProtected, err == nil)
+		})
+	}
+}
@@ -111,7 +111,7 @@ func TestGetHashStructure(t *testing.T) {
 		},
 	}
 
-	testhash := "17721150619434961566"
+	testhash := "10754817265168059707"
 
 	type args struct {
 		i interface{}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrKeptnInstanceUnavailable is returned if the KeptnInstance recorded in the status of a resource can not be resolved
+var ErrKeptnInstanceUnavailable = errors.New("keptn instance unavailable")
+
+//IsKeptnInstanceUnavailable returns true if the error has been caused by a KeptnInstance which is not recorded or does not exist anymore
+func IsKeptnInstanceUnavailable(err error) bool {
+	return errors.Is(err, ErrKeptnInstanceUnavailable)
+}
+
+//GetRecordedKeptnInstance returns the KeptnInstance recorded in the status of a resource in the namespace. Unlike
+//GetKeptnInstanceForProject, it never falls back to the default instance, as deleting objects in another Keptn instance
+//than the one they have been created in would delete unrelated objects with the same name
+func GetRecordedKeptnInstance(ctx context.Context, clt client.Client, ref *keptnv1.KeptnInstanceReference, namespace string) (keptnv1.KeptnInstance, string, error) {
+	if ref == nil {
+		return keptnv1.KeptnInstance{}, "", fmt.Errorf("%w: no keptn instance has been recorded", ErrKeptnInstanceUnavailable)
+	}
+	instance, token, err := GetKeptnInstanceByRef(ctx, clt, ref, namespace)
+	if apierrors.IsNotFound(err) {
+		return keptnv1.KeptnInstance{}, "", fmt.Errorf("%w: %s", ErrKeptnInstanceUnavailable, err.Error())
+	}
+	return instance, token, err
+}
@@ -4,11 +4,12 @@ import (
 	"context"
 	"fmt"
 	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/keptnclient"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/secrets"
 	"github.com/keptn/go-utils/pkg/api/models"
-	apiutils "github.com/keptn/go-utils/pkg/api/utils"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
-	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -44,15 +45,39 @@ func GetKeptnCPToken(ctx context.Context, client client.Client, namespace string
 	return string(keptnToken.Data["keptn-api-token"]), nil
 }
 
+// DefaultKeptnInstanceName is the name of the KeptnInstance used if no instance is referenced
+const DefaultKeptnInstanceName = "default"
+
 // GetKeptnInstance returns the Keptn CP Instance Information in a Namespace
 func GetKeptnInstance(ctx context.Context, client client.Client, namespace string) (keptnv1.KeptnInstance, string, error) {
+	return GetKeptnInstanceByRef(ctx, client, nil, namespace)
+}
+
+// GetKeptnInstanceByRef returns the referenced Keptn CP Instance, the default instance in the namespace is used if ref is nil.
+//Instances in other namespaces have to allow references from the namespace using the AllowedNamespacesAnnotation
+func GetKeptnInstanceByRef(ctx context.Context, client client.Client, ref *keptnv1.KeptnInstanceReference, namespace string) (keptnv1.KeptnInstance, string, error) {
+	name := DefaultKeptnInstanceName
+	referencingNamespace := namespace
+	if ref != nil {
+		if ref.Name != "" {
+			name = ref.Name
+		}
+		if ref.Namespace != "" {
+			namespace = ref.Namespace
+		}
+	}
+
 	keptnInstance := keptnv1.KeptnInstance{}
-	err := client.Get(ctx, types.NamespacedName{Name: "default", Namespace: namespace}, &keptnInstance)
+	err := client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &keptnInstance)
 	if err != nil {
-		return keptnv1.KeptnInstance{}, "", fmt.Errorf("could not fetch keptn instance: %w", err)
+		return keptnv1.KeptnInstance{}, "", fmt.Errorf("could not fetch keptn instance %s/%s: %w", namespace, name, err)
+	}
+
+	if !keptnv1.IsReferenceAllowed(&keptnInstance, referencingNamespace) {
+		return keptnv1.KeptnInstance{}, "", fmt.Errorf("keptn instance %s/%s may not be referenced from namespace %s, the namespace has to be listed in the %s annotation of the instance", namespace, name, referencingNamespace, keptnv1.AllowedNamespacesAnnotation)
 	}
 
-	token, err := DecryptSecret(keptnInstance.Status.CurrentToken)
+	token, err := secrets.ResolveInNamespace(ctx, keptnInstance.Status.CurrentToken, keptnInstance.Namespace)
 	if err != nil {
 		return keptnv1.KeptnInstance{}, "", err
 	}
@@ -60,25 +85,20 @@ func GetKeptnInstance(ctx context.Context, client client.Client, namespace strin
 	return keptnInstance, token, nil
 }
 
-//CheckKeptnProjectExists queries the keptn api if a project exists
-func CheckKeptnProjectExists(ctx context.Context, req ctrl.Request, clt client.Client, project string) (bool, error) {
-	instance, token, err := GetKeptnInstance(ctx, clt, req.Namespace)
-	if err != nil {
-		return false, err
-	}
-	projectsHandler := apiutils.NewAuthenticatedProjectHandler(instance.Spec.APIUrl, token, instance.Status.AuthHeader, nil, instance.Status.Scheme)
-
-	projects, err := projectsHandler.GetAllProjects()
+// GetKeptnInstanceForProject returns the Keptn CP Instance referenced by a KeptnProject, the default instance in the namespace is used if the KeptnProject does not exist
+func GetKeptnInstanceForProject(ctx context.Context, clt client.Client, project string, namespace string) (keptnv1.KeptnInstance, string, error) {
+	keptnProject := &keptnv1.KeptnProject{}
+	err := clt.Get(ctx, types.NamespacedName{Name: project, Namespace: namespace}, keptnProject)
 	if err != nil {
-		return false, err
-	}
-
-	filteredProjects := FilterProjects(projects, project)
-	if len(filteredProjects) == 0 {
-		if project != "" {
-			return false, fmt.Errorf("no project"+project+"found: %w", err)
+		if errors.IsNotFound(err) {
+			return GetKeptnInstanceByRef(ctx, clt, nil, namespace)
 		}
-		return false, err
+		return keptnv1.KeptnInstance{}, "", fmt.Errorf("could not fetch keptn project %s: %w", project, err)
 	}
-	return true, nil
+	return GetKeptnInstanceByRef(ctx, clt, keptnProject.Spec.InstanceRef, namespace)
+}
+
+//CheckKeptnProjectExists queries the keptn api or the cache if a project exists
+func CheckKeptnProjectExists(ctx context.Context, cache *keptnclient.Cache, instance keptnv1.KeptnInstance, token string, project string) (bool, error) {
+	return cache.ProjectExists(ctx, keptnclient.NewClientForInstance(instance, token), project)
 }
@@ -1,8 +1,14 @@
 package utils
 
 import (
+	"context"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
 	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"testing"
 )
 
@@ -114,3 +120,72 @@ func TestFilterServices(t *testing.T) {
 		})
 	}
 }
+
+func TestGetKeptnInstanceForProject(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, keptnv1.AddToScheme(scheme))
+
+	instance := func(name, namespace, url string) *keptnv1.KeptnInstance {
+		return &keptnv1.KeptnInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       keptnv1.KeptnInstanceSpec{APIUrl: url},
+			Status:     keptnv1.KeptnInstanceStatus{CurrentToken: "token-" + name},
+		}
+	}
+
+	allowNamespaces := func(instance *keptnv1.KeptnInstance, namespaces string) *keptnv1.KeptnInstance {
+		instance.Annotations = map[string]string{keptnv1.AllowedNamespacesAnnotation: namespaces}
+		return instance
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		instance("default", "keptn", "http://default"),
+		instance("prod", "keptn", "http://prod"),
+		allowNamespaces(instance("nonprod", "keptn-nonprod", "http://nonprod"), "other, keptn"),
+		allowNamespaces(instance("restricted", "keptn-restricted", "http://restricted"), "other"),
+		&keptnv1.KeptnProject{ObjectMeta: metav1.ObjectMeta{Name: "unreferenced", Namespace: "keptn"}},
+		&keptnv1.KeptnProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "keptn"},
+			Spec:       keptnv1.KeptnProjectSpec{InstanceRef: &keptnv1.KeptnInstanceReference{Name: "prod"}},
+		},
+		&keptnv1.KeptnProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "nonprod", Namespace: "keptn"},
+			Spec:       keptnv1.KeptnProjectSpec{InstanceRef: &keptnv1.KeptnInstanceReference{Name: "nonprod", Namespace: "keptn-nonprod"}},
+		},
+		&keptnv1.KeptnProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "restricted", Namespace: "keptn"},
+			Spec:       keptnv1.KeptnProjectSpec{InstanceRef: &keptnv1.KeptnInstanceReference{Name: "restricted", Namespace: "keptn-restricted"}},
+		},
+		&keptnv1.KeptnProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "keptn"},
+			Spec:       keptnv1.KeptnProjectSpec{InstanceRef: &keptnv1.KeptnInstanceReference{Name: "missing"}},
+		},
+	).Build()
+
+	tests := []struct {
+		name      string
+		project   string
+		wantURL   string
+		wantToken string
+		wantErr   bool
+	}{
+		{name: "project_without_reference", project: "unreferenced", wantURL: "http://default", wantToken: "token-default"},
+		{name: "project_not_found", project: "notexisting", wantURL: "http://default", wantToken: "token-default"},
+		{name: "same_namespace", project: "prod", wantURL: "http://prod", wantToken: "token-prod"},
+		{name: "cross_namespace", project: "nonprod", wantURL: "http://nonprod", wantToken: "token-nonprod"},
+		{name: "cross_namespace_not_allowed", project: "restricted", wantErr: true},
+		{name: "instance_not_found", project: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, token, err := GetKeptnInstanceForProject(context.TODO(), fakeClient, tt.project, "keptn")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantURL, got.Spec.APIUrl)
+			require.Equal(t, tt.wantToken, token)
+		})
+	}
+}
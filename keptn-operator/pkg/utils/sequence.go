@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"fmt"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"regexp"
+	"strings"
+)
+
+const previousStageParameter = "previousStage"
+
+var sequenceParameterPattern = regexp.MustCompile(`\$\{([A-Za-z0-9_-]+)\}`)
+
+//getSequenceParameters returns the values of the placeholders which can be used in the sequences of a stage
+func getSequenceParameters(stage keptnv1.KeptnStage, ref keptnv1.KeptnSequenceRefSpec) map[string]string {
+	parameters := map[string]string{
+		"stage":   stage.Name,
+		"project": stage.Spec.Project,
+	}
+	if stage.Spec.After != "" {
+		parameters[previousStageParameter] = stage.Spec.After
+	}
+	for name, value := range ref.Parameters {
+		parameters[name] = value
+	}
+	return parameters
+}
+
+//renderSequence substitutes the ${name} placeholders in a sequence. Triggers referring to ${previousStage} are dropped
+//for stages without predecessor, so that the same sequence can be used for the first stage and the stages following it.
+func renderSequence(sequence keptnv1.Sequence, parameters map[string]string) (keptnv1.Sequence, error) {
+	rendered := *sequence.DeepCopy()
+	var renderErr error
+	render := func(value string) string {
+		return sequenceParameterPattern.ReplaceAllStringFunc(value, func(placeholder string) string {
+			name := sequenceParameterPattern.FindStringSubmatch(placeholder)[1]
+			parameter, ok := parameters[name]
+			if !ok && renderErr == nil {
+				renderErr = fmt.Errorf("parameter %s used in sequence %s is not defined", name, sequence.Name)
+			}
+			return parameter
+		})
+	}
+
+	rendered.Name = render(rendered.Name)
+
+	var triggers []keptnv1.Trigger
+	for _, trigger := range rendered.TriggeredOn {
+		if _, ok := parameters[previousStageParameter]; !ok && strings.Contains(trigger.Event, "${"+previousStageParameter+"}") {
+			continue
+		}
+		trigger.Event = render(trigger.Event)
+		for key, value := range trigger.Selector.Match {
+			trigger.Selector.Match[key] = render(value)
+		}
+		triggers = append(triggers, trigger)
+	}
+	rendered.TriggeredOn = triggers
+
+	for i, task := range rendered.Tasks {
+		rendered.Tasks[i].TriggeredAfter = render(task.TriggeredAfter)
+		if task.Properties == nil {
+			continue
+		}
+		properties, err := task.Properties.Map()
+		if err != nil {
+			return rendered, err
+		}
+		rendered.Tasks[i].Properties, err = keptnv1.NewTaskProperties(renderProperties(properties, render).(map[string]interface{}))
+		if err != nil {
+			return rendered, err
+		}
+	}
+	return rendered, renderErr
+}
+
+//renderProperties substitutes the placeholders in all strings of nested task properties
+func renderProperties(value interface{}, render func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return render(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = renderProperties(item, render)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = renderProperties(item, render)
+		}
+	}
+	return value
+}
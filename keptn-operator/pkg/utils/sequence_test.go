@@ -0,0 +1,136 @@
+package utils
+
+import (
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func properties(t *testing.T, properties map[string]interface{}) *keptnv1.TaskProperties {
+	result, err := keptnv1.NewTaskProperties(properties)
+	require.NoError(t, err)
+	return result
+}
+
+func Test_composeKeptnStageTemplates(t *testing.T) {
+	template := keptnv1.Sequence{
+		Name:        "artifact-delivery",
+		TriggeredOn: []keptnv1.Trigger{{Event: "${previousStage}.artifact-delivery.finished"}},
+		Tasks: []keptnv1.Task{
+			{Name: "deployment", Properties: properties(t, map[string]interface{}{"deploymentstrategy": "${strategy}"})},
+			{Name: "evaluation", Properties: properties(t, map[string]interface{}{"timeframe": "2m"})},
+		},
+	}
+	sequences := &keptnv1.KeptnSequenceList{Items: []keptnv1.KeptnSequence{{
+		ObjectMeta: metav1.ObjectMeta{Name: "delivery", Namespace: "keptn"},
+		Spec:       keptnv1.KeptnSequenceSpec{Sequence: template},
+	}}}
+	stage := func(name, after string, refs ...keptnv1.KeptnSequenceRefSpec) keptnv1.KeptnStage {
+		return keptnv1.KeptnStage{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "keptn"},
+			Spec:       keptnv1.KeptnStageSpec{Project: "podtato", After: after, Sequence: refs},
+		}
+	}
+	reference := keptnv1.KeptnSequenceRefSpec{
+		Type:        keptnv1.SequenceRefTypeReference,
+		SequenceRef: "delivery",
+		Parameters:  map[string]string{"strategy": "blue_green_service"},
+	}
+
+	tests := []struct {
+		name    string
+		stage   keptnv1.KeptnStage
+		want    []keptnv1.Sequence
+		wantErr string
+	}{
+		{
+			name:  "first_stage_without_trigger",
+			stage: stage("dev", "", reference),
+			want: []keptnv1.Sequence{{
+				Name: "artifact-delivery",
+				Tasks: []keptnv1.Task{
+					{Name: "deployment", Properties: properties(t, map[string]interface{}{"deploymentstrategy": "blue_green_service"})},
+					{Name: "evaluation", Properties: properties(t, map[string]interface{}{"timeframe": "2m"})},
+				},
+			}},
+		},
+		{
+			name:  "following_stage",
+			stage: stage("production", "hardening", reference),
+			want: []keptnv1.Sequence{{
+				Name:        "artifact-delivery",
+				TriggeredOn: []keptnv1.Trigger{{Event: "hardening.artifact-delivery.finished"}},
+				Tasks: []keptnv1.Task{
+					{Name: "deployment", Properties: properties(t, map[string]interface{}{"deploymentstrategy": "blue_green_service"})},
+					{Name: "evaluation", Properties: properties(t, map[string]interface{}{"timeframe": "2m"})},
+				},
+			}},
+		},
+		{
+			name: "inline",
+			stage: stage("production", "hardening", keptnv1.KeptnSequenceRefSpec{
+				Type: keptnv1.SequenceRefTypeInline,
+				Sequence: &keptnv1.Sequence{
+					Name:        "rollback",
+					TriggeredOn: []keptnv1.Trigger{{Event: "${stage}.artifact-delivery.finished", Selector: keptnv1.Selector{Match: map[string]string{"result": "fail"}}}},
+					Tasks:       []keptnv1.Task{{Name: "rollback"}},
+				},
+			}),
+			want: []keptnv1.Sequence{{
+				Name:        "rollback",
+				TriggeredOn: []keptnv1.Trigger{{Event: "production.artifact-delivery.finished", Selector: keptnv1.Selector{Match: map[string]string{"result": "fail"}}}},
+				Tasks:       []keptnv1.Task{{Name: "rollback"}},
+			}},
+		},
+		{
+			name: "nested_properties",
+			stage: stage("dev", "", keptnv1.KeptnSequenceRefSpec{
+				Type: keptnv1.SequenceRefTypeInline,
+				Sequence: &keptnv1.Sequence{
+					Name: "test",
+					Tasks: []keptnv1.Task{{Name: "test", Properties: properties(t, map[string]interface{}{
+						"timeout":    300,
+						"strategies": []interface{}{"functional", map[string]interface{}{"name": "load", "target": "${stage}.${project}"}},
+					})}},
+				},
+			}),
+			want: []keptnv1.Sequence{{
+				Name: "test",
+				Tasks: []keptnv1.Task{{Name: "test", Properties: properties(t, map[string]interface{}{
+					"timeout":    300,
+					"strategies": []interface{}{"functional", map[string]interface{}{"name": "load", "target": "dev.podtato"}},
+				})}},
+			}},
+		},
+		{
+			name:    "undefined_parameter",
+			stage:   stage("dev", "", keptnv1.KeptnSequenceRefSpec{Type: keptnv1.SequenceRefTypeReference, SequenceRef: "delivery"}),
+			wantErr: "could not render sequence of stage dev: parameter strategy used in sequence artifact-delivery is not defined",
+		},
+		{
+			name:    "inline_without_sequence",
+			stage:   stage("dev", "", keptnv1.KeptnSequenceRefSpec{Type: keptnv1.SequenceRefTypeInline}),
+			wantErr: "inline sequence of stage dev has no sequence",
+		},
+		{
+			name:    "unknown_type",
+			stage:   stage("dev", "", keptnv1.KeptnSequenceRefSpec{Type: "remote"}),
+			wantErr: "unknown sequence type remote in stage dev",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := composeKeptnStage(tt.stage, sequences)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.Sequences)
+		})
+	}
+
+	// rendering must not modify the referenced sequence
+	require.JSONEq(t, `{"deploymentstrategy": "${strategy}"}`, string(sequences.Items[0].Spec.Sequence.Tasks[0].Properties.Raw))
+}
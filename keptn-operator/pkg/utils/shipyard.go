@@ -2,16 +2,27 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
 )
 
 const shipyardAPIVersion = "spec.keptn.sh/0.2.2"
 const shipyardKind = "KeptnShipyard"
 
+// ErrInvalidStageOrder is returned if the stages of a project can not be ordered, e.g. because they follow each other in a cycle
+var ErrInvalidStageOrder = errors.New("invalid stage order")
+
+//IsInvalidStageOrder returns true if the error has been caused by an invalid order of stages
+func IsInvalidStageOrder(err error) bool {
+	return errors.Is(err, ErrInvalidStageOrder)
+}
+
 //UpdateShipyard triggers the update of the shipyard object
 func UpdateShipyard(ctx context.Context, clt client.Client, shipyard keptnv1.KeptnShipyard, shipyardHash string, namespace string) error {
 	shipyard.Namespace = namespace
@@ -34,14 +45,14 @@ func UpdateShipyard(ctx context.Context, clt client.Client, shipyard keptnv1.Kep
 	return nil
 }
 
-//CreateShipyard creates a shipyard object
-func CreateShipyard(ctx context.Context, clt client.Client, project string) (keptnv1.KeptnShipyard, error) {
+//CreateShipyard creates a shipyard object from the KeptnStages of a project in a namespace
+func CreateShipyard(ctx context.Context, clt client.Client, project string, namespace string) (keptnv1.KeptnShipyard, error) {
 	shipyard := keptnv1.KeptnShipyard{}
 
 	shipyard.Name = project
 	shipyard.Spec.Project = project
 
-	keptnShipyard, err := composeShipyard(ctx, clt, project)
+	keptnShipyard, err := composeShipyard(ctx, clt, project, namespace)
 	if err != nil {
 		return shipyard, err
 	}
@@ -54,7 +65,7 @@ func CreateShipyard(ctx context.Context, clt client.Client, project string) (kep
 	return shipyard, nil
 }
 
-func composeShipyard(ctx context.Context, clt client.Client, project string) (keptnv1.Shipyard, error) {
+func composeShipyard(ctx context.Context, clt client.Client, project string, namespace string) (keptnv1.Shipyard, error) {
 	keptnShipyard := keptnv1.Shipyard{
 		ApiVersion: shipyardAPIVersion,
 		Kind:       shipyardKind,
@@ -62,12 +73,27 @@ func composeShipyard(ctx context.Context, clt client.Client, project string) (ke
 		Spec:       keptnv1.ShipyardSpec{},
 	}
 
-	stages, err := getKeptnStages(ctx, clt, project)
+	stages, err := getKeptnStages(ctx, clt, project, namespace)
 	if err != nil {
 		return keptnShipyard, err
 	}
 
-	sequences, err := getKeptnSequence(ctx, clt)
+	stages, err = sortKeptnStages(stages)
+	if err != nil {
+		return keptnShipyard, err
+	}
+
+	// sequences are looked up in the namespace of the project and the namespaces referenced explicitly by its stages
+	namespaces := []string{namespace}
+	for _, stage := range stages {
+		for _, seq := range stage.Spec.Sequence {
+			if !ContainsString(namespaces, seq.GetSequenceNamespace(stage.Namespace)) {
+				namespaces = append(namespaces, seq.GetSequenceNamespace(stage.Namespace))
+			}
+		}
+	}
+
+	sequences, err := getKeptnSequence(ctx, clt, namespaces)
 	if err != nil {
 		return keptnShipyard, err
 	}
@@ -88,46 +114,195 @@ func composeKeptnStage(stage keptnv1.KeptnStage, sequences *keptnv1.KeptnSequenc
 	}
 
 	for _, seq := range stage.Spec.Sequence {
-		sequenceFound := false
+		sequence, err := getStageSequence(stage, seq, sequences)
+		if err != nil {
+			return compstage, err
+		}
+
+		sequence, err = renderSequence(sequence, getSequenceParameters(stage, seq))
+		if err != nil {
+			return compstage, fmt.Errorf("could not render sequence of stage %s: %w", stage.Name, err)
+		}
+		compstage.Sequences = append(compstage.Sequences, sequence)
+	}
+	return compstage, nil
+}
+
+//getStageSequence returns the sequence defined inline or the referenced KeptnSequence
+func getStageSequence(stage keptnv1.KeptnStage, seq keptnv1.KeptnSequenceRefSpec, sequences *keptnv1.KeptnSequenceList) (keptnv1.Sequence, error) {
+	switch seq.Type {
+	case keptnv1.SequenceRefTypeInline:
+		if seq.Sequence == nil {
+			return keptnv1.Sequence{}, fmt.Errorf("inline sequence of stage %s has no sequence", stage.Name)
+		}
+		return *seq.Sequence, nil
+	case keptnv1.SequenceRefTypeReference, "":
+		sequenceNamespace := seq.GetSequenceNamespace(stage.Namespace)
 		for _, availableSequence := range sequences.Items {
-			if availableSequence.Name == seq.SequenceRef {
-				sequenceFound = true
+			if availableSequence.Name == seq.SequenceRef && availableSequence.Namespace == sequenceNamespace {
+				if !keptnv1.IsReferenceAllowed(&availableSequence, stage.Namespace) {
+					return keptnv1.Sequence{}, fmt.Errorf("sequence %s/%s may not be referenced by stage %s from namespace %s, the namespace has to be listed in the %s annotation of the sequence", sequenceNamespace, seq.SequenceRef, stage.Name, stage.Namespace, keptnv1.AllowedNamespacesAnnotation)
+				}
+				return availableSequence.Spec.Sequence, nil
+			}
+		}
+		return keptnv1.Sequence{}, fmt.Errorf("could not find sequence %s in namespace %s referenced by stage %s", seq.SequenceRef, sequenceNamespace, stage.Name)
+	default:
+		return keptnv1.Sequence{}, fmt.Errorf("unknown sequence type %s in stage %s", seq.Type, stage.Name)
+	}
+}
 
-				sequence := availableSequence.Spec.Sequence
-				compstage.Sequences = append(compstage.Sequences, sequence)
-				break
+//sortKeptnStages orders the stages so that each stage follows the stage referenced in its After field,
+//stages which are not ordered relative to each other are sorted by their name
+func sortKeptnStages(stages []keptnv1.KeptnStage) ([]keptnv1.KeptnStage, error) {
+	byName := map[string]keptnv1.KeptnStage{}
+	for _, stage := range stages {
+		byName[stage.Name] = stage
+	}
+
+	followers := map[string][]string{}
+	var next []string
+	for _, stage := range stages {
+		if stage.Spec.After == "" {
+			next = append(next, stage.Name)
+			continue
+		}
+		if _, ok := byName[stage.Spec.After]; !ok {
+			return nil, fmt.Errorf("%w: stage %s should follow stage %s, which does not exist", ErrInvalidStageOrder, stage.Name, stage.Spec.After)
+		}
+		followers[stage.Spec.After] = append(followers[stage.Spec.After], stage.Name)
+	}
+
+	sorted := make([]keptnv1.KeptnStage, 0, len(stages))
+	for len(next) > 0 {
+		sort.Strings(next)
+		name := next[0]
+		next = append(next[1:], followers[name]...)
+		sorted = append(sorted, byName[name])
+	}
+
+	// stages in a cycle are never reached from a stage without predecessor
+	if len(sorted) < len(stages) {
+		var cycle []string
+		for _, stage := range stages {
+			if !containsStage(sorted, stage.Name) {
+				cycle = append(cycle, stage.Name)
 			}
 		}
-		if !sequenceFound {
-			return compstage, fmt.Errorf("could not find sequence")
+		sort.Strings(cycle)
+		return nil, fmt.Errorf("%w: the stages %s can not be ordered, their after references contain a cycle", ErrInvalidStageOrder, strings.Join(cycle, ", "))
+	}
+	return sorted, nil
+}
+
+func containsStage(stages []keptnv1.KeptnStage, name string) bool {
+	for _, stage := range stages {
+		if stage.Name == name {
+			return true
 		}
 	}
-	return compstage, nil
+	return false
 }
 
-func getKeptnStages(ctx context.Context, clt client.Client, project string) ([]keptnv1.KeptnStage, error) {
+func getKeptnStages(ctx context.Context, clt client.Client, project string, namespace string) ([]keptnv1.KeptnStage, error) {
 	keptnStageList := &keptnv1.KeptnStageList{}
 	var stageList []keptnv1.KeptnStage
 
-	err := clt.List(ctx, keptnStageList)
+	err := clt.List(ctx, keptnStageList, client.InNamespace(namespace))
 	if err != nil {
 		return stageList, fmt.Errorf("could not get stages for project: %w", err)
 	}
 
+	removed := map[string]string{}
 	for _, stage := range keptnStageList.Items {
-		if stage.Spec.Project == project {
-			stageList = append(stageList, stage)
+		if stage.Spec.Project != project {
+			continue
 		}
+		// stages being deleted are removed from the shipyard as soon as no service deployment targets them anymore
+		if !stage.DeletionTimestamp.IsZero() {
+			deployments, err := GetServiceDeploymentsForStage(ctx, clt, stage.Namespace, project, stage.Name)
+			if err != nil {
+				return stageList, err
+			}
+			if len(deployments) == 0 {
+				removed[stage.Name] = stage.Spec.After
+				continue
+			}
+		}
+		stageList = append(stageList, stage)
+	}
+
+	// stages following a removed stage follow the predecessor of the removed stage instead
+	for i := range stageList {
+		stageList[i].Spec.After = relinkStage(stageList[i].Spec.After, removed)
 	}
 
 	return stageList, nil
 }
 
-func getKeptnSequence(ctx context.Context, clt client.Client) (*keptnv1.KeptnSequenceList, error) {
-	sequenceList := &keptnv1.KeptnSequenceList{}
-	err := clt.List(ctx, sequenceList)
+//relinkStage returns the first stage on the chain of after references starting at after which has not been removed
+func relinkStage(after string, removed map[string]string) string {
+	visited := map[string]bool{}
+	for {
+		predecessor, ok := removed[after]
+		if !ok || visited[after] {
+			return after
+		}
+		visited[after] = true
+		after = predecessor
+	}
+}
+
+//GetServiceDeploymentsForStage returns the names of the KeptnServiceDeployments in a namespace which target a stage of a project
+func GetServiceDeploymentsForStage(ctx context.Context, clt client.Client, namespace string, project string, stage string) ([]string, error) {
+	deploymentList := &keptnv1.KeptnServiceDeploymentList{}
+	err := clt.List(ctx, deploymentList, client.InNamespace(namespace))
 	if err != nil {
-		return sequenceList, fmt.Errorf("could not get sequences for project: %w", err)
+		return nil, fmt.Errorf("could not get service deployments: %w", err)
+	}
+
+	var deployments []string
+	for _, deployment := range deploymentList.Items {
+		if deployment.Spec.Project == project && deployment.Spec.Stage == stage {
+			deployments = append(deployments, deployment.Name)
+		}
+	}
+	return deployments, nil
+}
+
+//GetStagesForSequence returns the KeptnStages which refer to a KeptnSequence, including stages in other namespaces referring
+//to it explicitly. Stages in namespaces which are not allowed to reference the sequence are ignored
+func GetStagesForSequence(ctx context.Context, clt client.Client, sequence *keptnv1.KeptnSequence) ([]keptnv1.KeptnStage, error) {
+	keptnStageList := &keptnv1.KeptnStageList{}
+	err := clt.List(ctx, keptnStageList)
+	if err != nil {
+		return nil, fmt.Errorf("could not get stages: %w", err)
+	}
+
+	var stages []keptnv1.KeptnStage
+	for _, stage := range keptnStageList.Items {
+		if !keptnv1.IsReferenceAllowed(sequence, stage.Namespace) {
+			continue
+		}
+		for _, seq := range stage.Spec.Sequence {
+			if seq.SequenceRef == sequence.Name && seq.GetSequenceNamespace(stage.Namespace) == sequence.Namespace {
+				stages = append(stages, stage)
+				break
+			}
+		}
+	}
+	return stages, nil
+}
+
+func getKeptnSequence(ctx context.Context, clt client.Client, namespaces []string) (*keptnv1.KeptnSequenceList, error) {
+	sequenceList := &keptnv1.KeptnSequenceList{}
+	for _, namespace := range namespaces {
+		namespaceSequences := &keptnv1.KeptnSequenceList{}
+		err := clt.List(ctx, namespaceSequences, client.InNamespace(namespace))
+		if err != nil {
+			return sequenceList, fmt.Errorf("could not get sequences in namespace %s: %w", namespace, err)
+		}
+		sequenceList.Items = append(sequenceList.Items, namespaceSequences.Items...)
 	}
 	return sequenceList, nil
 }
@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"context"
 	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"testing"
 )
 
@@ -128,3 +132,136 @@ func Test_composeKeptnStage(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateShipyardNamespaces(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, keptnv1.AddToScheme(scheme))
+
+	sequence := func(namespace string, tasks ...string) *keptnv1.KeptnSequence {
+		seq := &keptnv1.KeptnSequence{
+			ObjectMeta: metav1.ObjectMeta{Name: "artifact-delivery", Namespace: namespace},
+			Spec:       keptnv1.KeptnSequenceSpec{Sequence: keptnv1.Sequence{Name: "artifact-delivery"}},
+		}
+		for _, task := range tasks {
+			seq.Spec.Sequence.Tasks = append(seq.Spec.Sequence.Tasks, keptnv1.Task{Name: task})
+		}
+		return seq
+	}
+	stage := func(name, namespace, project string, ref keptnv1.KeptnSequenceRefSpec) *keptnv1.KeptnStage {
+		return &keptnv1.KeptnStage{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       keptnv1.KeptnStageSpec{Project: project, Sequence: []keptnv1.KeptnSequenceRefSpec{ref}},
+		}
+	}
+	shared := sequence("shared", "release")
+	shared.Annotations = map[string]string{keptnv1.AllowedNamespacesAnnotation: "team-a"}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		sequence("team-a", "deployment"),
+		sequence("team-b", "deployment", "test", "evaluation"),
+		shared,
+		stage("dev", "team-a", "podtato", keptnv1.KeptnSequenceRefSpec{SequenceRef: "artifact-delivery"}),
+		stage("prod", "team-a", "podtato", keptnv1.KeptnSequenceRefSpec{SequenceRef: "artifact-delivery", Namespace: "shared"}),
+		stage("dev", "team-b", "podtato", keptnv1.KeptnSequenceRefSpec{SequenceRef: "artifact-delivery"}),
+		stage("prod", "team-b", "sockshop", keptnv1.KeptnSequenceRefSpec{SequenceRef: "artifact-delivery", Namespace: "shared"}),
+		stage("dev", "team-c", "podtato", keptnv1.KeptnSequenceRefSpec{SequenceRef: "artifact-delivery"}),
+	).Build()
+
+	taskCount := func(shipyard keptnv1.KeptnShipyard) map[string]int {
+		counts := map[string]int{}
+		for _, s := range shipyard.Spec.Shipyard.Spec.Stages {
+			counts[s.Name] = len(s.Sequences[0].Tasks)
+		}
+		return counts
+	}
+
+	// projects with the same name in different namespaces only see their own stages and sequences
+	shipyard, err := CreateShipyard(context.TODO(), fakeClient, "podtato", "team-a")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"dev": 1, "prod": 1}, taskCount(shipyard))
+	require.Equal(t, "release", shipyard.Spec.Shipyard.Spec.Stages[1].Sequences[0].Tasks[0].Name)
+
+	shipyard, err = CreateShipyard(context.TODO(), fakeClient, "podtato", "team-b")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"dev": 3}, taskCount(shipyard))
+
+	_, err = CreateShipyard(context.TODO(), fakeClient, "podtato", "team-c")
+	require.EqualError(t, err, "could not find sequence artifact-delivery in namespace team-c referenced by stage dev")
+
+	// sequences in other namespaces have to allow the namespace of the stage
+	_, err = CreateShipyard(context.TODO(), fakeClient, "sockshop", "team-b")
+	require.EqualError(t, err, "sequence shared/artifact-delivery may not be referenced by stage prod from namespace team-b, the namespace has to be listed in the keptn.sh/allowed-namespaces annotation of the sequence")
+
+	stages, err := GetStagesForSequence(context.TODO(), fakeClient, shared)
+	require.NoError(t, err)
+	require.Len(t, stages, 1)
+	require.Equal(t, "team-a", stages[0].Namespace)
+	require.Equal(t, "prod", stages[0].Name)
+}
+
+func Test_sortKeptnStages(t *testing.T) {
+	stages := func(afters ...string) []keptnv1.KeptnStage {
+		var result []keptnv1.KeptnStage
+		for i := 0; i < len(afters); i += 2 {
+			result = append(result, keptnv1.KeptnStage{
+				ObjectMeta: metav1.ObjectMeta{Name: afters[i]},
+				Spec:       keptnv1.KeptnStageSpec{After: afters[i+1]},
+			})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name    string
+		stages  []keptnv1.KeptnStage
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "unordered_by_name",
+			stages: stages("staging", "", "dev", "", "production", ""),
+			want:   []string{"dev", "production", "staging"},
+		},
+		{
+			name:   "promotion_flow",
+			stages: stages("production", "hardening", "dev", "", "hardening", "dev"),
+			want:   []string{"dev", "hardening", "production"},
+		},
+		{
+			name:   "branches",
+			stages: stages("production", "hardening", "hardening", "dev", "dev", "", "load-test", "dev", "sandbox", ""),
+			want:   []string{"dev", "hardening", "load-test", "production", "sandbox"},
+		},
+		{
+			name:    "cycle",
+			stages:  stages("dev", "", "hardening", "production", "production", "hardening"),
+			wantErr: "invalid stage order: the stages hardening, production can not be ordered, their after references contain a cycle",
+		},
+		{
+			name:    "self_reference",
+			stages:  stages("dev", "dev"),
+			wantErr: "invalid stage order: the stages dev can not be ordered, their after references contain a cycle",
+		},
+		{
+			name:    "missing_stage",
+			stages:  stages("production", "hardening"),
+			wantErr: "invalid stage order: stage production should follow stage hardening, which does not exist",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sortKeptnStages(tt.stages)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.True(t, IsInvalidStageOrder(err))
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, stage := range got {
+				names = append(names, stage.Name)
+			}
+			require.Equal(t, tt.want, names)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"fmt"
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const sloSpecVersion = "0.1.1"
+
+// SLOResourceURI is the path of the SLO in the service directory which is used by the lighthouse service
+const SLOResourceURI = "slo.yaml"
+
+var sloCriteriaPattern = regexp.MustCompile(`^(<=|>=|<|>|=)[+-]?[0-9]+(\.[0-9]+)?%?$`)
+var sloScorePattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?%$`)
+
+//slo is the slo.yaml format read by the lighthouse service
+type slo struct {
+	SpecVersion string            `yaml:"spec_version"`
+	Comparison  sloComparison     `yaml:"comparison"`
+	Filter      map[string]string `yaml:"filter"`
+	Objectives  []sloObjective    `yaml:"objectives"`
+	TotalScore  sloTotalScore     `yaml:"total_score"`
+}
+
+type sloComparison struct {
+	AggregateFunction         string `yaml:"aggregate_function"`
+	CompareWith               string `yaml:"compare_with"`
+	IncludeResultWithScore    string `yaml:"include_result_with_score"`
+	NumberOfComparisonResults int    `yaml:"number_of_comparison_results"`
+}
+
+type sloObjective struct {
+	SLI         string        `yaml:"sli"`
+	DisplayName string        `yaml:"displayName,omitempty"`
+	Pass        []sloCriteria `yaml:"pass,omitempty"`
+	Warning     []sloCriteria `yaml:"warning,omitempty"`
+	Weight      int           `yaml:"weight"`
+	KeySLI      bool          `yaml:"key_sli"`
+}
+
+type sloCriteria struct {
+	Criteria []string `yaml:"criteria"`
+}
+
+type sloTotalScore struct {
+	Pass    string `yaml:"pass"`
+	Warning string `yaml:"warning"`
+}
+
+//withSLODefaults returns the spec with the defaults of Keptn set for all optional fields
+func withSLODefaults(spec keptnv1.KeptnSLOSpec) keptnv1.KeptnSLOSpec {
+	if spec.Comparison.AggregateFunction == "" {
+		spec.Comparison.AggregateFunction = "avg"
+	}
+	if spec.Comparison.CompareWith == "" {
+		spec.Comparison.CompareWith = "single_result"
+	}
+	if spec.Comparison.IncludeResultWithScore == "" {
+		spec.Comparison.IncludeResultWithScore = "pass"
+	}
+	if spec.Comparison.NumberOfComparisonResults == 0 {
+		spec.Comparison.NumberOfComparisonResults = 1
+	}
+	if spec.TotalScore.Pass == "" {
+		spec.TotalScore.Pass = "90%"
+	}
+	if spec.TotalScore.Warning == "" {
+		spec.TotalScore.Warning = "75%"
+	}
+	return spec
+}
+
+// ValidateSLO checks the objectives, criteria and scores of a KeptnSLO, the returned error describes all problems found
+func ValidateSLO(spec keptnv1.KeptnSLOSpec) error {
+	spec = withSLODefaults(spec)
+	var problems []string
+
+	if spec.Comparison.CompareWith == "single_result" && spec.Comparison.NumberOfComparisonResults != 1 {
+		problems = append(problems, "numberOfComparisonResults must be 1 if compareWith is single_result")
+	}
+
+	if len(spec.Objectives) == 0 {
+		problems = append(problems, "at least one objective is required")
+	}
+	slis := map[string]bool{}
+	for i, objective := range spec.Objectives {
+		switch {
+		case objective.SLI == "":
+			problems = append(problems, fmt.Sprintf("objective %d has no sli", i))
+		case slis[objective.SLI]:
+			problems = append(problems, fmt.Sprintf("sli %s is used by several objectives", objective.SLI))
+		}
+		slis[objective.SLI] = true
+
+		if len(objective.Warning) > 0 && len(objective.Pass) == 0 {
+			problems = append(problems, fmt.Sprintf("objective %s has warning but no pass criteria", objective.SLI))
+		}
+		if objective.KeySLI && len(objective.Pass) == 0 {
+			problems = append(problems, fmt.Sprintf("key sli %s has no pass criteria", objective.SLI))
+		}
+		if objective.Weight < 0 {
+			problems = append(problems, fmt.Sprintf("objective %s has a negative weight", objective.SLI))
+		}
+		for _, group := range append(append([]keptnv1.SLOCriteria{}, objective.Pass...), objective.Warning...) {
+			if len(group.Criteria) == 0 {
+				problems = append(problems, fmt.Sprintf("objective %s has an empty criteria group", objective.SLI))
+			}
+			for _, criteria := range group.Criteria {
+				if !sloCriteriaPattern.MatchString(strings.ReplaceAll(criteria, " ", "")) {
+					problems = append(problems, fmt.Sprintf("criteria %q of objective %s is invalid", criteria, objective.SLI))
+				}
+			}
+		}
+	}
+
+	pass, err := parseSLOScore(spec.TotalScore.Pass)
+	if err != nil {
+		problems = append(problems, "totalScore.pass: "+err.Error())
+	}
+	warning, err := parseSLOScore(spec.TotalScore.Warning)
+	if err != nil {
+		problems = append(problems, "totalScore.warning: "+err.Error())
+	}
+	if pass >= 0 && warning >= 0 && warning > pass {
+		problems = append(problems, "totalScore.warning must not be higher than totalScore.pass")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid SLO: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+//parseSLOScore parses a percentage like 90%, -1 is returned along with the error if it is invalid
+func parseSLOScore(score string) (float64, error) {
+	if !sloScorePattern.MatchString(score) {
+		return -1, fmt.Errorf("%q is not a percentage", score)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSuffix(score, "%"), 64)
+	if err != nil || value > 100 {
+		return -1, fmt.Errorf("%q is not between 0%% and 100%%", score)
+	}
+	return value, nil
+}
+
+// RenderSLO renders a KeptnSLO into the slo.yaml format of Keptn
+func RenderSLO(spec keptnv1.KeptnSLOSpec) ([]byte, error) {
+	if err := ValidateSLO(spec); err != nil {
+		return nil, err
+	}
+	spec = withSLODefaults(spec)
+
+	rendered := slo{
+		SpecVersion: sloSpecVersion,
+		Comparison: sloComparison{
+			AggregateFunction:         spec.Comparison.AggregateFunction,
+			CompareWith:               spec.Comparison.CompareWith,
+			IncludeResultWithScore:    spec.Comparison.IncludeResultWithScore,
+			NumberOfComparisonResults: spec.Comparison.NumberOfComparisonResults,
+		},
+		Filter:     spec.Filter,
+		TotalScore: sloTotalScore{Pass: spec.TotalScore.Pass, Warning: spec.TotalScore.Warning},
+	}
+	if rendered.Filter == nil {
+		rendered.Filter = map[string]string{}
+	}
+	for _, objective := range spec.Objectives {
+		weight := objective.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		rendered.Objectives = append(rendered.Objectives, sloObjective{
+			SLI:         objective.SLI,
+			DisplayName: objective.DisplayName,
+			Pass:        renderSLOCriteria(objective.Pass),
+			Warning:     renderSLOCriteria(objective.Warning),
+			Weight:      weight,
+			KeySLI:      objective.KeySLI,
+		})
+	}
+
+	content, err := yaml.Marshal(rendered)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal SLO: %w", err)
+	}
+	return content, nil
+}
+
+func renderSLOCriteria(groups []keptnv1.SLOCriteria) []sloCriteria {
+	var rendered []sloCriteria
+	for _, group := range groups {
+		criteria := []string{}
+		for _, c := range group.Criteria {
+			criteria = append(criteria, strings.ReplaceAll(c, " ", ""))
+		}
+		rendered = append(rendered, sloCriteria{Criteria: criteria})
+	}
+	return rendered
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRenderSLO(t *testing.T) {
+	spec := keptnv1.KeptnSLOSpec{
+		Project: "podtato",
+		Service: "hello",
+		Filter:  map[string]string{"handler": "ItemsController"},
+		Objectives: []keptnv1.SLOObjective{
+			{
+				SLI:     "response_time_p95",
+				Pass:    []keptnv1.SLOCriteria{{Criteria: []string{"<=+10%", "< 600"}}},
+				Warning: []keptnv1.SLOCriteria{{Criteria: []string{"<=800"}}},
+				Weight:  2,
+				KeySLI:  true,
+			},
+			{SLI: "throughput", DisplayName: "Throughput"},
+		},
+	}
+
+	content, err := RenderSLO(spec)
+	require.NoError(t, err)
+	require.Equal(t, `spec_version: 0.1.1
+comparison:
+    aggregate_function: avg
+    compare_with: single_result
+    include_result_with_score: pass
+    number_of_comparison_results: 1
+filter:
+    handler: ItemsController
+objectives:
+    - sli: response_time_p95
+      pass:
+        - criteria:
+            - <=+10%
+            - <600
+      warning:
+        - criteria:
+            - <=800
+      weight: 2
+      key_sli: true
+    - sli: throughput
+      displayName: Throughput
+      weight: 1
+      key_sli: false
+total_score:
+    pass: 90%
+    warning: 75%
+`, string(content))
+}
+
+func TestValidateSLO(t *testing.T) {
+	valid := func(modify func(spec *keptnv1.KeptnSLOSpec)) keptnv1.KeptnSLOSpec {
+		spec := keptnv1.KeptnSLOSpec{
+			Project:    "podtato",
+			Service:    "hello",
+			Objectives: []keptnv1.SLOObjective{{SLI: "response_time_p95", Pass: []keptnv1.SLOCriteria{{Criteria: []string{"<600"}}}}},
+		}
+		modify(&spec)
+		return spec
+	}
+
+	tests := []struct {
+		name    string
+		spec    keptnv1.KeptnSLOSpec
+		wantErr string
+	}{
+		{name: "valid", spec: valid(func(spec *keptnv1.KeptnSLOSpec) {})},
+		{
+			name: "several_results",
+			spec: valid(func(spec *keptnv1.KeptnSLOSpec) {
+				spec.Comparison = keptnv1.SLOComparison{CompareWith: "several_results", NumberOfComparisonResults: 3}
+			}),
+		},
+		{
+			name:    "single_result_with_several_comparisons",
+			spec:    valid(func(spec *keptnv1.KeptnSLOSpec) { spec.Comparison.NumberOfComparisonResults = 3 }),
+			wantErr: "numberOfComparisonResults must be 1",
+		},
+		{
+			name:    "no_objectives",
+			spec:    valid(func(spec *keptnv1.KeptnSLOSpec) { spec.Objectives = nil }),
+			wantErr: "at least one objective is required",
+		},
+		{
+			name: "duplicate_sli",
+			spec: valid(func(spec *keptnv1.KeptnSLOSpec) {
+				spec.Objectives = append(spec.Objectives, keptnv1.SLOObjective{SLI: "response_time_p95"})
+			}),
+			wantErr: "sli response_time_p95 is used by several objectives",
+		},
+		{
+			name:    "invalid_criteria",
+			spec:    valid(func(spec *keptnv1.KeptnSLOSpec) { spec.Objectives[0].Pass[0].Criteria = []string{"fast"} }),
+			wantErr: `criteria "fast" of objective response_time_p95 is invalid`,
+		},
+		{
+			name: "warning_without_pass",
+			spec: valid(func(spec *keptnv1.KeptnSLOSpec) {
+				spec.Objectives[0].Pass = nil
+				spec.Objectives[0].Warning = []keptnv1.SLOCriteria{{Criteria: []string{"<800"}}}
+			}),
+			wantErr: "objective response_time_p95 has warning but no pass criteria",
+		},
+		{
+			name: "key_sli_without_pass",
+			spec: valid(func(spec *keptnv1.KeptnSLOSpec) {
+				spec.Objectives[0].Pass = nil
+				spec.Objectives[0].KeySLI = true
+			}),
+			wantErr: "key sli response_time_p95 has no pass criteria",
+		},
+		{
+			name:    "invalid_score",
+			spec:    valid(func(spec *keptnv1.KeptnSLOSpec) { spec.TotalScore.Pass = "110%" }),
+			wantErr: "totalScore.pass",
+		},
+		{
+			name:    "warning_above_pass",
+			spec:    valid(func(spec *keptnv1.KeptnSLOSpec) { spec.TotalScore = keptnv1.SLOTotalScore{Pass: "80%", Warning: "90%"} }),
+			wantErr: "totalScore.warning must not be higher than totalScore.pass",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSLO(tt.spec)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
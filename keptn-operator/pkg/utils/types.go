@@ -1,9 +1,12 @@
 package utils
 
+import "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/gitauth"
+
 //GitRepositoryConfig defines the configuration which is used by git components
 type GitRepositoryConfig struct {
 	RemoteURI string
 	User      string
 	Token     string
 	Branch    string
+	SSH       gitauth.SSHCredentials
 }
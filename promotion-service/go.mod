@@ -93,3 +93,5 @@ require (
 )
 
 replace github.com/go-git/go-git/v5 => github.com/yeahservice/go-git/v5 v5.4.2-aws-patch
+
+replace github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator => ../keptn-operator
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	cloudevents "github.com/cloudevents/sdk-go/v2" // make sure to use v2 cloudevents here
 	"github.com/kelseyhightower/envconfig"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/secrets"
 	"github.com/keptn-sandbox/keptn-gitops-operator/promotion-service/eventhandler"
 	"github.com/keptn-sandbox/keptn-gitops-operator/promotion-service/pkg/utils"
 	"github.com/keptn/go-utils/pkg/lib/keptn"
@@ -26,6 +27,8 @@ type envConfig struct {
 	Env string `envconfig:"ENV" default:"local"`
 	// URL of the Keptn configuration service (this is where we can fetch files from the config repo)
 	ConfigurationServiceUrl string `envconfig:"CONFIGURATION_SERVICE" default:""`
+	// Whether env: and file: secrets may be used in KeptnProjects
+	AllowLocalSecretReferences bool `envconfig:"ALLOW_LOCAL_SECRET_REFERENCES" default:"false"`
 }
 
 /**
@@ -102,6 +105,14 @@ func _main(args []string, env envConfig) int {
 
 	keptnOptions.ConfigurationServiceURL = env.ConfigurationServiceUrl
 
+	kubeClient, err := utils.NewKubeClient()
+	if err != nil {
+		log.Printf("Could not create kubernetes client, k8s: secrets will not be resolved: %v", err)
+	} else {
+		secrets.Register(secrets.KubernetesPrefix, secrets.NewKubernetesResolver(kubeClient))
+	}
+	secrets.AllowLocalReferences(env.AllowLocalSecretReferences)
+
 	log.Println("Starting promotion-service...")
 	log.Printf("    on Port = %d; Path=%s", env.Port, env.Path)
 
@@ -8,8 +8,9 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	keptnv1 "github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/api/v1"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/gitauth"
+	"github.com/keptn-sandbox/keptn-gitops-operator/keptn-operator/pkg/secrets"
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
 	"helm.sh/helm/v3/pkg/chart"
@@ -34,15 +35,23 @@ type GitHandlerInterface interface {
 type GitHandler struct {
 }
 
-//GetUpstreamCredentials gets the KeptnProject Resource for the Project and reads the git credentials
-func GetUpstreamCredentials(project string, namespace string) (*GitRepositoryConfig, error) {
+//NewKubeClient creates a kubernetes client which knows about the keptn.sh resources
+func NewKubeClient() (client.Client, error) {
 	scheme := runtime.NewScheme()
 	_ = clientgoscheme.AddToScheme(scheme)
 	_ = keptnv1.AddToScheme(scheme)
 
-	kubeconfig := ctrl.GetConfigOrDie()
+	kubeconfig, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.New(kubeconfig, client.Options{Scheme: scheme})
+}
 
-	kubeclient, err := client.New(kubeconfig, client.Options{Scheme: scheme})
+//GetUpstreamCredentials gets the KeptnProject Resource for the Project and reads the git credentials
+func GetUpstreamCredentials(project string, namespace string) (*GitRepositoryConfig, error) {
+	kubeclient, err := NewKubeClient()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -52,13 +61,26 @@ func GetUpstreamCredentials(project string, namespace string) (*GitRepositoryCon
 		Namespace: namespace,
 		Name:      project,
 	}, &keptnproject)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch keptn project %s: %w", project, err)
+	}
+
+	token := secrets.Reference(keptnproject.Spec.SecretRef, keptnproject.Spec.Password, namespace)
+	credentials, err := GetGitCredentials(keptnproject.Spec.Repository, keptnproject.Spec.Username, token, keptnproject.Spec.DefaultBranch, namespace)
+	if err != nil {
+		return nil, err
+	}
 
-	return GetGitCredentials(keptnproject.Spec.Repository, keptnproject.Spec.Username, keptnproject.Spec.Password, keptnproject.Spec.DefaultBranch)
+	credentials.SSH, err = gitauth.ResolveSSHCredentials(context.Background(), keptnproject.Spec.SSH, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return credentials, nil
 }
 
-//GetGitCredentials creates a unified struct for git credentials
-func GetGitCredentials(remoteURI, user, token string, branch string) (*GitRepositoryConfig, error) {
-	secret, err := DecryptSecret(token)
+//GetGitCredentials creates a unified struct for git credentials, the token is resolved in the given namespace
+func GetGitCredentials(remoteURI, user, token string, branch string, namespace string) (*GitRepositoryConfig, error) {
+	secret, err := secrets.ResolveInNamespace(context.Background(), token, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -77,9 +99,9 @@ func GetGitCredentials(remoteURI, user, token string, branch string) (*GitReposi
 
 //UpdateGitRepo updates the upstream repository
 func (gh *GitHandler) UpdateGitRepo(credentials *GitRepositoryConfig, stage string, service string, version string, configVersion string) error {
-	authentication := &http.BasicAuth{
-		Username: credentials.User,
-		Password: credentials.Token,
+	authentication, err := gitauth.NewAuthMethod(credentials.RemoteURI, credentials.User, credentials.Token, credentials.SSH)
+	if err != nil {
+		return err
 	}
 
 	cloneOptionsMaster := git.CloneOptions{
@@ -107,7 +129,7 @@ func (gh *GitHandler) UpdateGitRepo(credentials *GitRepositoryConfig, stage stri
 	dirMaster, _ := ioutil.TempDir("", "temp_dir_master")
 	dirStage, _ := ioutil.TempDir("", "temp_dir_"+stage)
 
-	_, err := git.PlainClone(dirMaster, false, &cloneOptionsMaster)
+	_, err = git.PlainClone(dirMaster, false, &cloneOptionsMaster)
 	if err != nil {
 		log.Println("Could not checkout "+credentials.RemoteURI+"/master", err)
 		return err